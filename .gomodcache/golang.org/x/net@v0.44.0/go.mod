@@ -0,0 +1,10 @@
+module golang.org/x/net
+
+go 1.24.0
+
+require (
+	golang.org/x/crypto v0.42.0
+	golang.org/x/sys v0.36.0
+	golang.org/x/term v0.35.0
+	golang.org/x/text v0.29.0
+)
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+
+	"endlesswiki/internal/app"
+)
+
+func runAutolink(args []string) {
+	fs := flag.NewFlagSet("autolink", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "report pages that would change without writing them")
+	world, lang := worldFlag(fs), langFlag(fs)
+	fs.Parse(args)
+
+	_, db := openDB(*world, *lang)
+	defer db.Close()
+
+	stats, err := app.AutolinkAll(context.Background(), db, *dryRun)
+	if err != nil {
+		log.Fatalf("autolink: %v", err)
+	}
+
+	verb := "updated"
+	if *dryRun {
+		verb = "would update"
+	}
+	log.Printf("autolink scanned %d pages, %s %d", stats.Scanned, verb, stats.Updated)
+}
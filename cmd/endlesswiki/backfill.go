@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"endlesswiki/internal/app"
+)
+
+func runBackfill(args []string) {
+	fs := flag.NewFlagSet("backfill", flag.ExitOnError)
+	world, lang := worldFlag(fs), langFlag(fs)
+	fs.Usage = func() {
+		fmt.Fprintln(os.Stderr, `usage: endlesswiki backfill [-world NAME] [-lang CODE] <kind>
+
+kinds:
+  links        rebuild page_links from stored page content
+  titles       store each page's <h1> as its display title
+  categories   ask the model to categorise pages that have no categories`)
+	}
+	fs.Parse(args)
+	if fs.NArg() != 1 {
+		fs.Usage()
+		os.Exit(2)
+	}
+	kind := fs.Arg(0)
+
+	cfg, db := openDB(*world, *lang)
+	defer db.Close()
+
+	ctx := context.Background()
+	var n int
+	var err error
+	switch kind {
+	case "links":
+		n, err = app.BackfillLinks(ctx, db)
+	case "titles":
+		n, err = app.BackfillTitles(ctx, db)
+	case "categories":
+		client := &http.Client{Timeout: 45 * time.Second}
+		n, err = app.BackfillCategories(ctx, db, client, cfg)
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		log.Fatalf("backfill %s: %v", kind, err)
+	}
+	log.Printf("backfill %s processed %d pages", kind, n)
+}
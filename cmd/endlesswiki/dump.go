@@ -0,0 +1,108 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"endlesswiki/internal/app"
+)
+
+// dateFilterFlags registers the -since and -until flags shared by dump and
+// restore.
+func dateFilterFlags(fs *flag.FlagSet) func() app.DateFilter {
+	since := fs.String("since", "", "only pages and redirects created at or after this time (RFC 3339 or YYYY-MM-DD)")
+	until := fs.String("until", "", "only pages and redirects created before this time (RFC 3339 or YYYY-MM-DD)")
+	return func() app.DateFilter {
+		var filter app.DateFilter
+		var err error
+		if filter.Since, err = app.ParseDumpTime(*since); err != nil {
+			log.Fatalf("invalid -since: %v", err)
+		}
+		if filter.Until, err = app.ParseDumpTime(*until); err != nil {
+			log.Fatalf("invalid -until: %v", err)
+		}
+		return filter
+	}
+}
+
+func runDump(args []string) {
+	fs := flag.NewFlagSet("dump", flag.ExitOnError)
+	out := fs.String("out", "-", `file to write the dump to, or "-" for stdout`)
+	gzipped := fs.Bool("gzip", false, "compress the dump (implied by an -out name ending in .gz)")
+	filter := dateFilterFlags(fs)
+	world, lang := worldFlag(fs), langFlag(fs)
+	fs.Parse(args)
+	if fs.NArg() != 0 {
+		fmt.Fprintln(os.Stderr, "usage: endlesswiki dump [-out FILE] [-gzip] [-since TIME] [-until TIME] [-world NAME] [-lang CODE]")
+		os.Exit(2)
+	}
+	opts := app.DumpOptions{Filter: filter(), Gzip: *gzipped || strings.HasSuffix(*out, ".gz")}
+
+	cfg, db := openDB(*world, *lang)
+	defer db.Close()
+
+	var w io.Writer = os.Stdout
+	var f *os.File
+	if *out != "-" {
+		var err error
+		if f, err = os.Create(*out); err != nil {
+			log.Fatalf("dump: %v", err)
+		}
+		w = f
+	}
+
+	stats, err := app.DumpWiki(context.Background(), db, cfg, w, opts)
+	if f != nil {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		log.Fatalf("dump: %v", err)
+	}
+	log.Printf("dump wrote %d pages, %d redirects, and %d categories", stats.Pages, stats.Redirects, stats.Categories)
+}
+
+func runRestore(args []string) {
+	fs := flag.NewFlagSet("restore", flag.ExitOnError)
+	in := fs.String("in", "-", `dump to read, plain or gzipped, or "-" for stdin`)
+	conflict := fs.String("conflict", string(app.ConflictSkip), "what to do with records that already exist: skip, overwrite, or keep-newer")
+	checkpoint := fs.String("checkpoint", "", "file recording progress, so that an interrupted restore can resume")
+	filter := dateFilterFlags(fs)
+	world, lang := worldFlag(fs), langFlag(fs)
+	fs.Parse(args)
+	if fs.NArg() != 0 {
+		fmt.Fprintln(os.Stderr, "usage: endlesswiki restore [-in FILE] [-conflict POLICY] [-checkpoint FILE] [-since TIME] [-until TIME] [-world NAME] [-lang CODE]")
+		os.Exit(2)
+	}
+	policy, err := app.ParseConflictPolicy(*conflict)
+	if err != nil {
+		log.Fatalf("restore: %v", err)
+	}
+	opts := app.RestoreOptions{Conflict: policy, Filter: filter(), Checkpoint: *checkpoint}
+
+	_, db := openDB(*world, *lang)
+	defer db.Close()
+
+	var r io.Reader = os.Stdin
+	if *in != "-" {
+		f, err := os.Open(*in)
+		if err != nil {
+			log.Fatalf("restore: %v", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	stats, err := app.RestoreWiki(context.Background(), db, r, opts)
+	if err != nil {
+		log.Fatalf("restore: %v (restored %d pages before the error)", err, stats.Pages)
+	}
+	log.Printf("restore wrote %d pages, %d redirects, and %d categories (skipped %d existing, %d outside the date filter, %d already restored, %d invalid)",
+		stats.Pages, stats.Redirects, stats.Categories, stats.Skipped, stats.Filtered, stats.Resumed, stats.Invalid)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"endlesswiki/internal/app"
+	"endlesswiki/internal/tools/epub"
+	"endlesswiki/internal/tools/zim"
+)
+
+func exportUsage() {
+	fmt.Fprintln(os.Stderr, `usage: endlesswiki export <format> [flags]
+
+formats:
+  static   render every page to plain HTML files for static hosting
+  zim      write a ZIM archive for offline reading in Kiwix
+  epub     bind the pages around a topic, or a constellation cluster, into an ebook`)
+}
+
+func runExport(args []string) {
+	if len(args) == 0 {
+		exportUsage()
+		os.Exit(2)
+	}
+	format, args := args[0], args[1:]
+	switch format {
+	case "static":
+		runExportStatic(args)
+	case "zim":
+		runExportZim(args)
+	case "epub":
+		runExportEpub(args)
+	default:
+		exportUsage()
+		os.Exit(2)
+	}
+}
+
+func runExportStatic(args []string) {
+	fs := flag.NewFlagSet("export static", flag.ExitOnError)
+	out := fs.String("out", "", "directory to write the site to (required)")
+	world, lang := worldFlag(fs), langFlag(fs)
+	fs.Parse(args)
+	if *out == "" || fs.NArg() != 0 {
+		fmt.Fprintln(os.Stderr, "usage: endlesswiki export static -out DIR [-world NAME] [-lang CODE]")
+		os.Exit(2)
+	}
+
+	cfg, db := openDB(*world, *lang)
+	defer db.Close()
+
+	stats, err := app.ExportStatic(context.Background(), db, cfg, *out)
+	if err != nil {
+		log.Fatalf("export static: %v", err)
+	}
+	log.Printf("export static wrote %d pages and %d redirects to %s (skipped %d)", stats.Pages, stats.Redirects, *out, stats.Skipped)
+}
+
+func runExportZim(args []string) {
+	fs := flag.NewFlagSet("export zim", flag.ExitOnError)
+	out := fs.String("out", "", "path of the .zim file to write (required)")
+	world, lang := worldFlag(fs), langFlag(fs)
+	fs.Parse(args)
+	if *out == "" || fs.NArg() != 0 {
+		fmt.Fprintln(os.Stderr, "usage: endlesswiki export zim -out FILE [-world NAME] [-lang CODE]")
+		os.Exit(2)
+	}
+
+	cfg, db := openDB(*world, *lang)
+	defer db.Close()
+
+	opts := zim.Options{
+		Name:        "endlesswiki",
+		Title:       "EndlessWiki",
+		Description: "The infinite encyclopedia",
+		Language:    cfg.Lang,
+		Creator:     "EndlessWiki",
+		Publisher:   "EndlessWiki",
+	}
+	if cfg.World != "" {
+		opts.Name += "_" + cfg.World
+		opts.Title += ": " + cfg.World
+	}
+	if cfg.Lang != "" {
+		opts.Name += "_" + cfg.Lang
+	}
+
+	stats, err := zim.Export(db, *out, opts)
+	if err != nil {
+		log.Fatalf("export zim: %v", err)
+	}
+	log.Printf("export zim wrote %d articles and %d redirects to %s", stats.Articles, stats.Redirects, *out)
+}
+
+func runExportEpub(args []string) {
+	fs := flag.NewFlagSet("export epub", flag.ExitOnError)
+	out := fs.String("out", "", "path of the .epub file to write (required)")
+	start := fs.String("start", "", "slug of the page the book starts from")
+	depth := fs.Int("depth", 1, "how many links away from -start to collect pages")
+	cluster := fs.Int("cluster", -1, "constellation cluster id to collect instead of -start")
+	snapshot := fs.String("snapshot", "static/constellation.json", "constellation snapshot that -cluster refers to")
+	limit := fs.Int("max", 100, "maximum number of chapters (0 for no limit)")
+	title := fs.String("title", "", "book title (default: the title of the first chapter)")
+	world, lang := worldFlag(fs), langFlag(fs)
+	fs.Parse(args)
+	if *out == "" || fs.NArg() != 0 || (*start == "") == (*cluster < 0) {
+		fmt.Fprintln(os.Stderr, "usage: endlesswiki export epub -out FILE (-start SLUG [-depth N] | -cluster ID [-snapshot FILE]) [-max N] [-title TITLE] [-world NAME] [-lang CODE]")
+		os.Exit(2)
+	}
+
+	cfg, db := openDB(*world, *lang)
+	defer db.Close()
+
+	stats, err := epub.Export(db, *out, epub.Options{
+		Start:    *start,
+		Depth:    *depth,
+		Cluster:  *cluster,
+		Snapshot: *snapshot,
+		Limit:    *limit,
+		Title:    *title,
+		Language: cfg.Lang,
+	})
+	if err != nil {
+		log.Fatalf("export epub: %v", err)
+	}
+	log.Printf("export epub wrote %d chapters with %d in-book links to %s", stats.Chapters, stats.Links, *out)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"endlesswiki/internal/app"
+)
+
+func importUsage() {
+	fmt.Fprintln(os.Stderr, `usage: endlesswiki import <format> [flags]
+
+formats:
+  mediawiki  seed articles from a MediaWiki XML export`)
+}
+
+func runImport(args []string) {
+	if len(args) == 0 {
+		importUsage()
+		os.Exit(2)
+	}
+	format, args := args[0], args[1:]
+	switch format {
+	case "mediawiki":
+		runImportMediaWiki(args)
+	default:
+		importUsage()
+		os.Exit(2)
+	}
+}
+
+func runImportMediaWiki(args []string) {
+	fs := flag.NewFlagSet("import mediawiki", flag.ExitOnError)
+	in := fs.String("in", "-", `export to read, plain, gzipped, or bzip2ed, or "-" for stdin`)
+	conflict := fs.String("conflict", string(app.ConflictSkip), "what to do with pages that already exist: skip, overwrite, or keep-newer")
+	world, lang := worldFlag(fs), langFlag(fs)
+	fs.Parse(args)
+	if fs.NArg() != 0 {
+		fmt.Fprintln(os.Stderr, "usage: endlesswiki import mediawiki [-in FILE] [-conflict POLICY] [-world NAME] [-lang CODE]")
+		os.Exit(2)
+	}
+	policy, err := app.ParseConflictPolicy(*conflict)
+	if err != nil {
+		log.Fatalf("import mediawiki: %v", err)
+	}
+
+	_, db := openDB(*world, *lang)
+	defer db.Close()
+
+	var r io.Reader = os.Stdin
+	if *in != "-" {
+		f, err := os.Open(*in)
+		if err != nil {
+			log.Fatalf("import mediawiki: %v", err)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	stats, err := app.ImportMediaWiki(context.Background(), db, r, app.ImportOptions{Conflict: policy})
+	if err != nil {
+		log.Fatalf("import mediawiki: %v (imported %d pages before the error)", err, stats.Pages)
+	}
+	log.Printf("import mediawiki wrote %d pages and %d redirects (skipped %d existing, ignored %d, dropped %d looping and %d dangling redirects)",
+		stats.Pages, stats.Redirects, stats.Skipped, stats.Ignored, stats.RedirectLoops, stats.DanglingRedirects)
+}
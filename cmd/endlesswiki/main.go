@@ -2,8 +2,12 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"flag"
+	"fmt"
 	"log"
 	"net/http"
+	"os"
 	"os/signal"
 	"syscall"
 	"time"
@@ -12,26 +16,168 @@ import (
 )
 
 func main() {
+	args := os.Args[1:]
+	command := "serve"
+	if len(args) > 0 {
+		command, args = args[0], args[1:]
+	}
+
+	switch command {
+	case "serve":
+		runServe(args)
+	case "autolink":
+		runAutolink(args)
+	case "backfill":
+		runBackfill(args)
+	case "merge":
+		runMerge(args)
+	case "export":
+		runExport(args)
+	case "dump":
+		runDump(args)
+	case "restore":
+		runRestore(args)
+	case "import":
+		runImport(args)
+	case "help", "-h", "-help", "--help":
+		usage()
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", command)
+		usage()
+		os.Exit(2)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, `usage: endlesswiki <command> [flags]
+
+commands:
+  serve      run the HTTP server (default)
+  autolink   link mentions of existing pages across all stored articles
+  backfill   rebuild derived data (links, titles, categories) from stored pages
+  merge      fold a duplicate page into another and leave a redirect
+  export     write the wiki out in another format (static HTML)
+  dump       write pages and their metadata out as JSON Lines
+  restore    load a dump written by dump
+  import     seed the wiki from another format (MediaWiki XML)
+
+Maintenance commands take -world NAME to operate on a named world from
+WORLDS_FILE instead of the main wiki, and -lang CODE to operate on one of its
+language editions.`)
+}
+
+// worldFlag registers the -world flag shared by the maintenance commands.
+func worldFlag(fs *flag.FlagSet) *string {
+	return fs.String("world", "", "named world from WORLDS_FILE to operate on (default: the main wiki)")
+}
+
+// langFlag registers the -lang flag shared by the maintenance commands.
+func langFlag(fs *flag.FlagSet) *string {
+	return fs.String("lang", "", "language edition to operate on (default: the wiki's own language)")
+}
+
+// openDB loads configuration and returns a verified database handle for the
+// main wiki, or for the named world when world is set, or for its edition in
+// lang when lang is set.
+func openDB(world, lang string) (app.Config, *sql.DB) {
 	cfg, err := app.LoadConfig()
 	if err != nil {
 		log.Fatalf("load config: %v", err)
 	}
+	if world != "" {
+		cfg = findWorld(cfg, world)
+	}
+	if lang != "" && lang != cfg.Lang {
+		cfg = findEdition(cfg, lang)
+	}
 
 	db, err := app.NewDB(cfg)
 	if err != nil {
 		log.Fatalf("open db: %v", err)
 	}
-	defer db.Close()
 
 	if err := db.Ping(); err != nil {
 		log.Fatalf("ping db: %v", err)
 	}
 
-	handler, err := app.NewServer(db, cfg)
+	return cfg, db
+}
+
+// findWorld returns the configuration of the named world.
+func findWorld(cfg app.Config, name string) app.Config {
+	if cfg.WorldsFile == "" {
+		log.Fatalf("world %q requested but WORLDS_FILE is not set", name)
+	}
+	worlds, err := app.LoadWorlds(cfg.WorldsFile, cfg)
+	if err != nil {
+		log.Fatalf("load worlds: %v", err)
+	}
+	for _, world := range worlds {
+		if world.World == name {
+			return world
+		}
+	}
+	log.Fatalf("no world named %q in %s", name, cfg.WorldsFile)
+	return cfg
+}
+
+// findEdition returns the configuration of cfg's language edition in lang.
+func findEdition(cfg app.Config, lang string) app.Config {
+	for _, edition := range cfg.Editions {
+		if edition.Lang == lang {
+			return edition
+		}
+	}
+	log.Fatalf("no %q edition configured", lang)
+	return cfg
+}
+
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	fs.Parse(args)
+
+	cfg, db := openDB("", "")
+	defer db.Close()
+
+	wiki, err := app.NewServer(db, cfg)
 	if err != nil {
 		log.Fatalf("init server: %v", err)
 	}
 
+	editionDBs, err := app.OpenEditions(wiki)
+	if err != nil {
+		log.Fatalf("open editions: %v", err)
+	}
+	for _, editionDB := range editionDBs {
+		defer editionDB.Close()
+	}
+
+	var handler http.Handler = wiki
+	if cfg.WorldsFile != "" {
+		worldCfgs, err := app.LoadWorlds(cfg.WorldsFile, cfg)
+		if err != nil {
+			log.Fatalf("load worlds: %v", err)
+		}
+		var worlds []*app.Server
+		for _, worldCfg := range worldCfgs {
+			srv, worldDB, err := app.OpenWorld(worldCfg)
+			if err != nil {
+				log.Fatalf("open world %s: %v", worldCfg.World, err)
+			}
+			defer worldDB.Close()
+			editionDBs, err := app.OpenEditions(srv)
+			if err != nil {
+				log.Fatalf("open world %s: %v", worldCfg.World, err)
+			}
+			for _, editionDB := range editionDBs {
+				defer editionDB.Close()
+			}
+			worlds = append(worlds, srv)
+			log.Printf("serving world %s", worldCfg.World)
+		}
+		handler = app.NewWorldRouter(wiki, worlds)
+	}
+
 	srv := &http.Server{
 		Addr:         ":" + cfg.Port,
 		Handler:      handler,
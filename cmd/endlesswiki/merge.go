@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"os"
+
+	"endlesswiki/internal/app"
+)
+
+func runMerge(args []string) {
+	fs := flag.NewFlagSet("merge", flag.ExitOnError)
+	from := fs.String("from", "", "slug of the duplicate page to remove")
+	into := fs.String("into", "", "slug of the canonical page to keep")
+	world, lang := worldFlag(fs), langFlag(fs)
+	fs.Parse(args)
+
+	if *from == "" || *into == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	fromSlug, err := app.NormalizeSlug(*from)
+	if err != nil {
+		log.Fatalf("invalid -from slug %q: %v", *from, err)
+	}
+	intoSlug, err := app.NormalizeSlug(*into)
+	if err != nil {
+		log.Fatalf("invalid -into slug %q: %v", *into, err)
+	}
+
+	_, db := openDB(*world, *lang)
+	defer db.Close()
+
+	if err := app.MergePages(context.Background(), db, fromSlug, intoSlug); err != nil {
+		log.Fatalf("merge: %v", err)
+	}
+	log.Printf("merged %s into %s; /wiki/%s now redirects", fromSlug, intoSlug, fromSlug)
+}
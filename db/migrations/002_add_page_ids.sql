@@ -0,0 +1,4 @@
+-- Dense sequence ids let /random probe a random id instead of sorting the
+-- table with ORDER BY RAND(). Existing rows are numbered on ALTER.
+ALTER TABLE pages
+    ADD COLUMN id BIGINT UNSIGNED NOT NULL AUTO_INCREMENT UNIQUE;
@@ -0,0 +1,8 @@
+-- One row per distinct internal link, maintained whenever page content is
+-- written. Targets may not exist yet; those rows make up the frontier.
+CREATE TABLE IF NOT EXISTS page_links (
+    source_slug VARCHAR(255) NOT NULL,
+    target_slug VARCHAR(255) NOT NULL,
+    PRIMARY KEY (source_slug, target_slug),
+    KEY page_links_target (target_slug)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
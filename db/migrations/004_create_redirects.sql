@@ -0,0 +1,8 @@
+-- Alias slugs that resolve to a canonical page. An alias never has its own
+-- row in pages; /wiki/{alias} redirects to /wiki/{target}.
+CREATE TABLE IF NOT EXISTS redirects (
+    alias_slug VARCHAR(255) NOT NULL PRIMARY KEY,
+    target_slug VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY redirects_target (target_slug)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
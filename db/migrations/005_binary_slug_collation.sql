@@ -0,0 +1,13 @@
+-- Compare slugs byte for byte. Slugs are NFC-normalized and case-folded before
+-- they reach the database, and utf8mb4_unicode_ci would otherwise treat
+-- distinct Unicode slugs (e.g. "ёж" and "еж") as the same primary key.
+ALTER TABLE pages
+    MODIFY slug VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL;
+
+ALTER TABLE page_links
+    MODIFY source_slug VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL,
+    MODIFY target_slug VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL;
+
+ALTER TABLE redirects
+    MODIFY alias_slug VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL,
+    MODIFY target_slug VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL;
@@ -0,0 +1,4 @@
+-- Display title parsed from the generated <h1>. An empty title means unknown,
+-- in which case it is derived from the slug. Run `endlesswiki backfill titles`
+-- afterwards to fill it in for existing pages.
+ALTER TABLE pages ADD COLUMN title VARCHAR(255) NOT NULL DEFAULT '' AFTER slug;
@@ -0,0 +1,16 @@
+-- Categories named by the model in each article's meta trailer. name is the
+-- normalized slug form of the title; parent is set when a category was first
+-- written as "Parent > Child".
+CREATE TABLE IF NOT EXISTS categories (
+    name VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL PRIMARY KEY,
+    title VARCHAR(255) NOT NULL,
+    parent VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NULL,
+    KEY categories_parent (parent)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS page_categories (
+    page_slug VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL,
+    category VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL,
+    PRIMARY KEY (page_slug, category),
+    KEY page_categories_category (category)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -0,0 +1,20 @@
+-- Validated infoboxes returned in each article's meta trailer. Number and date
+-- facts also store a numeric value (dates as the year, negative for BC) so
+-- /api/infoboxes can compare them; entity facts store the slug they name.
+CREATE TABLE IF NOT EXISTS infoboxes (
+    page_slug VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL PRIMARY KEY,
+    type VARCHAR(32) NOT NULL,
+    KEY infoboxes_type (type)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+CREATE TABLE IF NOT EXISTS infobox_facts (
+    page_slug VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL,
+    field VARCHAR(64) NOT NULL,
+    position INT NOT NULL,
+    value_text VARCHAR(255) NOT NULL,
+    value_number DOUBLE NULL,
+    value_slug VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NULL,
+    PRIMARY KEY (page_slug, field),
+    KEY infobox_facts_number (field, value_number),
+    KEY infobox_facts_slug (value_slug)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
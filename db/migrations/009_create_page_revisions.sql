@@ -0,0 +1,14 @@
+-- Article history. A page gets its first revisions when it is expanded: the
+-- original generation is recorded as revision 1 and each expansion appends the
+-- full resulting content. pages.content always holds the latest revision, and
+-- updated_at (NULL until the first expansion) drives Last-Modified.
+CREATE TABLE IF NOT EXISTS page_revisions (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    page_slug VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL,
+    content MEDIUMTEXT NOT NULL,
+    summary VARCHAR(255) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    KEY page_revisions_page (page_slug, id)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
+
+ALTER TABLE pages ADD COLUMN updated_at TIMESTAMP NULL DEFAULT NULL AFTER created_at;
@@ -0,0 +1,14 @@
+-- Lore claims each article makes about named entities, taken from its meta
+-- trailer and infobox. Facts about a subject are fed into the prompt when that
+-- subject's page is generated; two pages giving the same subject and attribute
+-- different value_key values are reported as a contradiction.
+CREATE TABLE IF NOT EXISTS page_facts (
+    id BIGINT NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    page_slug VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL,
+    subject_slug VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL,
+    attribute VARCHAR(64) NOT NULL,
+    value VARCHAR(255) NOT NULL,
+    value_key VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL,
+    KEY page_facts_page (page_slug),
+    KEY page_facts_subject (subject_slug, attribute)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
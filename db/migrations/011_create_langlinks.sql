@@ -0,0 +1,9 @@
+-- Cross-language links between editions. Each edition has its own database;
+-- a row says that the page page_slug here is the article target_slug in the
+-- edition for lang. Both sides are written when a translation is generated.
+CREATE TABLE IF NOT EXISTS langlinks (
+    page_slug VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL,
+    lang VARCHAR(16) NOT NULL,
+    target_slug VARCHAR(255) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL,
+    PRIMARY KEY (page_slug, lang)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci;
@@ -2,10 +2,11 @@ module endlesswiki
 
 go 1.25.0
 
-require github.com/go-sql-driver/mysql v1.9.3
-
 require (
-	filippo.io/edwards25519 v1.1.0 // indirect
-	golang.org/x/sync v0.17.0 // indirect
-	golang.org/x/text v0.29.0 // indirect
+	github.com/go-sql-driver/mysql v1.9.3
+	golang.org/x/net v0.44.0
+	golang.org/x/sync v0.17.0
+	golang.org/x/text v0.29.0
 )
+
+require filippo.io/edwards25519 v1.1.0 // indirect
@@ -0,0 +1,1094 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// /api.php speaks a subset of the MediaWiki action API, so that bots, reader
+// apps, and crawlers written for MediaWiki work against the page store. Every
+// page is in the main namespace (0) and has no wikitext; responses carry the
+// stored HTML instead.
+
+const (
+	actionAPIDefaultLimit = 10
+	actionAPIMaxLimit     = 500
+	// actionAPIMaxTitles bounds titles= and pageids=, as MediaWiki does for
+	// clients without the apihighlimits right.
+	actionAPIMaxTitles = 50
+	actionAPIMaxRandom = 50
+	// actionAPIMaxSearch and actionAPIMaxSearchOffset bound list=search, whose
+	// LIKE matching scans the pages table.
+	actionAPIMaxSearch       = 50
+	actionAPIMaxSearchOffset = 1000
+	// actionAPISnippetLen is the length of search snippets, in runes.
+	actionAPISnippetLen = 160
+	// actionAPITimeFormat is MediaWiki's ISO 8601 timestamp format, and
+	// actionAPIContinueTime its compact form in continuation values.
+	actionAPITimeFormat   = "2006-01-02T15:04:05Z"
+	actionAPIContinueTime = "20060102150405"
+)
+
+// Modules /api.php implements.
+var (
+	actionAPIProps = map[string]bool{"info": true, "links": true, "linkshere": true, "extracts": true}
+	actionAPILists = map[string]bool{"allpages": true, "search": true, "recentchanges": true, "random": true}
+	actionAPIMetas = map[string]bool{"siteinfo": true}
+	actionAPIParse = map[string]bool{"text": true, "links": true, "categories": true, "displaytitle": true}
+)
+
+// apiError is an error reported in MediaWiki's format: a machine-readable
+// code and a message for people.
+type apiError struct {
+	Code string
+	Info string
+}
+
+func (e *apiError) Error() string { return e.Code + ": " + e.Info }
+
+func badValue(param, value string) *apiError {
+	return &apiError{Code: "badvalue", Info: fmt.Sprintf("Unrecognized value for parameter %q: %s.", param, value)}
+}
+
+func missingParam(param string) *apiError {
+	return &apiError{Code: "missingparam", Info: fmt.Sprintf("The %q parameter must be set.", param)}
+}
+
+// apiRequest is one call to /api.php.
+type apiRequest struct {
+	s      *Server
+	ctx    context.Context
+	params url.Values
+	// v2 selects formatversion=2, with native booleans and page arrays.
+	v2 bool
+	// origin is the scheme and host the request came in on, for full URLs.
+	origin string
+
+	// continues holds the continuation values for the next request, and
+	// propContinues whether any belong to a page property, which leaves the
+	// batch incomplete.
+	continues     map[string]string
+	propContinues bool
+}
+
+// flag is a true boolean: true in formatversion 2, an empty string before.
+// False booleans are left out in both.
+func (q *apiRequest) flag() any {
+	if q.v2 {
+		return true
+	}
+	return ""
+}
+
+// content puts text under key in formatversion 2, and under "*" in an object
+// before.
+func (q *apiRequest) content(obj map[string]any, key string, text any) {
+	if q.v2 {
+		obj[key] = text
+	} else {
+		obj["*"] = text
+	}
+}
+
+func (q *apiRequest) setContinue(key, value string, prop bool) {
+	q.continues[key] = value
+	q.propContinues = q.propContinues || prop
+}
+
+// limit parses a limit parameter such as pllimit, which also accepts "max".
+func (q *apiRequest) limit(param string, def, max int) (int, error) {
+	switch raw := q.params.Get(param); raw {
+	case "":
+		return def, nil
+	case "max":
+		return max, nil
+	default:
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			return 0, &apiError{Code: "badinteger", Info: fmt.Sprintf("Invalid value %q for integer parameter %q.", raw, param)}
+		}
+		return min(n, max), nil
+	}
+}
+
+// has reports whether a boolean parameter is set. As in MediaWiki, any value
+// counts, even "false".
+func (q *apiRequest) has(param string) bool {
+	_, ok := q.params[param]
+	return ok
+}
+
+func (q *apiRequest) pageURL(slug string) string {
+	return q.origin + q.s.path("/wiki/"+url.PathEscape(slug))
+}
+
+// splitAPIList splits a multi-value parameter such as prop=info|links.
+func splitAPIList(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+	return strings.Split(raw, "|")
+}
+
+// requestOrigin returns the scheme and host r was addressed to.
+func requestOrigin(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	} else if proto := r.Header.Get("X-Forwarded-Proto"); proto == "https" || proto == "http" {
+		scheme = proto
+	}
+	return scheme + "://" + r.Host
+}
+
+// handleActionAPI serves /api.php. Only JSON output is supported; errors are
+// reported in the body with status 200, as MediaWiki does, except failures of
+// the database.
+func (s *Server) handleActionAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		writeAPIError(w, &apiError{Code: "badvalue", Info: err.Error()})
+		return
+	}
+	q := &apiRequest{
+		s:         s,
+		ctx:       r.Context(),
+		params:    r.Form,
+		origin:    requestOrigin(r),
+		continues: make(map[string]string),
+	}
+	// Anonymous cross-origin requests, as MediaWiki allows them.
+	if q.params.Get("origin") == "*" {
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	}
+	switch version := q.params.Get("formatversion"); version {
+	case "", "1":
+	case "2", "latest":
+		q.v2 = true
+	default:
+		writeAPIError(w, badValue("formatversion", version))
+		return
+	}
+	if format := q.params.Get("format"); format != "" && format != "json" && format != "jsonfm" {
+		writeAPIError(w, badValue("format", format))
+		return
+	}
+
+	var result map[string]any
+	var err error
+	switch action := q.params.Get("action"); action {
+	case "query":
+		result, err = q.query()
+	case "parse":
+		result, err = q.parse()
+	case "":
+		err = missingParam("action")
+	default:
+		err = badValue("action", action)
+	}
+
+	var apiErr *apiError
+	switch {
+	case errors.As(err, &apiErr):
+		writeAPIError(w, apiErr)
+	case err != nil:
+		log.Printf("api.php %s: %v", r.URL.RawQuery, err)
+		writeJSON(w, http.StatusInternalServerError, map[string]any{
+			"error": map[string]string{"code": "internal_api_error", "info": "database error"},
+		})
+	default:
+		writeJSON(w, http.StatusOK, result)
+	}
+}
+
+func writeAPIError(w http.ResponseWriter, err *apiError) {
+	w.Header().Set("MediaWiki-API-Error", err.Code)
+	writeJSON(w, http.StatusOK, map[string]any{
+		"error": map[string]string{"code": err.Code, "info": err.Info},
+	})
+}
+
+// query runs action=query: the page properties for titles= or pageids=, then
+// any lists and meta modules.
+func (q *apiRequest) query() (map[string]any, error) {
+	props, lists, metas := splitAPIList(q.params.Get("prop")), splitAPIList(q.params.Get("list")), splitAPIList(q.params.Get("meta"))
+	for _, check := range []struct {
+		param  string
+		values []string
+		known  map[string]bool
+	}{{"prop", props, actionAPIProps}, {"list", lists, actionAPILists}, {"meta", metas, actionAPIMetas}} {
+		for _, v := range check.values {
+			if !check.known[v] {
+				return nil, badValue(check.param, v)
+			}
+		}
+	}
+
+	out := make(map[string]any)
+	if q.has("titles") || q.has("pageids") {
+		pages, err := q.resolvePages(out)
+		if err != nil {
+			return nil, err
+		}
+		for _, prop := range props {
+			if err := q.queryProp(prop, pages); err != nil {
+				return nil, err
+			}
+		}
+		out["pages"] = q.pageSet(pages)
+	}
+	for _, list := range lists {
+		var entries []map[string]any
+		var err error
+		switch list {
+		case "allpages":
+			entries, err = q.allPages()
+		case "search":
+			entries, err = q.search()
+		case "recentchanges":
+			entries, err = q.recentChanges()
+		case "random":
+			entries, err = q.random()
+		}
+		if err != nil {
+			return nil, err
+		}
+		if entries == nil {
+			entries = []map[string]any{}
+		}
+		out[list] = entries
+	}
+	if len(metas) > 0 {
+		out["general"], out["namespaces"] = q.siteinfo()
+	}
+
+	result := map[string]any{}
+	if len(out) > 0 {
+		result["query"] = out
+	}
+	if len(q.continues) > 0 {
+		cont := map[string]string{"continue": "-||"}
+		if q.propContinues {
+			cont["continue"] = "||"
+		}
+		for k, v := range q.continues {
+			cont[k] = v
+		}
+		result["continue"] = cont
+	}
+	if !q.propContinues {
+		result["batchcomplete"] = q.flag()
+	}
+	return result, nil
+}
+
+// apiPage is a page named by titles= or pageids=. Missing pages have no id;
+// invalid titles have no slug either.
+type apiPage struct {
+	id        int64
+	slug      string
+	title     string
+	content   string
+	created   time.Time
+	updated   time.Time
+	lastRevID int64
+	invalid   string
+	// out is the page's entry in the response, which props add to.
+	out map[string]any
+}
+
+// resolvePages loads the pages titles= or pageids= names, recording title
+// normalization and, with redirects=, followed redirects in out as MediaWiki
+// does.
+func (q *apiRequest) resolvePages(out map[string]any) ([]*apiPage, error) {
+	titles, ids := splitAPIList(q.params.Get("titles")), splitAPIList(q.params.Get("pageids"))
+	if len(titles) > 0 && len(ids) > 0 {
+		return nil, &apiError{Code: "invalidparammix", Info: `The parameters "titles" and "pageids" can not be used together.`}
+	}
+	if len(titles)+len(ids) > actionAPIMaxTitles {
+		return nil, &apiError{Code: "toomanyvalues", Info: fmt.Sprintf("Too many values supplied; the limit is %d.", actionAPIMaxTitles)}
+	}
+
+	if len(ids) > 0 {
+		var args []any
+		for _, raw := range ids {
+			id, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				return nil, &apiError{Code: "badinteger", Info: fmt.Sprintf("Invalid value %q for integer parameter \"pageids\".", raw)}
+			}
+			args = append(args, id)
+		}
+		loaded, err := loadAPIPages(q.ctx, q.s.db, "id", args)
+		if err != nil {
+			return nil, err
+		}
+		var pages []*apiPage
+		for _, arg := range args {
+			id := arg.(int64)
+			page, ok := loaded[strconv.FormatInt(id, 10)]
+			if !ok {
+				page = &apiPage{id: id, out: map[string]any{"pageid": id, "missing": q.flag()}}
+			}
+			pages = append(pages, page)
+		}
+		return pages, nil
+	}
+
+	var pages []*apiPage
+	var slugs []any
+	var normalized []map[string]string
+	for _, title := range titles {
+		slug, err := NormalizeSlug(title)
+		if err != nil {
+			pages = append(pages, &apiPage{title: title, invalid: err.Error()})
+			continue
+		}
+		pages = append(pages, &apiPage{slug: slug, title: title})
+		slugs = append(slugs, slug)
+	}
+	loaded, err := loadAPIPages(q.ctx, q.s.db, "slug", slugs)
+	if err != nil {
+		return nil, err
+	}
+
+	var redirects []map[string]string
+	for i, page := range pages {
+		if page.invalid != "" {
+			page.out = map[string]any{"title": page.title, "invalid": q.flag(), "invalidreason": page.invalid}
+			continue
+		}
+		// name is the title MediaWiki would have normalized the request to.
+		name := SlugTitle(page.slug)
+		stored, ok := loaded[page.slug]
+		if ok {
+			name = stored.title
+		} else if q.has("redirects") {
+			target, err := lookupRedirect(q.ctx, q.s.db, page.slug)
+			if err != nil {
+				return nil, err
+			}
+			if target != "" {
+				targets, err := loadAPIPages(q.ctx, q.s.db, "slug", []any{target})
+				if err != nil {
+					return nil, err
+				}
+				if stored, ok = targets[target]; ok {
+					redirects = append(redirects, map[string]string{"from": name, "to": stored.title})
+				}
+			}
+		}
+		if !ok {
+			stored = &apiPage{slug: page.slug, title: name}
+			stored.out = map[string]any{"ns": 0, "title": name, "missing": q.flag()}
+		}
+		if page.title != name {
+			normalized = append(normalized, map[string]string{"from": page.title, "to": name})
+		}
+		pages[i] = stored
+	}
+	if normalized != nil {
+		out["normalized"] = normalized
+	}
+	if redirects != nil {
+		out["redirects"] = redirects
+	}
+	return pages, nil
+}
+
+// loadAPIPages loads the pages whose column ("slug" or "id") is one of
+// values, keyed by that column.
+func loadAPIPages(ctx context.Context, db *sql.DB, column string, values []any) (map[string]*apiPage, error) {
+	pages := make(map[string]*apiPage)
+	if len(values) == 0 {
+		return pages, nil
+	}
+	// column comes from the two callers above, never from the request.
+	query := `SELECT p.id, p.slug, p.title, p.content, p.created_at, p.updated_at,
+		COALESCE((SELECT MAX(r.id) FROM page_revisions r WHERE r.page_slug = p.slug), 0)
+		FROM pages p WHERE p.` + column + ` IN (?` + strings.Repeat(",?", len(values)-1) + `)`
+	err := scanRows(ctx, db, query, values, func(rows *sql.Rows) error {
+		p := &apiPage{}
+		var updated sql.NullTime
+		if err := rows.Scan(&p.id, &p.slug, &p.title, &p.content, &p.created, &updated, &p.lastRevID); err != nil {
+			return err
+		}
+		p.updated = updated.Time
+		p.title = DisplayTitle(p.slug, p.title)
+		p.out = map[string]any{"pageid": p.id, "ns": 0, "title": p.title}
+		if column == "id" {
+			pages[strconv.FormatInt(p.id, 10)] = p
+		} else {
+			pages[p.slug] = p
+		}
+		return nil
+	})
+	return pages, err
+}
+
+// pageSet is query.pages: an array in formatversion 2, an object keyed by
+// page id before, with missing pages numbered -1, -2, ….
+func (q *apiRequest) pageSet(pages []*apiPage) any {
+	seen := make(map[*apiPage]bool)
+	var list []map[string]any
+	keyed := make(map[string]map[string]any)
+	missing := 0
+	for _, page := range pages {
+		if seen[page] {
+			continue
+		}
+		seen[page] = true
+		list = append(list, page.out)
+		key := strconv.FormatInt(page.id, 10)
+		if page.id == 0 {
+			missing--
+			key = strconv.Itoa(missing)
+		}
+		keyed[key] = page.out
+	}
+	if q.v2 {
+		if list == nil {
+			list = []map[string]any{}
+		}
+		return list
+	}
+	return keyed
+}
+
+func (q *apiRequest) queryProp(prop string, pages []*apiPage) error {
+	switch prop {
+	case "info":
+		q.info(pages)
+	case "extracts":
+		q.extracts(pages)
+	case "links":
+		return q.links(pages)
+	case "linkshere":
+		return q.linksHere(pages)
+	}
+	return nil
+}
+
+// info implements prop=info, with inprop=url for page URLs.
+func (q *apiRequest) info(pages []*apiPage) {
+	withURL := false
+	for _, p := range splitAPIList(q.params.Get("inprop")) {
+		withURL = withURL || p == "url"
+	}
+	lang := q.s.cfg.lang()
+	for _, page := range pages {
+		if page.slug == "" {
+			continue
+		}
+		out := page.out
+		out["contentmodel"] = "html"
+		out["pagelanguage"] = lang
+		out["pagelanguagehtmlcode"] = lang
+		out["pagelanguagedir"] = "ltr"
+		if withURL {
+			out["fullurl"] = q.pageURL(page.slug)
+			out["canonicalurl"] = q.pageURL(page.slug)
+		}
+		if page.id == 0 {
+			continue
+		}
+		touched := page.created
+		if page.updated.After(touched) {
+			touched = page.updated
+		}
+		out["touched"] = touched.UTC().Format(actionAPITimeFormat)
+		out["lastrevid"] = page.lastRevID
+		out["length"] = len(page.content)
+		if page.updated.IsZero() {
+			out["new"] = q.flag()
+		}
+	}
+}
+
+var actionAPISectionPattern = regexp.MustCompile(`(?i)<h[2-6][\s>]`)
+
+// extracts implements prop=extracts: the article's HTML without its title,
+// or with explaintext its text. exintro keeps only the lead, and exchars
+// shortens plain text extracts.
+func (q *apiRequest) extracts(pages []*apiPage) {
+	intro, plain := q.has("exintro"), q.has("explaintext")
+	chars := math.MaxInt
+	if n, err := strconv.Atoi(q.params.Get("exchars")); err == nil && n > 0 {
+		chars = n
+	}
+	for _, page := range pages {
+		if page.id == 0 {
+			continue
+		}
+		var extract string
+		switch {
+		case plain && intro:
+			extract = leadExcerpt(page.content, chars)
+		case plain:
+			extract = truncateWords(pageText(articleBody(page.content), math.MaxInt), chars)
+		default:
+			extract = articleBody(page.content)
+			if loc := actionAPISectionPattern.FindStringIndex(extract); intro && loc != nil {
+				extract = strings.TrimSpace(extract[:loc[0]])
+			}
+		}
+		page.out["extract"] = extract
+	}
+}
+
+// articleBody returns the HTML of an article after its <h1>, without the
+// endlesswiki-body wrapper.
+func articleBody(content string) string {
+	body := content[headingEnd(content):]
+	const wrapper = `<div class="endlesswiki-body">`
+	if start := strings.Index(body, wrapper); start >= 0 {
+		if end := strings.LastIndex(body, "</div>"); end > start {
+			body = body[start+len(wrapper) : end]
+		}
+	}
+	return strings.TrimSpace(body)
+}
+
+// apiContinue parses a continuation value of the form "a|b".
+func (q *apiRequest) apiContinue(param string) (string, string, bool, error) {
+	raw := q.params.Get(param)
+	if raw == "" {
+		return "", "", false, nil
+	}
+	a, b, ok := strings.Cut(raw, "|")
+	if !ok {
+		return "", "", false, &apiError{Code: "badcontinue", Info: "Invalid continue param. You should pass the original value returned by the previous query."}
+	}
+	return a, b, true, nil
+}
+
+// links implements prop=links: the pages each page links to, whether written
+// yet or not, in slug order.
+func (q *apiRequest) links(pages []*apiPage) error {
+	return q.linkProp(pages, "links", "pl", "source_slug", "target_slug",
+		`SELECT l.source_slug, l.target_slug, 0, COALESCE(p.title, '') FROM page_links l
+			LEFT JOIN pages p ON p.slug = l.target_slug`)
+}
+
+// linksHere implements prop=linkshere: the pages linking to each page.
+func (q *apiRequest) linksHere(pages []*apiPage) error {
+	return q.linkProp(pages, "linkshere", "lh", "target_slug", "source_slug",
+		`SELECT l.target_slug, l.source_slug, p.id, p.title FROM page_links l
+			JOIN pages p ON p.slug = l.source_slug`)
+}
+
+// linkProp lists page_links rows whose from column is one of the pages, in
+// order of from and then to. Rows carry the linked page's id and title.
+func (q *apiRequest) linkProp(pages []*apiPage, prop, prefix, from, to, query string) error {
+	limit, err := q.limit(prefix+"limit", actionAPIDefaultLimit, actionAPIMaxLimit)
+	if err != nil {
+		return err
+	}
+	bySlug := make(map[string]*apiPage)
+	var args []any
+	for _, page := range pages {
+		if page.slug != "" && bySlug[page.slug] == nil {
+			bySlug[page.slug] = page
+			args = append(args, page.slug)
+		}
+	}
+	if len(args) == 0 {
+		return nil
+	}
+
+	// from and to name page_links columns, never request values.
+	query += " WHERE l." + from + " IN (?" + strings.Repeat(",?", len(args)-1) + ")"
+	if after, next, ok, err := q.apiContinue(prefix + "continue"); err != nil {
+		return err
+	} else if ok {
+		query += " AND (l." + from + " > ? OR (l." + from + " = ? AND l." + to + " >= ?))"
+		args = append(args, after, after, next)
+	}
+	query += " ORDER BY l." + from + ", l." + to + " LIMIT ?"
+	args = append(args, limit+1)
+
+	n := 0
+	return scanRows(q.ctx, q.s.db, query, args, func(rows *sql.Rows) error {
+		var owner, slug, title string
+		var id int64
+		if err := rows.Scan(&owner, &slug, &id, &title); err != nil {
+			return err
+		}
+		if n++; n > limit {
+			q.setContinue(prefix+"continue", owner+"|"+slug, true)
+			return nil
+		}
+		entry := map[string]any{"ns": 0, "title": DisplayTitle(slug, title)}
+		if id != 0 {
+			entry["pageid"] = id
+		}
+		page := bySlug[owner]
+		list, _ := page.out[prop].([]map[string]any)
+		page.out[prop] = append(list, entry)
+		return nil
+	})
+}
+
+// allPages implements list=allpages, in slug order.
+func (q *apiRequest) allPages() ([]map[string]any, error) {
+	limit, err := q.limit("aplimit", actionAPIDefaultLimit, actionAPIMaxLimit)
+	if err != nil {
+		return nil, err
+	}
+	if ns := q.params.Get("apnamespace"); ns != "" && ns != "0" {
+		return nil, nil
+	}
+
+	query := `SELECT id, slug, title FROM pages WHERE slug >= ?`
+	from := q.params.Get("apcontinue")
+	if from == "" && q.params.Get("apfrom") != "" {
+		if from, err = NormalizeSlug(q.params.Get("apfrom")); err != nil {
+			return nil, badValue("apfrom", q.params.Get("apfrom"))
+		}
+	}
+	args := []any{from}
+	if raw := q.params.Get("apprefix"); raw != "" {
+		prefix, err := NormalizeSlug(raw)
+		if err != nil {
+			return nil, badValue("apprefix", raw)
+		}
+		query += ` AND slug LIKE ?`
+		args = append(args, likePrefix(prefix))
+	}
+	query += ` ORDER BY slug LIMIT ?`
+	args = append(args, limit+1)
+
+	var entries []map[string]any
+	err = scanRows(q.ctx, q.s.db, query, args, func(rows *sql.Rows) error {
+		var id int64
+		var slug, title string
+		if err := rows.Scan(&id, &slug, &title); err != nil {
+			return err
+		}
+		if len(entries) == limit {
+			q.setContinue("apcontinue", slug, false)
+			return nil
+		}
+		entries = append(entries, map[string]any{"pageid": id, "ns": 0, "title": DisplayTitle(slug, title)})
+		return nil
+	})
+	return entries, err
+}
+
+// likePrefix is a LIKE pattern matching strings that start with prefix.
+func likePrefix(prefix string) string {
+	return likeEscape(prefix) + "%"
+}
+
+// likeEscape quotes the LIKE wildcards in s.
+func likeEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// search implements list=search with the matching /search uses, on slugs,
+// titles, and content, newest first. Results carry MediaWiki's default size,
+// word count, and snippet, which are computed from the content of at most
+// actionAPIMaxSearch pages per request.
+func (q *apiRequest) search() ([]map[string]any, error) {
+	term := strings.TrimSpace(q.params.Get("srsearch"))
+	if term == "" {
+		return nil, missingParam("srsearch")
+	}
+	limit, err := q.limit("srlimit", actionAPIDefaultLimit, actionAPIMaxSearch)
+	if err != nil {
+		return nil, err
+	}
+	offset := 0
+	if raw := q.params.Get("sroffset"); raw != "" {
+		if offset, err = strconv.Atoi(raw); err != nil || offset < 0 || offset > actionAPIMaxSearchOffset {
+			return nil, badValue("sroffset", raw)
+		}
+	}
+
+	const query = `SELECT id, slug, title, content, created_at, updated_at FROM pages
+		WHERE slug LIKE ? OR title LIKE ? OR content LIKE ?
+		ORDER BY created_at DESC LIMIT ? OFFSET ?`
+	like := "%" + likeEscape(term) + "%"
+	args := []any{strings.ToLower(like), like, like, limit + 1, offset}
+
+	var entries []map[string]any
+	err = scanRows(q.ctx, q.s.db, query, args, func(rows *sql.Rows) error {
+		var id int64
+		var slug, title, content string
+		var created time.Time
+		var updated sql.NullTime
+		if err := rows.Scan(&id, &slug, &title, &content, &created, &updated); err != nil {
+			return err
+		}
+		if len(entries) == limit {
+			if next := offset + limit; next <= actionAPIMaxSearchOffset {
+				q.setContinue("sroffset", strconv.Itoa(next), false)
+			}
+			return nil
+		}
+		if updated.Valid && updated.Time.After(created) {
+			created = updated.Time
+		}
+		text := pageText(articleBody(content), math.MaxInt)
+		entries = append(entries, map[string]any{
+			"ns":        0,
+			"title":     DisplayTitle(slug, title),
+			"pageid":    id,
+			"size":      len(content),
+			"wordcount": len(strings.Fields(text)),
+			"snippet":   searchSnippet(text, term, actionAPISnippetLen),
+			"timestamp": created.UTC().Format(actionAPITimeFormat),
+		})
+		return nil
+	})
+	return entries, err
+}
+
+// searchSnippet returns escaped text around the first case-insensitive match
+// of term, about width runes long, with the match wrapped in a searchmatch
+// span as MediaWiki marks it.
+func searchSnippet(text, term string, width int) string {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	needle := []rune(strings.ToLower(term))
+	at := -1
+	if len(lower) == len(runes) {
+		for i := 0; i+len(needle) <= len(lower); i++ {
+			if string(lower[i:i+len(needle)]) == string(needle) {
+				at = i
+				break
+			}
+		}
+	}
+	if at < 0 {
+		return html.EscapeString(truncateWords(text, width))
+	}
+
+	start := max(0, at-width/3)
+	if i := strings.LastIndexByte(string(runes[:start]), ' '); start > 0 && i >= 0 {
+		start = utf8.RuneCountInString(string(runes[:start])[:i+1])
+	}
+	end := min(len(runes), max(start+width, at+len(needle)))
+	if end < len(runes) {
+		// Cut the tail at a word boundary too.
+		for i := end; i > at+len(needle); i-- {
+			if runes[i] == ' ' {
+				end = i
+				break
+			}
+		}
+	}
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("… ")
+	}
+	b.WriteString(html.EscapeString(string(runes[start:at])))
+	b.WriteString(`<span class="searchmatch">`)
+	b.WriteString(html.EscapeString(string(runes[at : at+len(needle)])))
+	b.WriteString("</span>")
+	b.WriteString(html.EscapeString(string(runes[at+len(needle) : end])))
+	if end < len(runes) {
+		b.WriteString(" …")
+	}
+	return b.String()
+}
+
+// recentChanges implements list=recentchanges: page creations and the
+// expansions recorded in page_revisions, newest first. Revision ids are the
+// ones prop=info reports as lastrevid.
+func (q *apiRequest) recentChanges() ([]map[string]any, error) {
+	limit, err := q.limit("rclimit", actionAPIDefaultLimit, actionAPIMaxLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	// Creations come from pages; a page's first revision is its creation, so
+	// only later revisions are edits. Both are ordered by time, then kind,
+	// then id, which rccontinue records.
+	branches := []struct {
+		kind, at, id string
+		where        string
+		args         []any
+	}{
+		{kind: "new", at: "p.created_at", id: "p.id"},
+		{kind: "edit", at: "r.created_at", id: "r.id"},
+	}
+	if stamp, rest, ok, err := q.apiContinue("rccontinue"); err != nil {
+		return nil, err
+	} else if ok {
+		kind, rawID, _ := strings.Cut(rest, "|")
+		at, timeErr := time.Parse(actionAPIContinueTime, stamp)
+		id, idErr := strconv.ParseInt(rawID, 10, 64)
+		if (kind != "new" && kind != "edit") || timeErr != nil || idErr != nil {
+			return nil, &apiError{Code: "badcontinue", Info: "Invalid continue param. You should pass the original value returned by the previous query."}
+		}
+		for i := range branches {
+			br := &branches[i]
+			switch {
+			case br.kind == kind:
+				br.where = " AND (" + br.at + " < ? OR (" + br.at + " = ? AND " + br.id + " <= ?))"
+				br.args = []any{at, at, id}
+			case br.kind > kind:
+				br.where = " AND " + br.at + " <= ?"
+				br.args = []any{at}
+			default:
+				br.where = " AND " + br.at + " < ?"
+				br.args = []any{at}
+			}
+		}
+	}
+
+	query := `(SELECT 'new' AS kind, p.id AS seq, p.id AS pageid, p.slug, p.title,
+			COALESCE(first.id, 0) AS revid, 0 AS old_revid, 0 AS oldlen,
+			COALESCE(LENGTH(first.content), LENGTH(p.content)) AS newlen, p.created_at AS at
+		FROM pages p
+		LEFT JOIN page_revisions first ON first.id = (SELECT MIN(r.id) FROM page_revisions r WHERE r.page_slug = p.slug)
+		WHERE TRUE` + branches[0].where + `
+		ORDER BY p.created_at DESC, p.id DESC LIMIT ?)
+	UNION ALL
+	(SELECT 'edit', r.id, p.id, p.slug, p.title, r.id, prev.id, LENGTH(prev.content), LENGTH(r.content), r.created_at
+		FROM page_revisions r
+		JOIN pages p ON p.slug = r.page_slug
+		JOIN page_revisions prev ON prev.id = (SELECT MAX(o.id) FROM page_revisions o WHERE o.page_slug = r.page_slug AND o.id < r.id)
+		WHERE TRUE` + branches[1].where + `
+		ORDER BY r.created_at DESC, r.id DESC LIMIT ?)
+	ORDER BY at DESC, kind, seq DESC LIMIT ?`
+	args := append(append(branches[0].args, limit+1), append(branches[1].args, limit+1, limit+1)...)
+
+	var entries []map[string]any
+	err = scanRows(q.ctx, q.s.db, query, args, func(rows *sql.Rows) error {
+		var kind, slug, title string
+		var seq, pageID, revID, oldRevID int64
+		var oldLen, newLen int
+		var at time.Time
+		if err := rows.Scan(&kind, &seq, &pageID, &slug, &title, &revID, &oldRevID, &oldLen, &newLen, &at); err != nil {
+			return err
+		}
+		if len(entries) == limit {
+			q.setContinue("rccontinue", at.UTC().Format(actionAPIContinueTime)+"|"+kind+"|"+strconv.FormatInt(seq, 10), false)
+			return nil
+		}
+		entries = append(entries, map[string]any{
+			"type":      kind,
+			"ns":        0,
+			"title":     DisplayTitle(slug, title),
+			"pageid":    pageID,
+			"revid":     revID,
+			"old_revid": oldRevID,
+			"oldlen":    oldLen,
+			"newlen":    newLen,
+			"timestamp": at.UTC().Format(actionAPITimeFormat),
+		})
+		return nil
+	})
+	return entries, err
+}
+
+// random implements list=random by sampling like /random does.
+func (q *apiRequest) random() ([]map[string]any, error) {
+	limit, err := q.limit("rnlimit", 1, actionAPIMaxRandom)
+	if err != nil {
+		return nil, err
+	}
+	if ns := q.params.Get("rnnamespace"); ns != "" && ns != "0" {
+		return nil, nil
+	}
+
+	var slugs []any
+	picked := make(map[string]bool)
+	// Small wikis cannot fill the limit with distinct pages; give up after a
+	// few repeats rather than probing forever.
+	for tries := 0; len(slugs) < limit && tries < 3*limit; tries++ {
+		slug, err := q.s.randomSlug(q.ctx)
+		if err != nil {
+			return nil, err
+		}
+		if slug == "" {
+			break
+		}
+		if !picked[slug] {
+			picked[slug] = true
+			slugs = append(slugs, slug)
+		}
+	}
+	pages, err := loadAPIPages(q.ctx, q.s.db, "slug", slugs)
+	if err != nil {
+		return nil, err
+	}
+	var entries []map[string]any
+	for _, slug := range slugs {
+		if page, ok := pages[slug.(string)]; ok {
+			entries = append(entries, map[string]any{"id": page.id, "ns": 0, "title": page.title})
+		}
+	}
+	return entries, nil
+}
+
+// siteinfo implements meta=siteinfo with the general and namespaces
+// properties, which client libraries request on connecting.
+func (q *apiRequest) siteinfo() (map[string]any, map[string]any) {
+	name := "EndlessWiki"
+	if q.s.cfg.World != "" {
+		name += " (" + q.s.cfg.World + ")"
+	}
+	general := map[string]any{
+		"mainpage":    SlugTitle("main_page"),
+		"base":        q.pageURL("main_page"),
+		"sitename":    name,
+		"generator":   "EndlessWiki",
+		"lang":        q.s.cfg.lang(),
+		"case":        "case-insensitive",
+		"server":      q.origin,
+		"articlepath": q.s.path("/wiki/$1"),
+		"scriptpath":  q.s.cfg.BasePath,
+		"script":      q.s.path("/api.php"),
+	}
+	main := map[string]any{"id": 0, "case": "case-insensitive", "content": q.flag()}
+	q.content(main, "name", "")
+	return general, map[string]any{"0": main}
+}
+
+// parse implements action=parse for a stored page, named by page= or
+// pageid=. It never generates missing pages.
+func (q *apiRequest) parse() (map[string]any, error) {
+	props := splitAPIList(q.params.Get("prop"))
+	if !q.has("prop") {
+		props = []string{"text", "links", "categories", "displaytitle"}
+	}
+	for _, prop := range props {
+		if !actionAPIParse[prop] {
+			return nil, badValue("prop", prop)
+		}
+	}
+
+	var page *apiPage
+	var redirects []map[string]string
+	switch {
+	case q.params.Get("page") != "":
+		slug, err := NormalizeSlug(q.params.Get("page"))
+		if err != nil {
+			return nil, &apiError{Code: "invalidtitle", Info: fmt.Sprintf("Bad title %q.", q.params.Get("page"))}
+		}
+		alias := ""
+		if q.has("redirects") {
+			target, err := lookupRedirect(q.ctx, q.s.db, slug)
+			if err != nil {
+				return nil, err
+			}
+			if target != "" {
+				alias, slug = slug, target
+			}
+		}
+		pages, err := loadAPIPages(q.ctx, q.s.db, "slug", []any{slug})
+		if err != nil {
+			return nil, err
+		}
+		if page = pages[slug]; page == nil {
+			return nil, &apiError{Code: "missingtitle", Info: "The page you specified doesn't exist."}
+		}
+		if alias != "" {
+			redirects = append(redirects, map[string]string{"from": SlugTitle(alias), "to": page.title})
+		}
+	case q.params.Get("pageid") != "":
+		id, err := strconv.ParseInt(q.params.Get("pageid"), 10, 64)
+		if err != nil {
+			return nil, &apiError{Code: "badinteger", Info: fmt.Sprintf("Invalid value %q for integer parameter \"pageid\".", q.params.Get("pageid"))}
+		}
+		pages, err := loadAPIPages(q.ctx, q.s.db, "id", []any{id})
+		if err != nil {
+			return nil, err
+		}
+		if page = pages[strconv.FormatInt(id, 10)]; page == nil {
+			return nil, &apiError{Code: "nosuchpageid", Info: fmt.Sprintf("There is no page with ID %d.", id)}
+		}
+	default:
+		return nil, missingParam("page")
+	}
+
+	out := map[string]any{"title": page.title, "pageid": page.id}
+	if redirects != nil {
+		out["redirects"] = redirects
+	}
+	linked := ExtractLinkedSlugs(page.content)
+	missing, err := q.s.missingSlugs(q.ctx, linked)
+	if err != nil {
+		return nil, err
+	}
+	for _, prop := range props {
+		switch prop {
+		case "text":
+			text := decorateInternalLinksUnder(q.s.cfg.BasePath, articleBody(page.content), page.slug, missing)
+			if q.v2 {
+				out["text"] = text
+			} else {
+				out["text"] = map[string]any{"*": text}
+			}
+		case "displaytitle":
+			out["displaytitle"] = html.EscapeString(page.title)
+		case "links":
+			titles, err := pageTitles(q.ctx, q.s.db, linked)
+			if err != nil {
+				return nil, err
+			}
+			links := []map[string]any{}
+			for _, slug := range linked {
+				link := map[string]any{"ns": 0}
+				if _, ok := missing[slug]; !ok {
+					link["exists"] = q.flag()
+				}
+				q.content(link, "title", DisplayTitle(slug, titles[slug]))
+				links = append(links, link)
+			}
+			out["links"] = links
+		case "categories":
+			categories, err := pageCategories(q.ctx, q.s.db, page.slug)
+			if err != nil {
+				return nil, err
+			}
+			list := []map[string]any{}
+			for _, c := range categories {
+				category := map[string]any{"sortkey": ""}
+				q.content(category, "category", c.Name)
+				list = append(list, category)
+			}
+			out["categories"] = list
+		}
+	}
+	return map[string]any{"parse": out}, nil
+}
+
+// pageTitles returns the stored titles of those slugs that are pages.
+func pageTitles(ctx context.Context, db *sql.DB, slugs []string) (map[string]string, error) {
+	titles := make(map[string]string)
+	if len(slugs) == 0 {
+		return titles, nil
+	}
+	args := make([]any, len(slugs))
+	for i, slug := range slugs {
+		args[i] = slug
+	}
+	query := `SELECT slug, title FROM pages WHERE slug IN (?` + strings.Repeat(",?", len(slugs)-1) + `)`
+	err := scanRows(ctx, db, query, args, func(rows *sql.Rows) error {
+		var slug, title string
+		if err := rows.Scan(&slug, &title); err != nil {
+			return err
+		}
+		titles[slug] = title
+		return nil
+	})
+	return titles, err
+}
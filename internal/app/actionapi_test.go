@@ -0,0 +1,128 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func callActionAPI(t *testing.T, srv *Server, query string) (map[string]any, *httptest.ResponseRecorder) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api.php?"+query, nil))
+	var body map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("%s: %v\n%s", query, err, rec.Body)
+	}
+	return body, rec
+}
+
+func TestActionAPIErrors(t *testing.T) {
+	srv, err := NewServer(nil, Config{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	for query, code := range map[string]string{
+		"":                                      "missingparam",
+		"action=edit":                           "badvalue",
+		"action=query&prop=revisions":           "badvalue",
+		"action=query&list=allpages&format=xml": "badvalue",
+		"action=query&formatversion=3":          "badvalue",
+		"action=query&titles=A&pageids=1":       "invalidparammix",
+		"action=query&titles=" + strings.Repeat("a|", actionAPIMaxTitles) + "a": "toomanyvalues",
+		"action=query&list=allpages&aplimit=lots":                               "badinteger",
+		"action=query&list=search":                                              "missingparam",
+		"action=query&list=search&srsearch=a&sroffset=1001":                     "badvalue",
+		"action=query&list=recentchanges&rccontinue=20240101000000|move|1":      "badcontinue",
+		"action=parse":                         "missingparam",
+		"action=parse&page=a/b":                "invalidtitle",
+		"action=parse&page=Rome&prop=wikitext": "badvalue",
+	} {
+		body, rec := callActionAPI(t, srv, query)
+		apiErr, _ := body["error"].(map[string]any)
+		if rec.Code != http.StatusOK || apiErr["code"] != code || rec.Header().Get("MediaWiki-API-Error") != code {
+			t.Errorf("%q: status %d, body %v, want error %s", query, rec.Code, body, code)
+		}
+	}
+}
+
+func TestActionAPIInvalidTitles(t *testing.T) {
+	srv, err := NewServer(nil, Config{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	body, _ := callActionAPI(t, srv, "action=query&prop=info&titles=a/b")
+	pages := body["query"].(map[string]any)["pages"].(map[string]any)
+	page, ok := pages["-1"].(map[string]any)
+	if !ok || page["title"] != "a/b" || page["invalid"] != "" || page["invalidreason"] == nil {
+		t.Errorf("pages = %v", pages)
+	}
+	if body["batchcomplete"] != "" {
+		t.Errorf("batchcomplete = %v", body["batchcomplete"])
+	}
+
+	body, _ = callActionAPI(t, srv, "action=query&titles=a/b&formatversion=2")
+	list := body["query"].(map[string]any)["pages"].([]any)
+	if len(list) != 1 || list[0].(map[string]any)["invalid"] != true || body["batchcomplete"] != true {
+		t.Errorf("formatversion 2 = %v", body)
+	}
+}
+
+func TestActionAPISiteinfo(t *testing.T) {
+	srv, err := NewServer(nil, Config{World: "scifi", BasePath: "/w/scifi"})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api.php?action=query&meta=siteinfo&origin=*", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	srv.ServeHTTP(rec, req)
+	if rec.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Error("origin=* did not allow cross-origin reads")
+	}
+	var body struct {
+		Query struct {
+			General    map[string]string
+			Namespaces map[string]map[string]any
+		}
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	general := body.Query.General
+	if general["sitename"] != "EndlessWiki (scifi)" || general["articlepath"] != "/w/scifi/wiki/$1" ||
+		general["base"] != "https://example.com/w/scifi/wiki/main_page" || general["script"] != "/w/scifi/api.php" {
+		t.Errorf("general = %v", general)
+	}
+	if main := body.Query.Namespaces["0"]; main["*"] != "" || main["content"] != "" {
+		t.Errorf("namespaces = %v", body.Query.Namespaces)
+	}
+}
+
+func TestArticleBody(t *testing.T) {
+	content := "<h1>Rome</h1>\n<div class=\"endlesswiki-body\">\n<p>Lead.</p>\n<h2>History</h2>\n<div>x</div>\n</div>\n"
+	if got := articleBody(content); got != "<p>Lead.</p>\n<h2>History</h2>\n<div>x</div>" {
+		t.Errorf("articleBody = %q", got)
+	}
+	if got := articleBody("<h1>Old</h1><p>Unwrapped.</p>"); got != "<p>Unwrapped.</p>" {
+		t.Errorf("articleBody without wrapper = %q", got)
+	}
+}
+
+func TestSearchSnippet(t *testing.T) {
+	text := "The Tiber flows through Rome & into the sea at Ostia, past the ancient harbour."
+	if got := searchSnippet(text, "rome", 40); got != `… flows through <span class="searchmatch">Rome</span> &amp; into the sea at …` {
+		t.Errorf("snippet = %q", got)
+	}
+	if got := searchSnippet(text, "carthage", 20); got != "The Tiber flows…" {
+		t.Errorf("snippet without match = %q", got)
+	}
+}
+
+func TestLikePrefix(t *testing.T) {
+	if got := likePrefix(`a_b%c\`); got != `a\_b\%c\\%` {
+		t.Errorf("likePrefix = %q", got)
+	}
+}
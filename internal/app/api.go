@@ -0,0 +1,222 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// writeJSON encodes v as the response body.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("encode json: %v", err)
+	}
+}
+
+func writeJSONError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"error": message})
+}
+
+// infoboxCondition is one ?where= filter on infobox facts, e.g. founded<1500.
+type infoboxCondition struct {
+	Field string
+	Op    string
+	// column is the infobox_facts column compared against Value.
+	column string
+	Value  any
+}
+
+var infoboxConditionPattern = regexp.MustCompile(`^\s*([a-z0-9_]+)\s*(<=|>=|=|<|>)\s*(.+?)\s*$`)
+
+// parseInfoboxCondition validates a filter against the schema of kind, or
+// against every schema when kind is empty. Number and date fields compare
+// numerically (dates by year), entity fields by slug, and text fields only
+// support equality.
+func parseInfoboxCondition(raw, kind string) (infoboxCondition, error) {
+	m := infoboxConditionPattern.FindStringSubmatch(raw)
+	if m == nil {
+		return infoboxCondition{}, fmt.Errorf("malformed condition %q; use field<value, field>value, or field=value", raw)
+	}
+	cond := infoboxCondition{Field: m[1], Op: m[2]}
+
+	field, ok := lookupInfoboxField(cond.Field, kind)
+	if !ok {
+		return infoboxCondition{}, fmt.Errorf("unknown infobox field %q", cond.Field)
+	}
+
+	switch field.Kind {
+	case fieldNumber, fieldDate:
+		parse := parseInfoboxNumber
+		if field.Kind == fieldDate {
+			parse = parseInfoboxYear
+		}
+		n, ok := parse(m[3])
+		if !ok {
+			return infoboxCondition{}, fmt.Errorf("field %q needs a numeric value", cond.Field)
+		}
+		cond.column, cond.Value = "value_number", n
+	case fieldEntity:
+		slug, err := NormalizeSlug(m[3])
+		if err != nil || cond.Op != "=" {
+			return infoboxCondition{}, fmt.Errorf("field %q only supports = with an article name", cond.Field)
+		}
+		cond.column, cond.Value = "value_slug", slug
+	default:
+		if cond.Op != "=" {
+			return infoboxCondition{}, fmt.Errorf("field %q only supports =", cond.Field)
+		}
+		cond.column, cond.Value = "value_text", m[3]
+	}
+	return cond, nil
+}
+
+// lookupInfoboxField finds name in the schema of kind, or in the first schema
+// that has it when kind is empty.
+func lookupInfoboxField(name, kind string) (infoboxField, bool) {
+	for _, schema := range infoboxSchemas {
+		if kind != "" && schema.Type != kind {
+			continue
+		}
+		if field, ok := schema.field(name); ok {
+			return field, true
+		}
+	}
+	return infoboxField{}, false
+}
+
+type infoboxAPIFact struct {
+	Field  string   `json:"field"`
+	Value  string   `json:"value"`
+	Number *float64 `json:"number,omitempty"`
+	Slug   string   `json:"slug,omitempty"`
+}
+
+type infoboxAPIResult struct {
+	Slug  string           `json:"slug"`
+	Title string           `json:"title"`
+	URL   string           `json:"url"`
+	Type  string           `json:"type"`
+	Facts []infoboxAPIFact `json:"facts"`
+}
+
+type infoboxAPIResponse struct {
+	Results    []infoboxAPIResult `json:"results"`
+	NextOffset *int               `json:"next_offset,omitempty"`
+}
+
+// queryInfoboxes returns pages whose infobox has type kind (any type when
+// empty) and satisfies every condition, ordered by slug.
+func queryInfoboxes(ctx context.Context, db *sql.DB, kind string, conds []infoboxCondition, limit, offset int) ([]infoboxAPIResult, error) {
+	var where []string
+	var args []any
+	if kind != "" {
+		where = append(where, "i.type = ?")
+		args = append(args, kind)
+	}
+	for _, cond := range conds {
+		// column and Op come from fixed sets, never from the request.
+		where = append(where, `EXISTS (SELECT 1 FROM infobox_facts f
+			WHERE f.page_slug = i.page_slug AND f.field = ? AND f.`+cond.column+" "+cond.Op+" ?)")
+		args = append(args, cond.Field, cond.Value)
+	}
+
+	query := `SELECT i.page_slug, i.type, p.title FROM infoboxes i
+		JOIN pages p ON p.slug = i.page_slug`
+	if len(where) > 0 {
+		query += " WHERE " + strings.Join(where, " AND ")
+	}
+	query += " ORDER BY i.page_slug LIMIT ? OFFSET ?"
+	args = append(args, limit, offset)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []infoboxAPIResult
+	var slugs []string
+	for rows.Next() {
+		var result infoboxAPIResult
+		if err := rows.Scan(&result.Slug, &result.Type, &result.Title); err != nil {
+			return nil, err
+		}
+		result.Title = DisplayTitle(result.Slug, result.Title)
+		result.URL = "/wiki/" + url.PathEscape(result.Slug)
+		results = append(results, result)
+		slugs = append(slugs, result.Slug)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	facts, err := loadInfoboxFacts(ctx, db, slugs)
+	if err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].Facts = []infoboxAPIFact{}
+		for _, fact := range facts[results[i].Slug] {
+			results[i].Facts = append(results[i].Facts, infoboxAPIFact(fact))
+		}
+	}
+	return results, nil
+}
+
+// handleInfoboxAPI serves /api/infoboxes?type=city&where=founded<1500. Each
+// where parameter adds a condition; limit and offset page through results.
+func (s *Server) handleInfoboxAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	kind := strings.ToLower(strings.TrimSpace(query.Get("type")))
+	if kind != "" {
+		if _, ok := findInfoboxSchema(kind); !ok {
+			writeJSONError(w, http.StatusBadRequest, fmt.Sprintf("unknown infobox type %q", kind))
+			return
+		}
+	}
+
+	var conds []infoboxCondition
+	for _, raw := range query["where"] {
+		cond, err := parseInfoboxCondition(raw, kind)
+		if err != nil {
+			writeJSONError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		conds = append(conds, cond)
+	}
+
+	limit, offset := pagination(r)
+	results, err := queryInfoboxes(r.Context(), s.db, kind, conds, limit+1, offset)
+	if err != nil {
+		log.Printf("infobox query: %v", err)
+		writeJSONError(w, http.StatusInternalServerError, "infobox query failed")
+		return
+	}
+
+	for i := range results {
+		results[i].URL = s.path(results[i].URL)
+	}
+	resp := infoboxAPIResponse{Results: results}
+	if len(results) > limit {
+		resp.Results = results[:limit]
+		next := offset + limit
+		resp.NextOffset = &next
+	}
+	if resp.Results == nil {
+		resp.Results = []infoboxAPIResult{}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
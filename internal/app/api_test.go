@@ -0,0 +1,40 @@
+package app
+
+import "testing"
+
+func TestParseInfoboxCondition(t *testing.T) {
+	cond, err := parseInfoboxCondition("founded<1500", "city")
+	if err != nil {
+		t.Fatalf("parseInfoboxCondition: %v", err)
+	}
+	if cond.Field != "founded" || cond.Op != "<" || cond.column != "value_number" || cond.Value != 1500.0 {
+		t.Fatalf("cond = %+v", cond)
+	}
+
+	cond, err = parseInfoboxCondition("founded >= 300 BC", "")
+	if err != nil || cond.Value != -300.0 || cond.Op != ">=" {
+		t.Fatalf("BC condition = %+v, %v", cond, err)
+	}
+
+	cond, err = parseInfoboxCondition("country=Kingdom of Veldor", "city")
+	if err != nil || cond.column != "value_slug" || cond.Value != "kingdom_of_veldor" {
+		t.Fatalf("entity condition = %+v, %v", cond, err)
+	}
+}
+
+func TestParseInfoboxConditionRejectsInvalid(t *testing.T) {
+	for _, raw := range []string{
+		"founded",         // no operator
+		"capital<5",       // entity fields only support =
+		"government>x",    // text fields only support =
+		"population<many", // not a number
+		"crew=4",          // unknown field
+	} {
+		if _, err := parseInfoboxCondition(raw, ""); err == nil {
+			t.Errorf("parseInfoboxCondition(%q) succeeded", raw)
+		}
+	}
+	if _, err := parseInfoboxCondition("capital=Rome", "city"); err == nil {
+		t.Errorf("field outside the requested type was accepted")
+	}
+}
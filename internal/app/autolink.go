@@ -0,0 +1,302 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	nethtml "golang.org/x/net/html"
+)
+
+const (
+	// maxAutolinksPerArticle caps how many new links a single pass may add.
+	maxAutolinksPerArticle = 8
+	// minAutolinkTitleLen skips short titles that would match ordinary words.
+	minAutolinkTitleLen = 4
+)
+
+// autolinkTarget is an existing page whose title may be linked from prose.
+type autolinkTarget struct {
+	Slug  string
+	Title string
+}
+
+type autolinkCandidate struct {
+	slug  string
+	words []string
+	size  int
+}
+
+// autolinkIndex groups candidate titles by their first lower-cased word so a
+// text scan only compares titles that could start at the current word.
+type autolinkIndex map[string][]autolinkCandidate
+
+func newAutolinkIndex(targets []autolinkTarget) autolinkIndex {
+	index := make(autolinkIndex)
+	for _, target := range targets {
+		if key, candidate, ok := newAutolinkCandidate(target); ok {
+			index[key] = append(index[key], candidate)
+		}
+	}
+	for key, candidates := range index {
+		sort.Slice(candidates, func(i, j int) bool { return candidates[i].before(candidates[j]) })
+		index[key] = candidates
+	}
+	return index
+}
+
+// newAutolinkCandidate returns the index key and candidate for target, or
+// false when its title is too short to link.
+func newAutolinkCandidate(target autolinkTarget) (string, autolinkCandidate, bool) {
+	if target.Slug == "main_page" || len(target.Title) < minAutolinkTitleLen {
+		return "", autolinkCandidate{}, false
+	}
+	words := splitWords(target.Title)
+	if len(words) == 0 {
+		return "", autolinkCandidate{}, false
+	}
+	lower := make([]string, len(words))
+	size := 0
+	for i, w := range words {
+		lower[i] = strings.ToLower(w.text)
+		size += len(lower[i])
+	}
+	return lower[0], autolinkCandidate{slug: target.Slug, words: lower, size: size}, true
+}
+
+// before orders candidates longest title first, then by slug.
+func (a autolinkCandidate) before(b autolinkCandidate) bool {
+	if len(a.words) != len(b.words) {
+		return len(a.words) > len(b.words)
+	}
+	if a.size != b.size {
+		return a.size > b.size
+	}
+	return a.slug < b.slug
+}
+
+// add inserts target in order, so pages created after the index was built
+// can be linked without rebuilding it.
+func (index autolinkIndex) add(target autolinkTarget) {
+	key, candidate, ok := newAutolinkCandidate(target)
+	if !ok {
+		return
+	}
+	candidates := index[key]
+	i := sort.Search(len(candidates), func(i int) bool { return !candidates[i].before(candidate) })
+	index[key] = slices.Insert(candidates, i, candidate)
+}
+
+// autolinkMentions wraps the first plain-text mention of each target title in
+// a /wiki/ link. Text inside anchors, headings, code and scripts is left alone,
+// pages already linked from the content are skipped, and at most limit links
+// are added. At each word the longest matching title wins; ties fall back to
+// slug order so the result is deterministic.
+func autolinkMentions(content, slug string, index autolinkIndex, limit int) string {
+	if len(index) == 0 || limit <= 0 {
+		return content
+	}
+
+	linked := make(map[string]struct{})
+	linked[slug] = struct{}{}
+	for _, existing := range ExtractLinkedSlugs(content) {
+		linked[existing] = struct{}{}
+	}
+
+	var b strings.Builder
+	added := 0
+	skipDepth := 0
+	z := nethtml.NewTokenizer(strings.NewReader(content))
+	for {
+		tt := z.Next()
+		raw := string(z.Raw())
+		switch tt {
+		case nethtml.ErrorToken:
+			b.WriteString(raw)
+			return b.String()
+		case nethtml.TextToken:
+			if skipDepth == 0 && added < limit {
+				raw = linkTextMentions(raw, index, linked, limit, &added)
+			}
+		case nethtml.StartTagToken:
+			name, _ := z.TagName()
+			if _, ok := autolinkSkipTags[string(name)]; ok {
+				skipDepth++
+			}
+		case nethtml.EndTagToken:
+			name, _ := z.TagName()
+			if _, ok := autolinkSkipTags[string(name)]; ok && skipDepth > 0 {
+				skipDepth--
+			}
+		}
+		b.WriteString(raw)
+	}
+}
+
+var autolinkSkipTags = map[string]struct{}{
+	"a": {}, "h1": {}, "h2": {}, "h3": {}, "h4": {}, "h5": {}, "h6": {},
+	"code": {}, "pre": {}, "script": {}, "style": {}, "title": {},
+}
+
+type textWord struct {
+	start int
+	end   int
+	text  string
+}
+
+// splitWords returns the runs of letters and digits within s.
+func splitWords(s string) []textWord {
+	var words []textWord
+	start := -1
+	for i, r := range s {
+		isWord := unicode.IsLetter(r) || unicode.IsDigit(r)
+		switch {
+		case isWord && start == -1:
+			start = i
+		case !isWord && start != -1:
+			words = append(words, textWord{start: start, end: i, text: s[start:i]})
+			start = -1
+		}
+	}
+	if start != -1 {
+		words = append(words, textWord{start: start, end: len(s), text: s[start:]})
+	}
+	return words
+}
+
+func linkTextMentions(text string, index autolinkIndex, linked map[string]struct{}, limit int, added *int) string {
+	words := splitWords(text)
+	if len(words) == 0 {
+		return text
+	}
+	lower := make([]string, len(words))
+	for i, w := range words {
+		lower[i] = strings.ToLower(w.text)
+	}
+
+	var b strings.Builder
+	last := 0
+	for i := 0; i < len(words) && *added < limit; i++ {
+		match := matchCandidate(text, words, lower, i, index[lower[i]], linked)
+		if match == nil {
+			continue
+		}
+		start := words[i].start
+		end := words[i+len(match.words)-1].end
+		b.WriteString(text[last:start])
+		b.WriteString(`<a href="/wiki/`)
+		b.WriteString(url.PathEscape(match.slug))
+		b.WriteString(`">`)
+		b.WriteString(text[start:end])
+		b.WriteString(`</a>`)
+		last = end
+		linked[match.slug] = struct{}{}
+		*added++
+		i += len(match.words) - 1
+	}
+	if last == 0 {
+		return text
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+func matchCandidate(text string, words []textWord, lower []string, at int, candidates []autolinkCandidate, linked map[string]struct{}) *autolinkCandidate {
+	for idx := range candidates {
+		candidate := &candidates[idx]
+		if _, ok := linked[candidate.slug]; ok {
+			continue
+		}
+		if at+len(candidate.words) > len(words) {
+			continue
+		}
+		ok := true
+		for j, word := range candidate.words {
+			if lower[at+j] != word {
+				ok = false
+				break
+			}
+			if j > 0 && !isTitleSeparator(text[words[at+j-1].end:words[at+j].start]) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return candidate
+		}
+	}
+	return nil
+}
+
+// isTitleSeparator reports whether the text between two words keeps them part
+// of the same title, e.g. "Roman Empire" or "X-ray".
+func isTitleSeparator(gap string) bool {
+	if gap == "" || utf8.RuneCountInString(gap) > 3 {
+		return false
+	}
+	for _, r := range gap {
+		if !unicode.IsSpace(r) && r != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+func loadAutolinkTargets(ctx context.Context, db *sql.DB) ([]autolinkTarget, error) {
+	rows, err := db.QueryContext(ctx, `SELECT slug, title FROM pages`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var targets []autolinkTarget
+	for rows.Next() {
+		var target autolinkTarget
+		if err := rows.Scan(&target.Slug, &target.Title); err != nil {
+			return nil, err
+		}
+		target.Title = DisplayTitle(target.Slug, target.Title)
+		targets = append(targets, target)
+	}
+	return targets, rows.Err()
+}
+
+// AutolinkStats summarises a batch autolink run.
+type AutolinkStats struct {
+	Scanned int
+	Updated int
+}
+
+// AutolinkAll runs the mention linker over every stored page, rewriting pages
+// whose content changed. With dryRun set, nothing is written.
+func AutolinkAll(ctx context.Context, db *sql.DB, dryRun bool) (AutolinkStats, error) {
+	var stats AutolinkStats
+
+	targets, err := loadAutolinkTargets(ctx, db)
+	if err != nil {
+		return stats, err
+	}
+	index := newAutolinkIndex(targets)
+
+	scanned, err := eachPage(ctx, db, func(p Page) error {
+		if p.Slug == "main_page" {
+			return nil
+		}
+		linked := autolinkMentions(p.Content, p.Slug, index, maxAutolinksPerArticle)
+		if linked == p.Content {
+			return nil
+		}
+		stats.Updated++
+		if dryRun {
+			return nil
+		}
+		return updatePageContent(ctx, db, p.Slug, linked)
+	})
+	stats.Scanned = scanned
+	return stats, err
+}
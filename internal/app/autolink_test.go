@@ -0,0 +1,45 @@
+package app
+
+import "testing"
+
+func TestAutolinkMentionsLinksFirstOccurrenceOutsideAnchors(t *testing.T) {
+	index := newAutolinkIndex([]autolinkTarget{
+		{Slug: "roman_empire", Title: SlugTitle("roman_empire")},
+		{Slug: "rome", Title: SlugTitle("rome")},
+		{Slug: "carthage", Title: SlugTitle("carthage")},
+	})
+	content := `<h1>Punic Wars</h1><p>The <a href="/wiki/carthage">city</a> fought the Roman Empire. Carthage fell; the roman empire grew.</p><pre>Rome</pre><p>Rome endured.</p>`
+
+	got := autolinkMentions(content, "punic_wars", index, maxAutolinksPerArticle)
+	want := `<h1>Punic Wars</h1><p>The <a href="/wiki/carthage">city</a> fought the <a href="/wiki/roman_empire">Roman Empire</a>. Carthage fell; the roman empire grew.</p><pre>Rome</pre><p><a href="/wiki/rome">Rome</a> endured.</p>`
+	if got != want {
+		t.Fatalf("autolinkMentions:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestAutolinkMentionsPrefersLongestTitleAndRespectsLimit(t *testing.T) {
+	index := newAutolinkIndex([]autolinkTarget{
+		{Slug: "black_sea", Title: "Black Sea"},
+		{Slug: "black_sea_trade", Title: "Black Sea Trade"},
+		{Slug: "silk_road", Title: "Silk Road"},
+	})
+	content := `<p>Black Sea trade fed the Silk Road.</p>`
+
+	got := autolinkMentions(content, "commerce", index, 1)
+	want := `<p><a href="/wiki/black_sea_trade">Black Sea trade</a> fed the Silk Road.</p>`
+	if got != want {
+		t.Fatalf("autolinkMentions:\n got %s\nwant %s", got, want)
+	}
+}
+
+func TestAutolinkMentionsSkipsSelfAndShortTitles(t *testing.T) {
+	index := newAutolinkIndex([]autolinkTarget{
+		{Slug: "tea", Title: "Tea"},
+		{Slug: "green_tea", Title: "Green Tea"},
+	})
+	content := `<p>Green tea is a kind of tea.</p>`
+
+	if got := autolinkMentions(content, "green_tea", index, maxAutolinksPerArticle); got != content {
+		t.Fatalf("expected content unchanged, got %s", got)
+	}
+}
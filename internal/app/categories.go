@@ -0,0 +1,368 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	nethtml "golang.org/x/net/html"
+)
+
+const (
+	maxPageCategories = 4
+	// categoryExcerptLen bounds the article text sent when classifying pages
+	// stored before categories existed.
+	categoryExcerptLen = 1500
+)
+
+// stubCategories are attached to placeholder pages generated without Groq.
+var stubCategories = []string{"EndlessWiki > Placeholder articles"}
+
+// categoryLink is a category as shown in the category bar or on a category
+// page.
+type categoryLink struct {
+	Name  string
+	Title string
+}
+
+// categoryDef is one category named in a page's metadata together with the
+// broader category it was placed under, if any.
+type categoryDef struct {
+	Name   string
+	Title  string
+	Parent string
+}
+
+// parseCategories turns category strings such as "History > Roman history"
+// into the categories a page belongs to (the last element of each path) and
+// the definitions of every category mentioned, parents first.
+func parseCategories(raw []string) (members []string, defs []categoryDef) {
+	seenMember := make(map[string]struct{})
+	seenDef := make(map[string]struct{})
+	for _, entry := range raw {
+		parent := ""
+		for _, part := range strings.Split(entry, ">") {
+			title := strings.Join(strings.Fields(part), " ")
+			name, err := NormalizeSlug(title)
+			if err != nil || len([]rune(title)) > maxTitleLen {
+				continue
+			}
+			if name == parent {
+				continue
+			}
+			if _, ok := seenDef[name]; !ok {
+				seenDef[name] = struct{}{}
+				defs = append(defs, categoryDef{Name: name, Title: title, Parent: parent})
+			}
+			parent = name
+		}
+		if parent == "" {
+			continue
+		}
+		if _, ok := seenMember[parent]; !ok && len(members) < maxPageCategories {
+			seenMember[parent] = struct{}{}
+			members = append(members, parent)
+		}
+	}
+	return members, defs
+}
+
+// categoryStore is a database or transaction categories are written through.
+type categoryStore interface {
+	execer
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// replacePageCategories rewrites the page_categories rows for slug and makes
+// sure every category mentioned exists. A category keeps the first title and
+// parent it was given, and is never placed under one of its own descendants.
+func replacePageCategories(ctx context.Context, db categoryStore, slug string, raw []string) error {
+	if _, err := db.ExecContext(ctx, `DELETE FROM page_categories WHERE page_slug = ?`, slug); err != nil {
+		return err
+	}
+
+	members, defs := parseCategories(raw)
+	for _, def := range defs {
+		parent, err := categoryParent(ctx, db, def.Name, def.Parent)
+		if err != nil {
+			return err
+		}
+		const upsert = `INSERT INTO categories (name, title, parent) VALUES (?, ?, ?)
+			ON DUPLICATE KEY UPDATE parent = COALESCE(parent, VALUES(parent))`
+		if _, err := db.ExecContext(ctx, upsert, def.Name, def.Title, parent); err != nil {
+			return err
+		}
+	}
+	for _, name := range members {
+		const insert = `INSERT INTO page_categories (page_slug, category) VALUES (?, ?)`
+		if _, err := db.ExecContext(ctx, insert, slug, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// categoryParent returns parent as the value to store for name's parent, or
+// nil when there is none or when name is already above parent in the tree.
+func categoryParent(ctx context.Context, db categoryStore, name, parent string) (any, error) {
+	if parent == "" {
+		return nil, nil
+	}
+	seen := make(map[string]bool)
+	for ancestor := parent; ancestor != "" && !seen[ancestor]; {
+		if ancestor == name {
+			return nil, nil
+		}
+		seen[ancestor] = true
+		var next sql.NullString
+		err := db.QueryRowContext(ctx, `SELECT parent FROM categories WHERE name = ?`, ancestor).Scan(&next)
+		if errors.Is(err, sql.ErrNoRows) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		ancestor = next.String
+	}
+	return parent, nil
+}
+
+// pageCategories returns the categories slug belongs to in title order.
+func pageCategories(ctx context.Context, db *sql.DB, slug string) ([]categoryLink, error) {
+	const query = `SELECT c.name, c.title FROM page_categories pc
+		JOIN categories c ON c.name = pc.category
+		WHERE pc.page_slug = ?
+		ORDER BY c.title`
+	return scanCategoryLinks(ctx, db, query, slug)
+}
+
+func scanCategoryLinks(ctx context.Context, db *sql.DB, query string, args ...any) ([]categoryLink, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var links []categoryLink
+	for rows.Next() {
+		var link categoryLink
+		if err := rows.Scan(&link.Name, &link.Title); err != nil {
+			return nil, err
+		}
+		links = append(links, link)
+	}
+	return links, rows.Err()
+}
+
+// ClassifyPage asks the model for categories of an already stored page. It is
+// used to backfill pages generated before categories existed.
+func ClassifyPage(ctx context.Context, client *http.Client, cfg Config, page *Page) ([]string, error) {
+	if cfg.GroqAPIKey == "" {
+		return stubCategories, nil
+	}
+
+	messages := []groqMessage{
+		{
+			Role:    "system",
+			Content: cfg.setting() + ` Classify the article. Reply with only a JSON array of 1-4 short category names. Write "Parent > Child" to place a category under a broader one.`,
+		},
+		{
+			Role:    "user",
+			Content: fmt.Sprintf("Title: %s\n\n%s", page.DisplayTitle(), pageText(page.Content, categoryExcerptLen)),
+		},
+	}
+	reply, err := groqComplete(ctx, client, cfg, messages, 0.2, 120)
+	if err != nil {
+		return nil, err
+	}
+
+	var categories []string
+	if err := json.Unmarshal([]byte(stripHTMLCodeFence(reply)), &categories); err != nil {
+		return nil, fmt.Errorf("categories reply %q: %w", truncate(reply, 200), err)
+	}
+	return categories, nil
+}
+
+// pageText returns the visible text of content, truncated to roughly limit
+// bytes.
+func pageText(content string, limit int) string {
+	z := nethtml.NewTokenizer(strings.NewReader(content))
+	var b strings.Builder
+	for b.Len() < limit {
+		tt := z.Next()
+		if tt == nethtml.ErrorToken {
+			break
+		}
+		if tt == nethtml.TextToken {
+			if text := strings.Join(strings.Fields(string(z.Text())), " "); text != "" {
+				b.WriteString(text)
+				b.WriteByte(' ')
+			}
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// BackfillCategories classifies every page that has no categories yet and
+// returns the number of pages processed.
+func BackfillCategories(ctx context.Context, db *sql.DB, client *http.Client, cfg Config) (int, error) {
+	return eachPage(ctx, db, func(p Page) error {
+		if p.Slug == "main_page" {
+			return nil
+		}
+		var exists int
+		err := db.QueryRowContext(ctx, `SELECT 1 FROM page_categories WHERE page_slug = ? LIMIT 1`, p.Slug).Scan(&exists)
+		if err == nil || !errors.Is(err, sql.ErrNoRows) {
+			// Already classified, or the lookup failed.
+			return err
+		}
+
+		categories, err := ClassifyPage(ctx, client, cfg, &p)
+		if err != nil {
+			log.Printf("classify %s: %v", p.Slug, err)
+			return nil
+		}
+		return replacePageCategories(ctx, db, p.Slug, categories)
+	})
+}
+
+func (s *Server) handleCategory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	count, err := s.pageCount(ctx)
+	if err != nil {
+		log.Printf("page count: %v", err)
+		count = 0
+	}
+
+	data := struct {
+		Category      *categoryLink
+		Parent        *categoryLink
+		Subcategories []categoryLink
+		Members       []pageLink
+		Offset        int
+		Limit         int
+		PrevOffset    int
+		NextOffset    int
+		HasPrev       bool
+		HasNext       bool
+		PageCount     int
+		SearchQuery   string
+	}{
+		PageCount: count,
+	}
+
+	raw := strings.Trim(strings.TrimPrefix(r.URL.Path, "/category"), "/")
+	if raw == "" {
+		data.Subcategories, err = scanCategoryLinks(ctx, s.db, `SELECT name, title FROM categories WHERE parent IS NULL ORDER BY title`)
+		if err != nil {
+			log.Printf("top-level categories: %v", err)
+			http.Error(w, "failed to load categories", http.StatusInternalServerError)
+			return
+		}
+		s.renderCategory(w, data)
+		return
+	}
+
+	name, err := NormalizeSlug(raw)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if name != raw {
+		http.Redirect(w, r, s.path("/category/"+url.PathEscape(name)), http.StatusMovedPermanently)
+		return
+	}
+
+	var category categoryLink
+	var parent sql.NullString
+	err = s.db.QueryRowContext(ctx, `SELECT name, title, parent FROM categories WHERE name = ?`, name).Scan(&category.Name, &category.Title, &parent)
+	if errors.Is(err, sql.ErrNoRows) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		log.Printf("lookup category %s: %v", name, err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	data.Category = &category
+
+	if parent.Valid {
+		parents, err := scanCategoryLinks(ctx, s.db, `SELECT name, title FROM categories WHERE name = ?`, parent.String)
+		if err != nil {
+			log.Printf("parent of category %s: %v", name, err)
+		} else if len(parents) > 0 {
+			data.Parent = &parents[0]
+		}
+	}
+
+	data.Subcategories, err = scanCategoryLinks(ctx, s.db, `SELECT name, title FROM categories WHERE parent = ? ORDER BY title`, name)
+	if err != nil {
+		log.Printf("subcategories of %s: %v", name, err)
+		http.Error(w, "failed to load category", http.StatusInternalServerError)
+		return
+	}
+
+	limit, offset := pagination(r)
+	members, err := categoryMembers(ctx, s.db, name, limit+1, offset)
+	if err != nil {
+		log.Printf("members of category %s: %v", name, err)
+		http.Error(w, "failed to load category", http.StatusInternalServerError)
+		return
+	}
+	data.Limit, data.Offset = limit, offset
+	if len(members) > limit {
+		members = members[:limit]
+		data.HasNext = true
+		data.NextOffset = offset + limit
+	}
+	data.Members = members
+	if offset > 0 {
+		data.HasPrev = true
+		data.PrevOffset = max(0, offset-limit)
+	}
+
+	s.renderCategory(w, data)
+}
+
+func (s *Server) renderCategory(w http.ResponseWriter, data any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.templates.ExecuteTemplate(w, "category.gohtml", data); err != nil {
+		log.Printf("render category: %v", err)
+	}
+}
+
+func categoryMembers(ctx context.Context, db *sql.DB, name string, limit, offset int) ([]pageLink, error) {
+	const query = `SELECT p.slug, p.title FROM page_categories pc
+		JOIN pages p ON p.slug = pc.page_slug
+		WHERE pc.category = ?
+		ORDER BY p.slug
+		LIMIT ? OFFSET ?`
+	rows, err := db.QueryContext(ctx, query, name, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []pageLink
+	for rows.Next() {
+		var member pageLink
+		if err := rows.Scan(&member.Slug, &member.Title); err != nil {
+			return nil, err
+		}
+		member.Title = DisplayTitle(member.Slug, member.Title)
+		members = append(members, member)
+	}
+	return members, rows.Err()
+}
@@ -0,0 +1,59 @@
+package app
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCategories(t *testing.T) {
+	members, defs := parseCategories([]string{
+		"History > Ancient Rome",
+		"Cities",
+		"history",
+		"  ",
+		"Architecture", "Engineering", "Law",
+	})
+	if want := []string{"ancient_rome", "cities", "history", "architecture"}; !reflect.DeepEqual(members, want) {
+		t.Fatalf("members = %v, want %v", members, want)
+	}
+	wantDefs := []categoryDef{
+		{Name: "history", Title: "History"},
+		{Name: "ancient_rome", Title: "Ancient Rome", Parent: "history"},
+		{Name: "cities", Title: "Cities"},
+		{Name: "architecture", Title: "Architecture"},
+		{Name: "engineering", Title: "Engineering"},
+		{Name: "law", Title: "Law"},
+	}
+	if !reflect.DeepEqual(defs, wantDefs) {
+		t.Fatalf("defs = %+v, want %+v", defs, wantDefs)
+	}
+}
+
+func TestStubPageCarriesCategories(t *testing.T) {
+	_, meta := splitMetaTrailer(stubPage("test_topic"))
+	if !reflect.DeepEqual(meta.Categories, stubCategories) {
+		t.Fatalf("stub categories = %v", meta.Categories)
+	}
+}
+
+func TestWikiTemplateRendersCategoryBar(t *testing.T) {
+	srv, err := NewServer(nil, Config{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	data := map[string]any{
+		"Title":      "Rome",
+		"Slug":       "rome",
+		"Content":    "",
+		"Categories": []categoryLink{{Name: "ancient_rome", Title: "Ancient Rome"}},
+	}
+
+	var b strings.Builder
+	if err := srv.templates.ExecuteTemplate(&b, "wiki.gohtml", data); err != nil {
+		t.Fatalf("render wiki: %v", err)
+	}
+	if !strings.Contains(b.String(), `<a href="/category/ancient_rome">Ancient Rome</a>`) {
+		t.Fatalf("category bar missing: %s", b.String())
+	}
+}
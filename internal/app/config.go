@@ -9,10 +9,43 @@ import (
 )
 
 // Config contains runtime configuration derived from environment variables.
+// Named worlds (see LoadWorlds) get a copy with their own DSN, prompts, and
+// limits.
 type Config struct {
 	DSN        string
 	Port       string
 	GroqAPIKey string
+	// AdminToken, when set, lets requests bearing it skip reader rate limits.
+	AdminToken string
+	// WorldsFile is the path of the named worlds configuration, if any.
+	WorldsFile string
+
+	// World is the name of a named world, or "" for the default wiki.
+	World string
+	// Hosts are the hostnames that serve the world at the root. A world
+	// without hosts is served below BasePath.
+	Hosts []string
+	// BasePath is the path prefix the wiki is served under, e.g. "/w/scifi";
+	// "" means the root.
+	BasePath string
+	// Model overrides the Groq model used for generation.
+	Model string
+	// SystemPrompt describes the setting; it replaces the default
+	// "fictional encyclopedia" framing of every generation prompt.
+	SystemPrompt string
+	// MainPageHTML replaces the built-in main page.
+	MainPageHTML string
+	// GenLimit is the number of generations allowed per reader per hour; 0
+	// means the default.
+	GenLimit int
+
+	// Lang is the language the wiki is written in; "" means English.
+	Lang string
+	// Editions are the wiki's other language editions, each with its own
+	// database, served under /{lang}/.
+	Editions []Config
+	// Edition marks the configuration of a language edition.
+	Edition bool
 }
 
 // LoadConfig populates Config from environment variables, applying reasonable defaults.
@@ -20,6 +53,15 @@ func LoadConfig() (Config, error) {
 	cfg := Config{
 		Port:       defaultEnv("PORT", "8080"),
 		GroqAPIKey: os.Getenv("GROQ_API_KEY"),
+		AdminToken: os.Getenv("ADMIN_TOKEN"),
+		WorldsFile: os.Getenv("WORLDS_FILE"),
+	}
+	if lang := os.Getenv("WIKI_LANG"); lang != "" {
+		normalized, err := parseEditionLang(lang)
+		if err != nil {
+			return cfg, fmt.Errorf("WIKI_LANG: %w", err)
+		}
+		cfg.Lang = normalized
 	}
 
 	rawDSN := os.Getenv("MYSQL_DSN")
@@ -36,6 +78,10 @@ func LoadConfig() (Config, error) {
 	}
 	cfg.DSN = normalized
 
+	if cfg.Editions, err = parseEditions(os.Getenv("EDITIONS"), cfg); err != nil {
+		return cfg, fmt.Errorf("EDITIONS: %w", err)
+	}
+
 	return cfg, nil
 }
 
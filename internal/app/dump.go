@@ -0,0 +1,413 @@
+package app
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// Dumps are JSON Lines: a header record, then the categories, the redirects,
+// and one self-contained record per page. Nothing in them is specific to
+// MySQL; timestamps are RFC 3339 in UTC and derived data (page_links) is
+// informative only, so any store can load a dump.
+const (
+	dumpFormat  = "endlesswiki-dump"
+	dumpVersion = 1
+)
+
+// queryer is satisfied by both *sql.DB and *sql.Tx.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+}
+
+type dumpHeader struct {
+	Type      string    `json:"type"`
+	Format    string    `json:"format"`
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+	World     string    `json:"world,omitempty"`
+	Lang      string    `json:"lang,omitempty"`
+}
+
+type dumpCategory struct {
+	Type   string `json:"type"`
+	Name   string `json:"name"`
+	Title  string `json:"title"`
+	Parent string `json:"parent,omitempty"`
+}
+
+type dumpRedirect struct {
+	Type      string    `json:"type"`
+	Alias     string    `json:"alias"`
+	Target    string    `json:"target"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// dumpPage is a page with everything stored about it.
+type dumpPage struct {
+	Type      string     `json:"type"`
+	Slug      string     `json:"slug"`
+	Title     string     `json:"title,omitempty"`
+	Content   string     `json:"content"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	// Links are the slugs the content links to. Restore derives them from
+	// the content again.
+	Links      []string          `json:"links,omitempty"`
+	Categories []string          `json:"categories,omitempty"`
+	Infobox    *dumpInfobox      `json:"infobox,omitempty"`
+	Facts      []dumpFact        `json:"facts,omitempty"`
+	Revisions  []dumpRevision    `json:"revisions,omitempty"`
+	LangLinks  map[string]string `json:"langlinks,omitempty"`
+}
+
+type dumpInfobox struct {
+	Type  string            `json:"type"`
+	Facts []dumpInfoboxFact `json:"facts"`
+}
+
+type dumpInfoboxFact struct {
+	Field  string   `json:"field"`
+	Value  string   `json:"value"`
+	Number *float64 `json:"number,omitempty"`
+	Slug   string   `json:"slug,omitempty"`
+}
+
+type dumpFact struct {
+	Subject   string `json:"subject"`
+	Attribute string `json:"attribute"`
+	Value     string `json:"value"`
+	Key       string `json:"key"`
+}
+
+type dumpRevision struct {
+	Content   string    `json:"content"`
+	Summary   string    `json:"summary,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// modified is when the page last changed.
+func (p *dumpPage) modified() time.Time {
+	if p.UpdatedAt != nil && p.UpdatedAt.After(p.CreatedAt) {
+		return *p.UpdatedAt
+	}
+	return p.CreatedAt
+}
+
+// DateFilter selects records by creation time. Zero bounds are open.
+type DateFilter struct {
+	// Since keeps records created at or after it.
+	Since time.Time
+	// Until keeps records created before it.
+	Until time.Time
+}
+
+func (f DateFilter) match(t time.Time) bool {
+	return (f.Since.IsZero() || !t.Before(f.Since)) && (f.Until.IsZero() || t.Before(f.Until))
+}
+
+// ParseDumpTime parses a -since or -until flag: an RFC 3339 time or a
+// YYYY-MM-DD date, taken as midnight UTC.
+func ParseDumpTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.UTC(), nil
+	}
+	t, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither a date (2006-01-02) nor an RFC 3339 time", value)
+	}
+	return t, nil
+}
+
+// DumpOptions configures DumpWiki.
+type DumpOptions struct {
+	Filter DateFilter
+	Gzip   bool
+}
+
+// DumpStats reports what DumpWiki wrote.
+type DumpStats struct {
+	Pages      int
+	Redirects  int
+	Categories int
+}
+
+// DumpWiki writes the wiki stored in db to w as JSON Lines, from a single
+// consistent snapshot. The filter applies to pages and redirects; all
+// categories are written.
+func DumpWiki(ctx context.Context, db *sql.DB, cfg Config, w io.Writer, opts DumpOptions) (DumpStats, error) {
+	var stats DumpStats
+	tx, err := db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return stats, err
+	}
+	defer tx.Rollback()
+
+	var out io.Writer = w
+	var zw *gzip.Writer
+	if opts.Gzip {
+		zw = gzip.NewWriter(w)
+		out = zw
+	}
+	bw := bufio.NewWriter(out)
+	enc := json.NewEncoder(bw)
+	enc.SetEscapeHTML(false)
+
+	header := dumpHeader{
+		Type:      "header",
+		Format:    dumpFormat,
+		Version:   dumpVersion,
+		CreatedAt: time.Now().UTC().Truncate(time.Second),
+		World:     cfg.World,
+		Lang:      cfg.Lang,
+	}
+	if err := enc.Encode(header); err != nil {
+		return stats, err
+	}
+	if stats.Categories, err = dumpCategories(ctx, tx, enc); err != nil {
+		return stats, fmt.Errorf("dump categories: %w", err)
+	}
+	if stats.Redirects, err = dumpRedirects(ctx, tx, enc, opts.Filter); err != nil {
+		return stats, fmt.Errorf("dump redirects: %w", err)
+	}
+	if stats.Pages, err = dumpPages(ctx, tx, enc, opts.Filter); err != nil {
+		return stats, fmt.Errorf("dump pages: %w", err)
+	}
+
+	if err := bw.Flush(); err != nil {
+		return stats, err
+	}
+	if zw != nil {
+		if err := zw.Close(); err != nil {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+func dumpCategories(ctx context.Context, db queryer, enc *json.Encoder) (int, error) {
+	rows, err := db.QueryContext(ctx, `SELECT name, title, COALESCE(parent, '') FROM categories ORDER BY name`)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		c := dumpCategory{Type: "category"}
+		if err := rows.Scan(&c.Name, &c.Title, &c.Parent); err != nil {
+			return n, err
+		}
+		if err := enc.Encode(c); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, rows.Err()
+}
+
+func dumpRedirects(ctx context.Context, db queryer, enc *json.Encoder, filter DateFilter) (int, error) {
+	rows, err := db.QueryContext(ctx, `SELECT alias_slug, target_slug, created_at FROM redirects ORDER BY alias_slug`)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	n := 0
+	for rows.Next() {
+		r := dumpRedirect{Type: "redirect"}
+		if err := rows.Scan(&r.Alias, &r.Target, &r.CreatedAt); err != nil {
+			return n, err
+		}
+		if !filter.match(r.CreatedAt) {
+			continue
+		}
+		r.CreatedAt = r.CreatedAt.UTC()
+		if err := enc.Encode(r); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, rows.Err()
+}
+
+// dumpPages writes the pages in slug order, pageBatchSize at a time.
+func dumpPages(ctx context.Context, db queryer, enc *json.Encoder, filter DateFilter) (int, error) {
+	n := 0
+	after := ""
+	for {
+		pages, err := loadDumpPages(ctx, db, after, filter)
+		if err != nil {
+			return n, err
+		}
+		if len(pages) == 0 {
+			return n, nil
+		}
+		if err := loadDumpDetails(ctx, db, pages); err != nil {
+			return n, err
+		}
+		for _, page := range pages {
+			if err := enc.Encode(page); err != nil {
+				return n, err
+			}
+			n++
+		}
+		after = pages[len(pages)-1].Slug
+	}
+}
+
+func loadDumpPages(ctx context.Context, db queryer, after string, filter DateFilter) ([]*dumpPage, error) {
+	query := `SELECT slug, title, content, created_at, updated_at FROM pages WHERE slug > ?`
+	args := []any{after}
+	if !filter.Since.IsZero() {
+		query += ` AND created_at >= ?`
+		args = append(args, filter.Since)
+	}
+	if !filter.Until.IsZero() {
+		query += ` AND created_at < ?`
+		args = append(args, filter.Until)
+	}
+	query += ` ORDER BY slug LIMIT ?`
+	args = append(args, pageBatchSize)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pages []*dumpPage
+	for rows.Next() {
+		p := &dumpPage{Type: "page"}
+		var updated sql.NullTime
+		if err := rows.Scan(&p.Slug, &p.Title, &p.Content, &p.CreatedAt, &updated); err != nil {
+			return nil, err
+		}
+		p.CreatedAt = p.CreatedAt.UTC()
+		if updated.Valid {
+			t := updated.Time.UTC()
+			p.UpdatedAt = &t
+		}
+		p.Links = ExtractLinkedSlugs(p.Content)
+		pages = append(pages, p)
+	}
+	return pages, rows.Err()
+}
+
+// loadDumpDetails fills in the categories, infoboxes, facts, revisions, and
+// language links of a batch of pages.
+func loadDumpDetails(ctx context.Context, db queryer, pages []*dumpPage) error {
+	bySlug := make(map[string]*dumpPage, len(pages))
+	args := make([]any, len(pages))
+	for i, p := range pages {
+		bySlug[p.Slug] = p
+		args[i] = p.Slug
+	}
+	in := "(" + strings.TrimSuffix(strings.Repeat("?,", len(pages)), ",") + ")"
+
+	queries := []struct {
+		query string
+		scan  func(rows *sql.Rows) error
+	}{
+		{`SELECT page_slug, category FROM page_categories WHERE page_slug IN ` + in + ` ORDER BY page_slug, category`,
+			func(rows *sql.Rows) error {
+				var slug, category string
+				if err := rows.Scan(&slug, &category); err != nil {
+					return err
+				}
+				bySlug[slug].Categories = append(bySlug[slug].Categories, category)
+				return nil
+			}},
+		{`SELECT page_slug, type FROM infoboxes WHERE page_slug IN ` + in,
+			func(rows *sql.Rows) error {
+				var slug, kind string
+				if err := rows.Scan(&slug, &kind); err != nil {
+					return err
+				}
+				bySlug[slug].Infobox = &dumpInfobox{Type: kind, Facts: []dumpInfoboxFact{}}
+				return nil
+			}},
+		{`SELECT page_slug, field, value_text, value_number, COALESCE(value_slug, '') FROM infobox_facts
+			WHERE page_slug IN ` + in + ` ORDER BY page_slug, position`,
+			func(rows *sql.Rows) error {
+				var slug string
+				var fact dumpInfoboxFact
+				var number sql.NullFloat64
+				if err := rows.Scan(&slug, &fact.Field, &fact.Value, &number, &fact.Slug); err != nil {
+					return err
+				}
+				if number.Valid {
+					fact.Number = &number.Float64
+				}
+				if box := bySlug[slug].Infobox; box != nil {
+					box.Facts = append(box.Facts, fact)
+				}
+				return nil
+			}},
+		{`SELECT page_slug, subject_slug, attribute, value, value_key FROM page_facts
+			WHERE page_slug IN ` + in + ` ORDER BY page_slug, id`,
+			func(rows *sql.Rows) error {
+				var slug string
+				var fact dumpFact
+				if err := rows.Scan(&slug, &fact.Subject, &fact.Attribute, &fact.Value, &fact.Key); err != nil {
+					return err
+				}
+				bySlug[slug].Facts = append(bySlug[slug].Facts, fact)
+				return nil
+			}},
+		{`SELECT page_slug, content, summary, created_at FROM page_revisions
+			WHERE page_slug IN ` + in + ` ORDER BY page_slug, id`,
+			func(rows *sql.Rows) error {
+				var slug string
+				var rev dumpRevision
+				if err := rows.Scan(&slug, &rev.Content, &rev.Summary, &rev.CreatedAt); err != nil {
+					return err
+				}
+				rev.CreatedAt = rev.CreatedAt.UTC()
+				bySlug[slug].Revisions = append(bySlug[slug].Revisions, rev)
+				return nil
+			}},
+		{`SELECT page_slug, lang, target_slug FROM langlinks WHERE page_slug IN ` + in,
+			func(rows *sql.Rows) error {
+				var slug, lang, target string
+				if err := rows.Scan(&slug, &lang, &target); err != nil {
+					return err
+				}
+				p := bySlug[slug]
+				if p.LangLinks == nil {
+					p.LangLinks = make(map[string]string)
+				}
+				p.LangLinks[lang] = target
+				return nil
+			}},
+	}
+	for _, q := range queries {
+		if err := scanRows(ctx, db, q.query, args, q.scan); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func scanRows(ctx context.Context, db queryer, query string, args []any, scan func(*sql.Rows) error) error {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
@@ -0,0 +1,218 @@
+package app
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseDumpTime(t *testing.T) {
+	got, err := ParseDumpTime("2024-03-01")
+	if err != nil || !got.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("date = %v, %v", got, err)
+	}
+	got, err = ParseDumpTime("2024-03-01T12:00:00+02:00")
+	if err != nil || !got.Equal(time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("time = %v, %v", got, err)
+	}
+	if got, err := ParseDumpTime(""); err != nil || !got.IsZero() {
+		t.Errorf("empty = %v, %v", got, err)
+	}
+	if _, err := ParseDumpTime("March 2024"); err == nil {
+		t.Error("accepted March 2024")
+	}
+}
+
+func TestDateFilter(t *testing.T) {
+	march := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	april := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	filter := DateFilter{Since: march, Until: april}
+	for _, tc := range []struct {
+		at   time.Time
+		want bool
+	}{
+		{march.Add(-time.Second), false},
+		{march, true},
+		{april.Add(-time.Second), true},
+		{april, false},
+	} {
+		if got := filter.match(tc.at); got != tc.want {
+			t.Errorf("match(%v) = %v", tc.at, got)
+		}
+	}
+	if !(DateFilter{}).match(march) {
+		t.Error("the zero filter rejected a record")
+	}
+}
+
+func TestConflictPolicy(t *testing.T) {
+	old, recent := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	for _, tc := range []struct {
+		policy           string
+		overOld, overNew bool
+	}{
+		{"skip", false, false},
+		{"overwrite", true, true},
+		{"keep-newer", true, false},
+	} {
+		policy, err := ParseConflictPolicy(tc.policy)
+		if err != nil {
+			t.Fatalf("ParseConflictPolicy(%q): %v", tc.policy, err)
+		}
+		if got := policy.replaces(old, recent); got != tc.overOld {
+			t.Errorf("%s replaces an older record = %v", tc.policy, got)
+		}
+		if got := policy.replaces(recent, old); got != tc.overNew {
+			t.Errorf("%s replaces a newer record = %v", tc.policy, got)
+		}
+	}
+	if _, err := ParseConflictPolicy("merge"); err == nil {
+		t.Error("accepted merge")
+	}
+}
+
+func TestDumpPageModified(t *testing.T) {
+	created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	page := dumpPage{CreatedAt: created}
+	if !page.modified().Equal(created) {
+		t.Errorf("modified = %v", page.modified())
+	}
+	updated := created.Add(time.Hour)
+	page.UpdatedAt = &updated
+	if !page.modified().Equal(updated) {
+		t.Errorf("modified = %v", page.modified())
+	}
+}
+
+func TestDumpPageJSON(t *testing.T) {
+	population := 12.5
+	page := dumpPage{
+		Type:       "page",
+		Slug:       "rome",
+		Content:    "<h1>Rome</h1><p>See <a href=\"/wiki/tiber\">the Tiber</a>.</p>",
+		CreatedAt:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Categories: []string{"cities"},
+		Infobox:    &dumpInfobox{Type: "place", Facts: []dumpInfoboxFact{{Field: "population", Value: "12.5 million", Number: &population}}},
+		LangLinks:  map[string]string{"fr": "rome"},
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(page); err != nil {
+		t.Fatal(err)
+	}
+	line := buf.String()
+	for _, want := range []string{`"created_at":"2024-01-01T00:00:00Z"`, `<a href=\"/wiki/tiber\">`, `"number":12.5`} {
+		if !strings.Contains(line, want) {
+			t.Errorf("encoded page missing %s:\n%s", want, line)
+		}
+	}
+	if strings.Contains(line, "updated_at") || strings.Contains(line, "revisions") {
+		t.Errorf("encoded page has empty fields:\n%s", line)
+	}
+
+	var decoded dumpPage
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Content != page.Content || *decoded.Infobox.Facts[0].Number != population || decoded.LangLinks["fr"] != "rome" {
+		t.Errorf("decoded = %+v", decoded)
+	}
+}
+
+func testDump(t *testing.T, created time.Time, records ...string) []byte {
+	t.Helper()
+	header, err := json.Marshal(dumpHeader{Type: "header", Format: dumpFormat, Version: dumpVersion, CreatedAt: created})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []byte(string(header) + "\n" + strings.Join(records, "\n"))
+}
+
+func TestOpenDumpReaderDetectsGzip(t *testing.T) {
+	plain := testDump(t, time.Now().UTC())
+	var zipped bytes.Buffer
+	zw := gzip.NewWriter(&zipped)
+	zw.Write(plain)
+	zw.Close()
+
+	for name, in := range map[string][]byte{"plain": plain, "gzip": zipped.Bytes()} {
+		r, err := openDumpReader(bytes.NewReader(in))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		got, err := io.ReadAll(r)
+		if err != nil || !bytes.Equal(got, plain) {
+			t.Errorf("%s: read %q, %v", name, got, err)
+		}
+	}
+}
+
+func TestRestoreRejectsBadDumps(t *testing.T) {
+	for name, in := range map[string]string{
+		"empty":        "",
+		"not a dump":   `{"type":"page","slug":"rome"}`,
+		"other format": `{"type":"header","format":"mediawiki","version":1}`,
+		"too new":      `{"type":"header","format":"endlesswiki-dump","version":99}`,
+	} {
+		if _, err := RestoreWiki(context.Background(), nil, strings.NewReader(in), RestoreOptions{}); err == nil {
+			t.Errorf("%s: restore succeeded", name)
+		}
+	}
+
+	dump := testDump(t, time.Now().UTC(), `{"type":"template","name":"x"}`)
+	if _, err := RestoreWiki(context.Background(), nil, bytes.NewReader(dump), RestoreOptions{}); err == nil || !strings.Contains(err.Error(), "template") {
+		t.Errorf("unknown record type: %v", err)
+	}
+}
+
+func TestRestoreSkipsInvalidRecords(t *testing.T) {
+	dump := testDump(t, time.Now().UTC(),
+		`{"type":"page","slug":"Not Normal","content":"","created_at":"2024-01-01T00:00:00Z"}`,
+		`{"type":"redirect","alias":"a/b","target":"rome","created_at":"2024-01-01T00:00:00Z"}`,
+		`{"type":"page","slug":42}`)
+	stats, err := RestoreWiki(context.Background(), nil, bytes.NewReader(dump), RestoreOptions{})
+	if err != nil {
+		t.Fatalf("RestoreWiki: %v", err)
+	}
+	if stats.Invalid != 3 || stats.Pages != 0 || stats.Redirects != 0 {
+		t.Errorf("stats = %+v, want 3 invalid records", stats)
+	}
+}
+
+func TestRestoreResumesFromCheckpoint(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	dump := testDump(t, created, `{"type":"category","name":"cities","title":"Cities"}`, `{"type":"page","slug":"rome","content":"","created_at":"2024-01-01T00:00:00Z"}`)
+	checkpoint := filepath.Join(t.TempDir(), "restore.checkpoint")
+
+	// A checkpoint for another dump is an error rather than a silent skip.
+	if err := writeCheckpoint(checkpoint, restoreCheckpoint{DumpCreatedAt: created.Add(time.Hour), Records: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := RestoreWiki(context.Background(), nil, bytes.NewReader(dump), RestoreOptions{Checkpoint: checkpoint}); err == nil {
+		t.Error("restore accepted another dump's checkpoint")
+	}
+
+	// Every record was applied before the interruption, so nothing touches
+	// the database.
+	if err := writeCheckpoint(checkpoint, restoreCheckpoint{DumpCreatedAt: created, Records: 2}); err != nil {
+		t.Fatal(err)
+	}
+	stats, err := RestoreWiki(context.Background(), nil, bytes.NewReader(dump), RestoreOptions{Checkpoint: checkpoint})
+	if err != nil {
+		t.Fatalf("RestoreWiki: %v", err)
+	}
+	if stats.Resumed != 2 || stats.Pages != 0 {
+		t.Errorf("stats = %+v", stats)
+	}
+	if _, err := os.Stat(checkpoint); !os.IsNotExist(err) {
+		t.Errorf("checkpoint left behind: %v", err)
+	}
+}
@@ -0,0 +1,253 @@
+package app
+
+import (
+	"context"
+	"iter"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// duplicateMinEditLen is the shortest comparison key checked by edit
+	// distance; below it unrelated words ("war", "car") collide too easily.
+	duplicateMinEditLen = 6
+	// duplicateTypoMinWordLen is the shortest word in which a single edit is
+	// treated as a typo of an existing page rather than a borderline case.
+	duplicateTypoMinWordLen = 5
+	duplicateMaxDistance    = 2
+)
+
+var leadingArticles = []string{"the", "a", "an"}
+
+// duplicateMatch is the existing slug closest to a requested one. Reason says
+// why a borderline match was made.
+type duplicateMatch struct {
+	Slug      string
+	Distance  int
+	Confident bool
+	Reason    string
+}
+
+// duplicateKey is the form two slugs for the same title share: the words in
+// order, whatever separates them.
+func duplicateKey(slug string) string {
+	return strings.Join(slugWords(slug), "_")
+}
+
+// articleKey drops a leading article from duplicateKey and reports how many
+// words are left.
+func articleKey(slug string) (string, int) {
+	words := stripArticle(slugWords(slug))
+	return strings.Join(words, "_"), len(words)
+}
+
+// looseDuplicateKey also drops a leading article and singularises every word.
+func looseDuplicateKey(slug string) string {
+	words := stripArticle(slugWords(slug))
+	for i, word := range words {
+		words[i] = singularize(word)
+	}
+	return strings.Join(words, "_")
+}
+
+// sortedDuplicateKey is looseDuplicateKey with its words sorted, shared by
+// titles that differ only in word order.
+func sortedDuplicateKey(slug string) string {
+	words := strings.Split(looseDuplicateKey(slug), "_")
+	slices.Sort(words)
+	return strings.Join(words, "_")
+}
+
+// stripArticle drops the leading article of a title of several words.
+func stripArticle(words []string) []string {
+	if len(words) > 1 && slices.Contains(leadingArticles, words[0]) {
+		return words[1:]
+	}
+	return words
+}
+
+func slugWords(slug string) []string {
+	return strings.FieldsFunc(slug, func(r rune) bool { return r == '_' || r == '-' })
+}
+
+// pluralWords end like plurals but are not.
+var pluralWords = []string{"news", "series", "species", "means", "physics", "mathematics", "politics", "economics", "ethics"}
+
+// singularize strips common English plural endings. It only needs to map both
+// forms of a word to the same string, not to produce real English.
+func singularize(word string) string {
+	switch {
+	case slices.Contains(pluralWords, word):
+		return word
+	case len(word) > 4 && strings.HasSuffix(word, "ies"):
+		return word[:len(word)-3] + "y"
+	case strings.HasSuffix(word, "sses"),
+		strings.HasSuffix(word, "ches"),
+		strings.HasSuffix(word, "shes"),
+		strings.HasSuffix(word, "xes"),
+		strings.HasSuffix(word, "zes"):
+		return word[:len(word)-2]
+	case len(word) > 3 && strings.HasSuffix(word, "s") &&
+		!strings.HasSuffix(word, "ss") &&
+		!strings.HasSuffix(word, "us") &&
+		!strings.HasSuffix(word, "is"):
+		return word[:len(word)-1]
+	}
+	return word
+}
+
+// findDuplicate returns the existing slug closest to slug. Matching keys are
+// confident, as is a dropped article before a title of several words
+// ("the_roman_empire") and a single edit inside one long word
+// ("constantinple"). A dropped article before a single word ("the_who"), a
+// plural ("roman_empires"), transposed words ("empire_roman"), and anything
+// else within duplicateMaxDistance are borderline. Short words and numbers
+// are never merged by edit distance since "world_war_i" and "battle_of_1806"
+// name different topics than their neighbours.
+func findDuplicate(slug string, existing iter.Seq[string]) (duplicateMatch, bool) {
+	key := duplicateKey(slug)
+	keyRunes := []rune(key)
+	article, articleWords := articleKey(slug)
+	loose := looseDuplicateKey(slug)
+	sorted := sortedDuplicateKey(slug)
+	var best duplicateMatch
+	for candidate := range existing {
+		if candidate == slug {
+			continue
+		}
+		other := duplicateKey(candidate)
+		match := duplicateMatch{Slug: candidate}
+		switch otherArticle, _ := articleKey(candidate); {
+		case other == key:
+			match.Confident = true
+		case otherArticle == article:
+			match.Confident = articleWords > 1
+			match.Reason = "leading article"
+		case looseDuplicateKey(candidate) == loose:
+			match.Reason = "plural"
+		case sortedDuplicateKey(candidate) == sorted:
+			match.Reason = "word order"
+		default:
+			otherRunes := []rune(other)
+			if len(keyRunes) < duplicateMinEditLen || len(otherRunes) < duplicateMinEditLen {
+				continue
+			}
+			match.Distance = levenshtein(keyRunes, otherRunes, duplicateMaxDistance)
+			if match.Distance > duplicateMaxDistance {
+				continue
+			}
+			match.Confident = match.Distance == 1 && isWordTypo(key, other)
+			match.Reason = "edit distance " + strconv.Itoa(match.Distance)
+		}
+		if best.Slug == "" || match.closerThan(best) {
+			best = match
+		}
+	}
+	return best, best.Slug != ""
+}
+
+// closerThan ranks confident matches first, then by distance and slug length.
+func (m duplicateMatch) closerThan(other duplicateMatch) bool {
+	if m.Confident != other.Confident {
+		return m.Confident
+	}
+	if m.Distance != other.Distance {
+		return m.Distance < other.Distance
+	}
+	return shorterSlug(m.Slug, other.Slug)
+}
+
+// isWordTypo reports whether two keys differ in exactly one word and that word
+// is long enough, free of digits, and not merely a plural, for the difference
+// to be a misspelling.
+func isWordTypo(a, b string) bool {
+	wordsA, wordsB := strings.Split(a, "_"), strings.Split(b, "_")
+	if len(wordsA) != len(wordsB) {
+		return false
+	}
+	differing := -1
+	for i := range wordsA {
+		if wordsA[i] != wordsB[i] {
+			if differing >= 0 {
+				return false
+			}
+			differing = i
+		}
+	}
+	if differing < 0 || singularize(wordsA[differing]) == singularize(wordsB[differing]) {
+		return false
+	}
+	for _, word := range []string{wordsA[differing], wordsB[differing]} {
+		if utf8.RuneCountInString(word) < duplicateTypoMinWordLen || containsDigit(word) {
+			return false
+		}
+	}
+	return true
+}
+
+// shorterSlug orders ties by length, then alphabetically.
+func shorterSlug(a, b string) bool {
+	if b == "" {
+		return true
+	}
+	if la, lb := utf8.RuneCountInString(a), utf8.RuneCountInString(b); la != lb {
+		return la < lb
+	}
+	return a < b
+}
+
+func containsDigit(s string) bool {
+	return strings.IndexFunc(s, unicode.IsDigit) >= 0
+}
+
+// levenshtein returns the edit distance between a and b, or limit+1 as soon
+// as it is known to exceed limit.
+func levenshtein(a, b []rune, limit int) int {
+	if diff := len(a) - len(b); diff > limit || -diff > limit {
+		return limit + 1
+	}
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		rowMin := curr[0]
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+			rowMin = min(rowMin, curr[j])
+		}
+		if rowMin > limit {
+			return limit + 1
+		}
+		prev, curr = curr, prev
+	}
+	return min(prev[len(b)], limit+1)
+}
+
+// duplicateOf returns an existing page that slug confidently duplicates, or ""
+// when slug deserves its own article. Borderline matches are logged for review.
+func (s *Server) duplicateOf(ctx context.Context, slug string) string {
+	match, ok, err := s.pages.findDuplicate(ctx, slug)
+	if err != nil {
+		log.Printf("duplicate check for %s: %v", slug, err)
+		return ""
+	}
+	if !ok {
+		return ""
+	}
+	if !match.Confident {
+		log.Printf("possible duplicate: %s resembles %s (%s); generating anyway", slug, match.Slug, match.Reason)
+		return ""
+	}
+	return match.Slug
+}
@@ -0,0 +1,72 @@
+package app
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestFindDuplicate(t *testing.T) {
+	existing := []string{"roman_empire", "constantinople", "battle_of_1805", "car", "world_war_ii", "dog_bites_man", "who", "new_york"}
+	cases := []struct {
+		slug      string
+		want      string
+		confident bool
+	}{
+		{"roman-empire", "roman_empire", true},
+		{"the_roman_empire", "roman_empire", true},
+		{"the_roman_empires", "roman_empire", false},
+		{"roman_empires", "roman_empire", false},
+		{"empire_roman", "roman_empire", false},
+		{"man_bites_dog", "dog_bites_man", false},
+		{"the_who", "who", false},
+		{"news_york", "new_york", false},
+		{"constantinple", "constantinople", true},
+		{"battle_of_1806", "battle_of_1805", false},
+		{"world_war_i", "world_war_ii", false},
+		{"cat", "", false},
+		{"medieval_music", "", false},
+	}
+	for _, tc := range cases {
+		match, ok := findDuplicate(tc.slug, slices.Values(existing))
+		if tc.want == "" {
+			if ok {
+				t.Errorf("findDuplicate(%q) = %+v, want no match", tc.slug, match)
+			}
+			continue
+		}
+		if !ok || match.Slug != tc.want || match.Confident != tc.confident {
+			t.Errorf("findDuplicate(%q) = %+v, %v; want %s confident=%v", tc.slug, match, ok, tc.want, tc.confident)
+		}
+	}
+}
+
+func TestDuplicateKey(t *testing.T) {
+	cases := map[string][3]string{
+		"the_churches_of_rome": {"the_churches_of_rome", "church_of_rome", "church_of_rome"},
+		"a":                    {"a", "a", "a"},
+		"well-known_cities":    {"well_known_cities", "well_known_city", "city_known_well"},
+		"crisis":               {"crisis", "crisis", "crisis"},
+		"history_of_art":       {"history_of_art", "history_of_art", "art_history_of"},
+		"the_news":             {"the_news", "news", "news"},
+	}
+	for slug, want := range cases {
+		if got := duplicateKey(slug); got != want[0] {
+			t.Errorf("duplicateKey(%q) = %q, want %q", slug, got, want[0])
+		}
+		if got := looseDuplicateKey(slug); got != want[1] {
+			t.Errorf("looseDuplicateKey(%q) = %q, want %q", slug, got, want[1])
+		}
+		if got := sortedDuplicateKey(slug); got != want[2] {
+			t.Errorf("sortedDuplicateKey(%q) = %q, want %q", slug, got, want[2])
+		}
+	}
+}
+
+func TestLevenshteinStopsAtLimit(t *testing.T) {
+	if got := levenshtein([]rune("kitten"), []rune("sitting"), 5); got != 3 {
+		t.Fatalf("levenshtein = %d, want 3", got)
+	}
+	if got := levenshtein([]rune("kitten"), []rune("sitting"), 2); got != 3 {
+		t.Fatalf("levenshtein with limit 2 = %d, want 3", got)
+	}
+}
@@ -0,0 +1,341 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/language/display"
+)
+
+// defaultLang is the language of a wiki that does not set one.
+const defaultLang = "en"
+
+// maxTranslationSourceLen bounds the source article sent with a translation
+// prompt, in bytes.
+const maxTranslationSourceLen = 12 << 10
+
+var editionLangPattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})?$`)
+
+// reservedEditionLangs are language codes that would shadow a top-level route.
+var reservedEditionLangs = map[string]struct{}{"api": {}}
+
+// lang returns the language the wiki is written in.
+func (cfg Config) lang() string {
+	if cfg.Lang != "" {
+		return cfg.Lang
+	}
+	return defaultLang
+}
+
+// parseEditionLang validates a language code for an edition path.
+func parseEditionLang(code string) (string, error) {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if !editionLangPattern.MatchString(code) {
+		return "", fmt.Errorf("language %q must be a code such as fr or pt-br", code)
+	}
+	if _, reserved := reservedEditionLangs[code]; reserved {
+		return "", fmt.Errorf("language %q clashes with a wiki route", code)
+	}
+	if _, err := language.Parse(code); err != nil {
+		return "", fmt.Errorf("language %q: %w", code, err)
+	}
+	return code, nil
+}
+
+// languageName is the English name of code, for prompts.
+func languageName(code string) string {
+	if name := display.English.Tags().Name(language.Make(code)); name != "" {
+		return name
+	}
+	return code
+}
+
+// nativeLanguageName is the name of code in that language, for the language
+// sidebar.
+func nativeLanguageName(code string) string {
+	if name := display.Self.Name(language.Make(code)); name != "" {
+		return name
+	}
+	return code
+}
+
+// editionConfig derives the configuration of parent's edition in lang, stored
+// in its own database and served under parent's base path at /{lang}.
+func editionConfig(parent Config, lang, dsn string) (Config, error) {
+	lang, err := parseEditionLang(lang)
+	if err != nil {
+		return Config{}, err
+	}
+	if lang == parent.lang() {
+		return Config{}, fmt.Errorf("edition %q has the language of the wiki itself", lang)
+	}
+	if dsn == "" {
+		return Config{}, fmt.Errorf("edition %q has no dsn; each edition needs its own database", lang)
+	}
+
+	cfg := parent
+	cfg.Lang = lang
+	cfg.Edition = true
+	cfg.Editions = nil
+	cfg.Hosts = nil
+	cfg.BasePath = parent.BasePath + "/" + lang
+	// A custom main page is written in the parent's language.
+	cfg.MainPageHTML = ""
+	if cfg.DSN, err = normalizeMySQLDSN(dsn); err != nil {
+		return Config{}, fmt.Errorf("edition %q: %w", lang, err)
+	}
+	return cfg, nil
+}
+
+// parseEditions reads the EDITIONS variable: whitespace-separated
+// lang=dsn pairs, such as "fr=mysql://u:p@db/wiki_fr de=mysql://u:p@db/wiki_de".
+func parseEditions(spec string, parent Config) ([]Config, error) {
+	var editions []Config
+	seen := make(map[string]struct{})
+	for _, field := range strings.Fields(spec) {
+		lang, dsn, ok := strings.Cut(field, "=")
+		if !ok {
+			return nil, fmt.Errorf("edition %q must be written lang=dsn", field)
+		}
+		cfg, err := editionConfig(parent, lang, dsn)
+		if err != nil {
+			return nil, err
+		}
+		if _, dup := seen[cfg.Lang]; dup {
+			return nil, fmt.Errorf("edition %q is defined twice", cfg.Lang)
+		}
+		seen[cfg.Lang] = struct{}{}
+		editions = append(editions, cfg)
+	}
+	return editions, nil
+}
+
+// editionSet is a wiki together with its language editions. Every member
+// points at the same set.
+type editionSet struct {
+	// langs lists the languages in configuration order, the parent first.
+	langs  []string
+	byLang map[string]*Server
+}
+
+// OpenEditions opens the databases of srv's language editions and attaches
+// them, so srv serves them under /{lang}/. It returns the opened databases.
+func OpenEditions(srv *Server) ([]*sql.DB, error) {
+	var editions []*Server
+	var dbs []*sql.DB
+	for _, cfg := range srv.cfg.Editions {
+		edition, db, err := OpenWorld(cfg)
+		if err != nil {
+			for _, db := range dbs {
+				db.Close()
+			}
+			return nil, fmt.Errorf("edition %s: %w", cfg.Lang, err)
+		}
+		editions = append(editions, edition)
+		dbs = append(dbs, db)
+	}
+	linkEditions(srv, editions)
+	return dbs, nil
+}
+
+// linkEditions makes editions the language editions of parent.
+func linkEditions(parent *Server, editions []*Server) {
+	if len(editions) == 0 {
+		return
+	}
+	set := &editionSet{byLang: make(map[string]*Server)}
+	for _, srv := range append([]*Server{parent}, editions...) {
+		set.langs = append(set.langs, srv.cfg.lang())
+		set.byLang[srv.cfg.lang()] = srv
+		srv.editions = set
+	}
+}
+
+// edition returns the sibling edition in lang, or nil when there is none.
+func (s *Server) edition(lang string) *Server {
+	if s.editions == nil {
+		return nil
+	}
+	if srv := s.editions.byLang[lang]; srv != s {
+		return srv
+	}
+	return nil
+}
+
+// serveEdition hands requests under /{lang}/ to that edition. It reports
+// whether the request was handled.
+func (s *Server) serveEdition(w http.ResponseWriter, r *http.Request) bool {
+	if s.cfg.Edition || s.editions == nil {
+		return false
+	}
+	lang, rest, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	edition := s.edition(lang)
+	if edition == nil {
+		return false
+	}
+	if rest == "" && !strings.HasSuffix(r.URL.Path, "/") {
+		http.Redirect(w, r, edition.path("/"), http.StatusMovedPermanently)
+		return true
+	}
+	http.StripPrefix("/"+lang, edition).ServeHTTP(w, r)
+	return true
+}
+
+// languageLink is an entry of the "in other languages" sidebar. Links to
+// editions that lack the article generate it from this one.
+type languageLink struct {
+	Lang   string
+	Name   string
+	Href   string
+	Exists bool
+}
+
+// languageLinks lists slug in every other edition.
+func (s *Server) languageLinks(ctx context.Context, slug string) ([]languageLink, error) {
+	if s.editions == nil {
+		return nil, nil
+	}
+	translations, err := loadLangLinks(ctx, s.db, slug)
+	if err != nil {
+		return nil, err
+	}
+	return s.languageLinksFrom(slug, translations), nil
+}
+
+// languageLinksFrom builds the sidebar from the known translations of slug,
+// keyed by language.
+func (s *Server) languageLinksFrom(slug string, translations map[string]string) []languageLink {
+	var links []languageLink
+	for _, lang := range s.editions.langs {
+		edition := s.edition(lang)
+		if edition == nil {
+			continue
+		}
+		link := languageLink{Lang: lang, Name: nativeLanguageName(lang)}
+		switch target, ok := translations[lang]; {
+		case ok:
+			link.Href, link.Exists = edition.path("/wiki/"+url.PathEscape(target)), true
+		case slug == "main_page":
+			link.Href, link.Exists = edition.path("/wiki/main_page"), true
+		default:
+			link.Href = edition.path("/wiki/"+url.PathEscape(slug)) + "?from=" + url.QueryEscape(s.cfg.lang())
+		}
+		links = append(links, link)
+	}
+	return links
+}
+
+// translationSource is an article in another edition that a new page is
+// generated from.
+type translationSource struct {
+	Lang string
+	Page *Page
+}
+
+// loadTranslationSource loads the article slug from the edition in lang, following
+// a redirect there. It returns nil when that edition has no such article,
+// except that the parent wiki's main page is the one it would store.
+func (s *Server) loadTranslationSource(ctx context.Context, lang, slug string) (*translationSource, error) {
+	edition := s.edition(lang)
+	if edition == nil {
+		return nil, nil
+	}
+	page, err := edition.lookupPage(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if page == nil && slug == "main_page" && !edition.cfg.Edition {
+		content := edition.defaultMainPage()
+		page = &Page{Slug: slug, Title: ExtractTitle(content), Content: content}
+	}
+	if page == nil {
+		target, err := lookupRedirect(ctx, edition.db, slug)
+		if err != nil || target == "" {
+			return nil, err
+		}
+		if page, err = edition.lookupPage(ctx, target); err != nil || page == nil {
+			return nil, err
+		}
+	}
+	return &translationSource{Lang: lang, Page: page}, nil
+}
+
+// translationInstructions asks for the article to be based on source, or
+// returns "" when there is none.
+func translationInstructions(cfg Config, source *translationSource) string {
+	if source == nil {
+		return ""
+	}
+	content := source.Page.Content
+	if len(content) > maxTranslationSourceLen {
+		content = strings.ToValidUTF8(content[:maxTranslationSourceLen], "")
+	}
+	return fmt.Sprintf("Base the article on this article from the %s edition. Keep its facts and structure, but write naturally in %s rather than translating word for word, and link to topics by their %s names:\n\n%s\n",
+		languageName(source.Lang), languageName(cfg.lang()), languageName(cfg.lang()), content)
+}
+
+// linkTranslation records slug as the translation of source, and as the
+// translation of every article source is already linked to, in all the
+// editions involved.
+func (s *Server) linkTranslation(ctx context.Context, slug string, source *translationSource) {
+	origin := s.edition(source.Lang)
+	if origin == nil {
+		return
+	}
+	translations, err := loadLangLinks(ctx, origin.db, source.Page.Slug)
+	if err != nil {
+		log.Printf("language links for %s:%s: %v", source.Lang, source.Page.Slug, err)
+		translations = make(map[string]string)
+	}
+	translations[source.Lang] = source.Page.Slug
+
+	for lang, target := range translations {
+		edition := s.edition(lang)
+		if edition == nil {
+			continue
+		}
+		if err := saveLangLink(ctx, s.db, slug, lang, target); err != nil {
+			log.Printf("language link %s -> %s:%s: %v", slug, lang, target, err)
+			continue
+		}
+		if err := saveLangLink(ctx, edition.db, target, s.cfg.lang(), slug); err != nil {
+			log.Printf("language link %s:%s -> %s: %v", lang, target, slug, err)
+			continue
+		}
+		edition.renders.Invalidate(target)
+	}
+}
+
+// loadLangLinks returns the translations of slug recorded in db, keyed by
+// language.
+func loadLangLinks(ctx context.Context, db *sql.DB, slug string) (map[string]string, error) {
+	rows, err := db.QueryContext(ctx, `SELECT lang, target_slug FROM langlinks WHERE page_slug = ?`, slug)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	translations := make(map[string]string)
+	for rows.Next() {
+		var lang, target string
+		if err := rows.Scan(&lang, &target); err != nil {
+			return nil, err
+		}
+		translations[lang] = target
+	}
+	return translations, rows.Err()
+}
+
+func saveLangLink(ctx context.Context, db execer, slug, lang, target string) error {
+	const upsert = `INSERT INTO langlinks (page_slug, lang, target_slug) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE target_slug = VALUES(target_slug)`
+	_, err := db.ExecContext(ctx, upsert, slug, lang, target)
+	return err
+}
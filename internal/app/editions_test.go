@@ -0,0 +1,213 @@
+package app
+
+import (
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestParseEditions(t *testing.T) {
+	parent := Config{World: "scifi", BasePath: "/w/scifi", MainPageHTML: "<h1>Home</h1>", DSN: "parent"}
+	editions, err := parseEditions("fr=mysql://u:p@db/scifi_fr\n DE-CH=u:p@tcp(db:3306)/scifi_de", parent)
+	if err != nil {
+		t.Fatalf("parseEditions: %v", err)
+	}
+	if len(editions) != 2 {
+		t.Fatalf("got %d editions", len(editions))
+	}
+	fr, de := editions[0], editions[1]
+	if fr.Lang != "fr" || fr.BasePath != "/w/scifi/fr" || !fr.Edition || fr.World != "scifi" || fr.MainPageHTML != "" {
+		t.Fatalf("fr = %+v", fr)
+	}
+	if fr.DSN != "u:p@tcp(db:3306)/scifi_fr?parseTime=true" {
+		t.Fatalf("fr dsn = %q", fr.DSN)
+	}
+	if de.Lang != "de-ch" || de.BasePath != "/w/scifi/de-ch" {
+		t.Fatalf("de = %+v", de)
+	}
+
+	for _, spec := range []string{"fr", "fr=", "api=u@tcp(db)/x", "en=u@tcp(db)/x", "f!=u@tcp(db)/x", "fr=u@tcp(db)/a fr=u@tcp(db)/b"} {
+		if _, err := parseEditions(spec, Config{}); err == nil {
+			t.Errorf("parseEditions(%q) succeeded", spec)
+		}
+	}
+}
+
+func newEditionServers(t *testing.T, parent Config, langs ...string) (*Server, map[string]*Server) {
+	t.Helper()
+	primary, err := NewServer(nil, parent)
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	byLang := make(map[string]*Server)
+	var editions []*Server
+	for _, lang := range langs {
+		cfg, err := editionConfig(parent, lang, "u:p@tcp(db)/"+lang)
+		if err != nil {
+			t.Fatalf("editionConfig: %v", err)
+		}
+		srv, err := NewServer(nil, cfg)
+		if err != nil {
+			t.Fatalf("NewServer: %v", err)
+		}
+		byLang[lang] = srv
+		editions = append(editions, srv)
+	}
+	linkEditions(primary, editions)
+	return primary, byLang
+}
+
+func TestEditionRouting(t *testing.T) {
+	primary, _ := newEditionServers(t, Config{}, "fr", "de")
+	world, _ := newEditionServers(t, Config{World: "scifi", BasePath: "/w/scifi"}, "fr")
+	router := NewWorldRouter(primary, []*Server{world})
+
+	for _, tc := range []struct {
+		path     string
+		status   int
+		location string
+	}{
+		{"/fr/", http.StatusFound, "/fr/wiki/main_page"},
+		{"/fr/wiki/", http.StatusFound, "/fr/wiki/main_page"},
+		{"/fr", http.StatusMovedPermanently, "/fr/"},
+		{"/de/wiki/", http.StatusFound, "/de/wiki/main_page"},
+		{"/es/wiki/", http.StatusNotFound, ""},
+		{"/fr/constellation", http.StatusNotFound, ""},
+		{"/w/scifi/fr/wiki/", http.StatusFound, "/w/scifi/fr/wiki/main_page"},
+		{"/w/scifi/de/wiki/", http.StatusNotFound, ""},
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tc.path, nil))
+		if rec.Code != tc.status || rec.Header().Get("Location") != tc.location {
+			t.Errorf("%s: status %d location %q, want %d %q", tc.path, rec.Code, rec.Header().Get("Location"), tc.status, tc.location)
+		}
+	}
+}
+
+func TestLanguageLinks(t *testing.T) {
+	primary, editions := newEditionServers(t, Config{}, "fr", "de")
+
+	links := primary.languageLinksFrom("rome", map[string]string{"de": "rom"})
+	want := []languageLink{
+		{Lang: "fr", Name: "français", Href: "/fr/wiki/rome?from=en"},
+		{Lang: "de", Name: "Deutsch", Href: "/de/wiki/rom", Exists: true},
+	}
+	if len(links) != len(want) {
+		t.Fatalf("links = %+v", links)
+	}
+	for i := range want {
+		if links[i] != want[i] {
+			t.Errorf("links[%d] = %+v, want %+v", i, links[i], want[i])
+		}
+	}
+
+	links = editions["de"].languageLinksFrom("main_page", nil)
+	if len(links) != 2 || links[0].Href != "/wiki/main_page" || !links[0].Exists || links[1].Href != "/fr/wiki/main_page" {
+		t.Errorf("main page links = %+v", links)
+	}
+}
+
+func TestEditionPrompts(t *testing.T) {
+	fr := Config{Lang: "fr", SystemPrompt: "A space opera."}
+	if got := fr.setting(); got != "A space opera. Write in French." {
+		t.Errorf("setting = %q", got)
+	}
+	if got := (Config{}).setting(); got != defaultSetting {
+		t.Errorf("default setting = %q", got)
+	}
+
+	if translationInstructions(fr, nil) != "" {
+		t.Error("instructions without a source")
+	}
+	source := &translationSource{Lang: "en", Page: &Page{Slug: "rome", Content: "<h1>Rome</h1><p>Founded in 753 BC.</p>"}}
+	got := translationInstructions(fr, source)
+	for _, want := range []string{"English edition", "in French", "Founded in 753 BC."} {
+		if !strings.Contains(got, want) {
+			t.Errorf("instructions missing %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestTranslatorFallsBack(t *testing.T) {
+	if got := translator("de-ch")("Random page"); got != "Zufälliger Artikel" {
+		t.Errorf("de-ch = %q", got)
+	}
+	if got := translator("ja")("Random page"); got != "Random page" {
+		t.Errorf("ja = %q", got)
+	}
+	if got := translator("fr")("The infinite encyclopedia. %d pages discovered so far.", 3); got != "L’encyclopédie infinie. 3 pages découvertes jusqu’ici." {
+		t.Errorf("fr = %q", got)
+	}
+}
+
+var templateMessagePattern = regexp.MustCompile("\\{\\{t (?:\"((?:[^\"\\\\]|\\\\.)*)\"|`([^`]*)`)")
+
+// TestUIMessagesCoverTemplates keeps every catalog in step with the strings
+// the templates translate.
+func TestUIMessagesCoverTemplates(t *testing.T) {
+	used := make(map[string]struct{})
+	err := fs.WalkDir(templateFS, "templates", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		buf, err := fs.ReadFile(templateFS, path)
+		if err != nil {
+			return err
+		}
+		for _, m := range templateMessagePattern.FindAllStringSubmatch(string(buf), -1) {
+			used[m[1]+m[2]] = struct{}{}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(used) == 0 {
+		t.Fatal("no translated strings found in templates")
+	}
+
+	for lang, messages := range uiMessages {
+		for msg := range used {
+			if _, ok := messages[msg]; !ok {
+				t.Errorf("%s: missing translation of %q", lang, msg)
+			}
+		}
+		for msg := range messages {
+			if _, ok := used[msg]; !ok {
+				t.Errorf("%s: %q is not used by any template", lang, msg)
+			}
+		}
+	}
+}
+
+func TestWikiTemplateInEdition(t *testing.T) {
+	_, editions := newEditionServers(t, Config{}, "fr")
+	var b strings.Builder
+	data := map[string]any{
+		"Title":     "Rome",
+		"Slug":      "rome",
+		"PageCount": 7,
+		"Languages": []languageLink{{Lang: "en", Name: "English", Href: "/wiki/rome", Exists: true}},
+	}
+	if err := editions["fr"].templates.ExecuteTemplate(&b, "wiki.gohtml", data); err != nil {
+		t.Fatalf("render wiki: %v", err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		`<html lang="fr">`,
+		`href="/fr/random">Article au hasard</a>`,
+		"7 pages découvertes",
+		`<a href="/wiki/rome" hreflang="en" lang="en">English</a>`,
+		`action="/fr/expand/rome"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %s", want)
+		}
+	}
+	if strings.Contains(out, "/constellation") {
+		t.Error("edition links to the default wiki's constellation map")
+	}
+}
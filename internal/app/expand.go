@@ -0,0 +1,369 @@
+package app
+
+import (
+	"context"
+	"crypto/subtle"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	nethtml "golang.org/x/net/html"
+)
+
+const (
+	// maxExpandedContentLen stops pages from growing without bound; an
+	// article this long is not expanded further.
+	maxExpandedContentLen = 64 << 10
+	// maxExpansionLen bounds the HTML accepted for one expansion.
+	maxExpansionLen = 16 << 10
+	// minExpansionText is the least visible text an expansion must add.
+	minExpansionText  = 200
+	maxExpandTopicLen = 120
+)
+
+var (
+	errPageTooLong       = errors.New("article is already at its maximum length")
+	errExpansionConflict = errors.New("article changed while it was being expanded")
+)
+
+// expansionTags are the elements an expansion may contain. Attributes other
+// than href on links are dropped.
+var expansionTags = map[string]bool{
+	"h2": true, "h3": true, "p": true, "ul": true, "ol": true, "li": true,
+	"a": true, "b": true, "strong": true, "i": true, "em": true, "blockquote": true,
+	"table": true, "caption": true, "thead": true, "tbody": true, "tr": true, "th": true, "td": true,
+	"dl": true, "dt": true, "dd": true, "code": true, "sup": true, "sub": true, "br": true,
+}
+
+// ExpandPageHTML asks the model for one or more new sections extending page,
+// about topic or, when topic is empty, a subtopic of the model's choosing. It
+// returns only the new sections. lore lists facts other articles have
+// established about the page, which the new sections must not contradict.
+func ExpandPageHTML(ctx context.Context, client *http.Client, cfg Config, page *Page, topic string, lore []loreFact) (string, error) {
+	if cfg.GroqAPIKey == "" {
+		return stubExpansion(page, topic), nil
+	}
+
+	focus := "a subtopic the article does not cover yet"
+	if topic != "" {
+		focus = fmt.Sprintf("'%s'", topic)
+	}
+	messages := []groqMessage{
+		{
+			Role:    "system",
+			Content: cfg.setting() + ` You are extending one of its articles. Output only the HTML of 1-2 new sections, each starting with an <h2> heading followed by paragraphs or lists. Do not repeat the title or existing sections, and do not output <h1>, <div>, scripts, or styles. Stay consistent with every fact in the article. Include 1-3 internal links to related topics using <a href="/wiki/..."> anchors.`,
+		},
+		{
+			Role:    "user",
+			Content: fmt.Sprintf("Article:\n%s\n\nWrite new sections about %s.", page.Content, focus) + "\n\n" + loreInstructions(lore),
+		},
+	}
+
+	content, err := groqComplete(ctx, client, cfg, messages, 0.7, 1200)
+	if err != nil {
+		return "", err
+	}
+	return stripHTMLCodeFence(content), nil
+}
+
+func stubExpansion(page *Page, topic string) string {
+	if topic == "" {
+		topic = "Further details"
+	}
+	related := page.Slug + "_legacy"
+	if normalized, err := NormalizeSlug(related); err == nil {
+		related = normalized
+	}
+	var b strings.Builder
+	b.WriteString("<h2>")
+	b.WriteString(templateEscape(topic))
+	b.WriteString("</h2>\n<p>This placeholder section about ")
+	b.WriteString(templateEscape(topic))
+	b.WriteString(" extends the article on ")
+	b.WriteString(templateEscape(page.DisplayTitle()))
+	b.WriteString(" without Groq access. A generated section would describe the subject in more depth, drawing on what the article already establishes.</p>\n")
+	b.WriteString("<p>See also <a href=\"/wiki/")
+	b.WriteString(url.PathEscape(related))
+	b.WriteString("\">")
+	b.WriteString(templateEscape(SlugTitle(related)))
+	b.WriteString("</a>.</p>\n")
+	return b.String()
+}
+
+// validateExpansion checks generated sections and returns them normalised:
+// the fragment must open with an <h2>, be well nested, use only
+// expansionTags, link only within the wiki, and add some text.
+func validateExpansion(sections string) (string, error) {
+	sections, _ = splitMetaTrailer(sections)
+	if len(sections) > maxExpansionLen {
+		return "", fmt.Errorf("expansion is %d bytes, over the %d byte limit", len(sections), maxExpansionLen)
+	}
+
+	z := nethtml.NewTokenizer(strings.NewReader(sections))
+	var b strings.Builder
+	var open []string
+	textLen := 0
+	sawHeading := false
+
+	for {
+		tt := z.Next()
+		if tt == nethtml.ErrorToken {
+			break
+		}
+		raw := string(z.Raw())
+
+		switch tt {
+		case nethtml.StartTagToken, nethtml.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			tag := string(name)
+			if !expansionTags[tag] {
+				return "", fmt.Errorf("expansion uses disallowed element <%s>", tag)
+			}
+			if !sawHeading {
+				if tag != "h2" {
+					return "", fmt.Errorf("expansion must start with <h2>, not <%s>", tag)
+				}
+				sawHeading = true
+			}
+			var attrs []nethtml.Attribute
+			if hasAttr {
+				for _, attr := range readAttrs(z) {
+					if tag != "a" || attr.Key != "href" {
+						continue
+					}
+					if !strings.HasPrefix(attr.Val, "/wiki/") || slugFromHref(attr.Val) == "" {
+						return "", fmt.Errorf("expansion links outside the wiki: %q", attr.Val)
+					}
+					attrs = append(attrs, attr)
+				}
+			}
+			writeTag(&b, tag, attrs, tt == nethtml.SelfClosingTagToken)
+			if tag != "br" && tt == nethtml.StartTagToken {
+				open = append(open, tag)
+			}
+		case nethtml.EndTagToken:
+			name, _ := z.TagName()
+			tag := string(name)
+			if tag == "br" {
+				continue
+			}
+			if len(open) == 0 || open[len(open)-1] != tag {
+				return "", fmt.Errorf("expansion has unbalanced </%s>", tag)
+			}
+			open = open[:len(open)-1]
+			b.WriteString(raw)
+		case nethtml.TextToken:
+			if !sawHeading {
+				if strings.TrimSpace(raw) != "" {
+					return "", errors.New("expansion must start with <h2>")
+				}
+				continue
+			}
+			textLen += len(strings.Join(strings.Fields(string(z.Text())), " "))
+			b.WriteString(raw)
+		case nethtml.CommentToken, nethtml.DoctypeToken:
+			// Dropped.
+		}
+	}
+	if err := z.Err(); err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	if len(open) > 0 {
+		return "", fmt.Errorf("expansion leaves <%s> unclosed", open[len(open)-1])
+	}
+	if !sawHeading {
+		return "", errors.New("expansion has no <h2> section")
+	}
+	if textLen < minExpansionText {
+		return "", fmt.Errorf("expansion adds only %d characters of text", textLen)
+	}
+	return strings.TrimSpace(b.String()), nil
+}
+
+// appendSections adds sections to the end of the article body, inside the
+// closing </div> of the endlesswiki-body wrapper when there is one, so the
+// existing content is kept byte for byte.
+func appendSections(content, sections string) string {
+	at := len(content)
+	trimmed := strings.TrimRight(content, " \t\r\n")
+	const closing = "</div>"
+	if len(trimmed) >= len(closing) && strings.EqualFold(trimmed[len(trimmed)-len(closing):], closing) &&
+		strings.Contains(content, `class="endlesswiki-body"`) {
+		at = len(trimmed) - len(closing)
+	}
+	return content[:at] + "\n" + sections + "\n" + content[at:]
+}
+
+// storeExpansion records the expanded content as a new revision and makes it
+// the page's current content. The original generation becomes revision 1 the
+// first time a page is expanded. It fails with errExpansionConflict if the
+// page no longer holds previous.
+func storeExpansion(ctx context.Context, db *sql.DB, slug, previous, content, summary string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var current string
+	var created time.Time
+	err = tx.QueryRowContext(ctx, `SELECT content, created_at FROM pages WHERE slug = ? FOR UPDATE`, slug).Scan(&current, &created)
+	if err != nil {
+		return err
+	}
+	if current != previous {
+		return errExpansionConflict
+	}
+
+	var revisions int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM page_revisions WHERE page_slug = ?`, slug).Scan(&revisions); err != nil {
+		return err
+	}
+	if revisions == 0 {
+		const initial = `INSERT INTO page_revisions (page_slug, content, summary, created_at) VALUES (?, ?, 'Initial generation', ?)`
+		if _, err := tx.ExecContext(ctx, initial, slug, previous, created); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, `INSERT INTO page_revisions (page_slug, content, summary) VALUES (?, ?, ?)`, slug, content, summary); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `UPDATE pages SET content = ?, updated_at = CURRENT_TIMESTAMP WHERE slug = ?`, content, slug); err != nil {
+		return err
+	}
+	if err := replacePageLinks(ctx, tx, slug, content); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// expandPage generates, validates, and stores new sections for page,
+// returning the updated page.
+func (s *Server) expandPage(ctx context.Context, page *Page, topic string) (*Page, error) {
+	if len(page.Content) >= maxExpandedContentLen {
+		return nil, errPageTooLong
+	}
+
+	lore, err := loadLoreFacts(ctx, s.db, page.Slug, maxLoreFactsInPrompt)
+	if err != nil {
+		log.Printf("lore facts for %s: %v", page.Slug, err)
+	}
+	generated, err := ExpandPageHTML(ctx, s.httpClient, s.cfg, page, topic, lore)
+	if err != nil {
+		return nil, err
+	}
+	sections, err := validateExpansion(generated)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := "Expanded"
+	if topic != "" {
+		summary = "Expanded: " + topic
+	}
+	content := appendSections(page.Content, sections)
+	if err := storeExpansion(ctx, s.db, page.Slug, page.Content, content, summary); err != nil {
+		return nil, err
+	}
+
+	updated := *page
+	updated.Content = content
+	updated.UpdatedAt = time.Now()
+	s.pageCreated(ctx, &updated)
+	return &updated, nil
+}
+
+// isAdmin reports whether the request carries the configured admin token,
+// either as a bearer token or, so browsers can prompt for it, as the password
+// of HTTP basic auth.
+func (s *Server) isAdmin(r *http.Request) bool {
+	if s.cfg.AdminToken == "" {
+		return false
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		_, token, ok = r.BasicAuth()
+	}
+	return ok && subtle.ConstantTimeCompare([]byte(token), []byte(s.cfg.AdminToken)) == 1
+}
+
+// expandTopic cleans the optional subtopic a reader asked for.
+func expandTopic(raw string) (string, error) {
+	topic := strings.Join(strings.Fields(raw), " ")
+	if len([]rune(topic)) > maxExpandTopicLen {
+		return "", fmt.Errorf("topic is longer than %d characters", maxExpandTopicLen)
+	}
+	if strings.ContainsAny(topic, "<>") {
+		return "", errors.New("topic may not contain markup")
+	}
+	return topic, nil
+}
+
+// handleExpand serves POST /expand/{slug}. Readers share the generation rate
+// limit; requests with the admin token skip it.
+func (s *Server) handleExpand(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	decoded, err := url.PathUnescape(strings.TrimPrefix(r.URL.Path, "/expand/"))
+	if err != nil {
+		http.Error(w, "bad slug", http.StatusBadRequest)
+		return
+	}
+	slug, err := NormalizeSlug(decoded)
+	if err != nil || slug == "main_page" {
+		http.NotFound(w, r)
+		return
+	}
+	topic, err := expandTopic(r.PostFormValue("topic"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ctx := r.Context()
+	page, err := s.lookupPage(ctx, slug)
+	if err != nil {
+		log.Printf("lookup page %s: %v", slug, err)
+		http.Error(w, "database error", http.StatusInternalServerError)
+		return
+	}
+	if page == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if !s.isAdmin(r) && !s.allowGeneration(clientIP(r), time.Now()) {
+		w.Header().Set("Retry-After", strconv.Itoa(int(genLimitWindow.Seconds())))
+		http.Error(w, "generation rate limit exceeded; please wait before expanding articles", http.StatusTooManyRequests)
+		return
+	}
+
+	// Identical concurrent expansions share the first. An expansion with
+	// another topic runs on its own and, if the page changes under it, fails
+	// with errExpansionConflict. Topics never contain a newline.
+	_, expandErr, _ := s.genGroup.Do("expand:"+slug+"\n"+topic, func() (interface{}, error) {
+		return s.expandPage(ctx, page, topic)
+	})
+	switch {
+	case errors.Is(expandErr, errPageTooLong), errors.Is(expandErr, errExpansionConflict):
+		http.Error(w, expandErr.Error(), http.StatusConflict)
+		return
+	case expandErr != nil:
+		log.Printf("expand page %s: %v", slug, expandErr)
+		http.Error(w, "failed to expand article", http.StatusBadGateway)
+		return
+	}
+
+	http.Redirect(w, r, s.path("/wiki/"+url.PathEscape(slug)), http.StatusSeeOther)
+}
@@ -0,0 +1,119 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const expansionText = "The guild kept its ledgers in the old harbour tower, where clerks recorded every cargo that passed the chain boom. " +
+	"Their accounts survive in fragments and remain the main source for the city's trade in salt, amber, and dyed wool."
+
+func TestValidateExpansion(t *testing.T) {
+	in := "<!-- note --><h2 class=\"x\" onclick=\"evil()\">Trade</h2>\n<p>" + expansionText +
+		` See <a href="/wiki/salt_road" title="t">the salt road</a>.<br></p>`
+	got, err := validateExpansion(in)
+	if err != nil {
+		t.Fatalf("validateExpansion: %v", err)
+	}
+	want := "<h2>Trade</h2>\n<p>" + expansionText + ` See <a href="/wiki/salt_road">the salt road</a>.<br></p>`
+	if got != want {
+		t.Fatalf("validateExpansion =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestValidateExpansionRejects(t *testing.T) {
+	for name, in := range map[string]string{
+		"no heading":     "<p>" + expansionText + "</p>",
+		"leading text":   "Sure! <h2>Trade</h2><p>" + expansionText + "</p>",
+		"title":          "<h1>Veldor</h1><h2>Trade</h2><p>" + expansionText + "</p>",
+		"script":         "<h2>Trade</h2><p>" + expansionText + "</p><script>alert(1)</script>",
+		"closes wrapper": "<h2>Trade</h2><p>" + expansionText + "</p></div>",
+		"unclosed":       "<h2>Trade</h2><p>" + expansionText,
+		"external link":  `<h2>Trade</h2><p>` + expansionText + `<a href="https://example.com">x</a></p>`,
+		"too short":      "<h2>Trade</h2><p>Short.</p>",
+	} {
+		if _, err := validateExpansion(in); err == nil {
+			t.Errorf("%s: validateExpansion accepted %q", name, in)
+		}
+	}
+}
+
+func TestStubExpansionIsValid(t *testing.T) {
+	page := &Page{Slug: "veldor", Title: "Veldor"}
+	if _, err := validateExpansion(stubExpansion(page, "Trade routes")); err != nil {
+		t.Fatalf("stub expansion rejected: %v", err)
+	}
+}
+
+func TestAppendSectionsKeepsContent(t *testing.T) {
+	content := "<h1>Veldor</h1>\n<div class=\"endlesswiki-body\">\n<p>Intro with <a href=\"/wiki/salt\">salt</a>.</p>\n</div>"
+	got := appendSections(content, "<h2>Trade</h2><p>More.</p>")
+	want := "<h1>Veldor</h1>\n<div class=\"endlesswiki-body\">\n<p>Intro with <a href=\"/wiki/salt\">salt</a>.</p>\n\n<h2>Trade</h2><p>More.</p>\n</div>"
+	if got != want {
+		t.Fatalf("appendSections =\n%q\nwant\n%q", got, want)
+	}
+
+	if got := appendSections("<h1>X</h1><p>a</p>", "<h2>B</h2>"); got != "<h1>X</h1><p>a</p>\n<h2>B</h2>\n" {
+		t.Fatalf("appendSections without wrapper = %q", got)
+	}
+}
+
+func TestExpandTopic(t *testing.T) {
+	if topic, err := expandTopic("  trade \n routes "); err != nil || topic != "trade routes" {
+		t.Fatalf("expandTopic = %q, %v", topic, err)
+	}
+	for _, raw := range []string{strings.Repeat("x", maxExpandTopicLen+1), "<b>trade</b>"} {
+		if _, err := expandTopic(raw); err == nil {
+			t.Errorf("expandTopic(%q) succeeded", raw)
+		}
+	}
+}
+
+func TestIsAdmin(t *testing.T) {
+	srv := &Server{cfg: Config{AdminToken: "secret"}}
+	r := httptest.NewRequest(http.MethodPost, "/expand/veldor", nil)
+	if srv.isAdmin(r) {
+		t.Fatalf("request without token is admin")
+	}
+	r.Header.Set("Authorization", "Bearer wrong")
+	if srv.isAdmin(r) {
+		t.Fatalf("wrong token is admin")
+	}
+	r.Header.Set("Authorization", "Bearer secret")
+	if !srv.isAdmin(r) {
+		t.Fatalf("admin token rejected")
+	}
+
+	open := &Server{}
+	r.Header.Set("Authorization", "Bearer ")
+	if open.isAdmin(r) {
+		t.Fatalf("empty token matched unset AdminToken")
+	}
+}
+
+func TestExpandRequiresPost(t *testing.T) {
+	srv, err := NewServer(nil, Config{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/expand/veldor", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("status = %d, want 405", rec.Code)
+	}
+}
+
+func TestIsAdminAcceptsBasicAuth(t *testing.T) {
+	srv := &Server{cfg: Config{AdminToken: "secret"}}
+	r := httptest.NewRequest(http.MethodGet, "/admin/contradictions", nil)
+	r.SetBasicAuth("admin", "secret")
+	if !srv.isAdmin(r) {
+		t.Fatalf("basic auth with the admin token rejected")
+	}
+	r.SetBasicAuth("admin", "wrong")
+	if srv.isAdmin(r) {
+		t.Fatalf("basic auth with a wrong password accepted")
+	}
+}
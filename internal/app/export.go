@@ -0,0 +1,84 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"html/template"
+	"strings"
+	"time"
+)
+
+// ArticleRenderer renders stored pages for offline exports, in which every
+// page is an entry named slug plus a suffix beside the others. Articles are
+// decorated as the wiki shows them, with infobox, table of contents, and
+// categories; links to pages outside the export become plain text.
+type ArticleRenderer struct {
+	srv      *Server
+	tmpl     *template.Template
+	existing map[string]struct{}
+	entries  map[string]string
+	suffix   string
+}
+
+// Article is a page rendered by ArticleRenderer.
+type Article struct {
+	Slug    string
+	Title   string
+	Created time.Time
+	Body    template.HTML
+}
+
+// NewArticleRenderer returns a renderer for the pages of db written in lang,
+// for an export in which the slugs linked to are the keys of entries, each
+// written as the entry it maps to. Exports with their own redirect entries map
+// aliases to themselves; others map them to their targets.
+func NewArticleRenderer(db *sql.DB, lang string, entries map[string]string, suffix string) (*ArticleRenderer, error) {
+	srv, err := NewServer(db, Config{Lang: lang})
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := srv.templates.Clone()
+	if err != nil {
+		return nil, err
+	}
+	tmpl.Funcs(template.FuncMap{
+		"base": func() string { return "." },
+		"link": func(p string) string {
+			slug, _ := strings.CutPrefix(p, "/wiki/")
+			if entry, ok := entries[slug]; ok {
+				slug = entry
+			}
+			return slug + suffix
+		},
+		"static": func() bool { return true },
+	})
+	existing := make(map[string]struct{}, len(entries))
+	for slug := range entries {
+		existing[slug] = struct{}{}
+	}
+	return &ArticleRenderer{srv: srv, tmpl: tmpl, existing: existing, entries: entries, suffix: suffix}, nil
+}
+
+// Render renders the page slug, or returns nil when there is no such page.
+func (r *ArticleRenderer) Render(ctx context.Context, slug string) (*Article, error) {
+	page, err := r.srv.lookupPage(ctx, slug)
+	if err != nil || page == nil {
+		return nil, err
+	}
+	mode := linkMode{
+		existing: r.existing,
+		rewrite: func(content string, missing map[string]struct{}) string {
+			return exportLinks(content, missing, r.entries, r.suffix)
+		},
+	}
+	rendered, err := r.srv.decoratePage(ctx, page, mode, page.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := r.tmpl.ExecuteTemplate(&buf, "article", newWikiPageView(rendered, "", 0)); err != nil {
+		return nil, err
+	}
+	return &Article{Slug: page.Slug, Title: rendered.Title, Created: page.CreatedAt, Body: template.HTML(buf.String())}, nil
+}
@@ -0,0 +1,39 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestArticleTemplate(t *testing.T) {
+	r, err := NewArticleRenderer(nil, "", nil, "")
+	if err != nil {
+		t.Fatalf("NewArticleRenderer: %v", err)
+	}
+	page := &renderedPage{
+		Slug:       "rome",
+		Title:      "Rome",
+		Body:       `<h1>Rome</h1><p>See <a href="carthage">Carthage</a>.</p>`,
+		Infobox:    &infoboxView{Type: "place", Title: "Rome", Rows: []infoboxRow{{Label: "Founder", Value: "Romulus", Href: "/wiki/romulus"}}},
+		Categories: []categoryLink{{Name: "cities", Title: "Cities"}},
+		headingEnd: len("<h1>Rome</h1>"),
+	}
+	var b strings.Builder
+	if err := r.tmpl.ExecuteTemplate(&b, "article", newWikiPageView(page, "", 0)); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		`<h1>Rome</h1>`,
+		`<a href="romulus">Romulus</a>`,
+		`<a href="carthage">Carthage</a>`,
+		`<li>Cities</li>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %s:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "/wiki/") || strings.Contains(out, "/category/") {
+		t.Errorf("article links outside the export:\n%s", out)
+	}
+}
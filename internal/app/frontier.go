@@ -0,0 +1,235 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// frontierReloadInterval controls how often the index is rebuilt from
+	// page_links to pick up pages created by other instances.
+	frontierReloadInterval = 30 * time.Minute
+	frontierPickAttempts   = 8
+)
+
+// frontierIndex tracks every linked-but-missing slug together with the pages
+// that link to it. Targets live in a slice so a uniform pick is O(1).
+type frontierIndex struct {
+	mu        sync.Mutex
+	loaded    bool
+	reloading bool
+	loadedAt  time.Time
+	targets   []string
+	position  map[string]int
+	sources   map[string][]string
+	load      func(context.Context) (map[string][]string, error)
+}
+
+func newFrontierIndex(load func(context.Context) (map[string][]string, error)) *frontierIndex {
+	return &frontierIndex{load: load}
+}
+
+// ensure loads the index on first use and schedules a background rebuild once
+// it is older than frontierReloadInterval.
+func (f *frontierIndex) ensure(ctx context.Context) error {
+	f.mu.Lock()
+	if f.loaded {
+		if !f.reloading && time.Since(f.loadedAt) >= frontierReloadInterval {
+			f.reloading = true
+			go f.reload()
+		}
+		f.mu.Unlock()
+		return nil
+	}
+	f.mu.Unlock()
+
+	entries, err := f.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.loaded {
+		f.replace(entries)
+	}
+	return nil
+}
+
+func (f *frontierIndex) reload() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	entries, err := f.load(ctx)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.reloading = false
+	if err != nil {
+		log.Printf("reload frontier: %v", err)
+		return
+	}
+	f.replace(entries)
+}
+
+// replace swaps in a freshly loaded index. Callers hold f.mu.
+func (f *frontierIndex) replace(entries map[string][]string) {
+	f.targets = make([]string, 0, len(entries))
+	f.position = make(map[string]int, len(entries))
+	f.sources = entries
+	for target := range entries {
+		f.position[target] = len(f.targets)
+		f.targets = append(f.targets, target)
+	}
+	f.loaded, f.loadedAt = true, time.Now()
+}
+
+// Size reports the number of distinct missing slugs, or -1 before the index
+// has loaded.
+func (f *frontierIndex) Size() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.loaded {
+		return -1
+	}
+	return len(f.targets)
+}
+
+// Pick returns a random missing slug and one page that links to it.
+func (f *frontierIndex) Pick(intn func(int) int) (target, source string, ok bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.targets) == 0 {
+		return "", "", false
+	}
+	target = f.targets[intn(len(f.targets))]
+	sources := f.sources[target]
+	if len(sources) == 0 {
+		return "", "", false
+	}
+	return target, sources[intn(len(sources))], true
+}
+
+// Resolve removes slug from the frontier once it has a page.
+func (f *frontierIndex) Resolve(slug string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	idx, ok := f.position[slug]
+	if !ok {
+		return
+	}
+	last := len(f.targets) - 1
+	f.targets[idx] = f.targets[last]
+	f.position[f.targets[idx]] = idx
+	f.targets = f.targets[:last]
+	delete(f.position, slug)
+	delete(f.sources, slug)
+}
+
+// Add records that source links to each of the missing targets.
+func (f *frontierIndex) Add(source string, missing map[string]struct{}) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.loaded {
+		return
+	}
+	for target := range missing {
+		if _, ok := f.position[target]; !ok {
+			f.position[target] = len(f.targets)
+			f.targets = append(f.targets, target)
+		}
+		f.sources[target] = appendUnique(f.sources[target], source)
+	}
+}
+
+func appendUnique(list []string, value string) []string {
+	for _, existing := range list {
+		if existing == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
+
+func loadFrontier(ctx context.Context, db *sql.DB) (map[string][]string, error) {
+	const query = `SELECT l.target_slug, l.source_slug FROM page_links l
+		LEFT JOIN pages p ON p.slug = l.target_slug
+		LEFT JOIN redirects r ON r.alias_slug = l.target_slug
+		WHERE p.slug IS NULL AND r.alias_slug IS NULL`
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := make(map[string][]string)
+	for rows.Next() {
+		var target, source string
+		if err := rows.Scan(&target, &source); err != nil {
+			return nil, err
+		}
+		entries[target] = append(entries[target], source)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// pickFrontierLink returns a missing slug and an existing page linking to it,
+// dropping entries that another instance has since written.
+func (s *Server) pickFrontierLink(ctx context.Context) (target, source string, err error) {
+	if err := s.frontier.ensure(ctx); err != nil {
+		return "", "", err
+	}
+	for range frontierPickAttempts {
+		target, source, ok := s.frontier.Pick(rand.IntN)
+		if !ok {
+			return "", "", nil
+		}
+		missing, err := s.missingSlugs(ctx, []string{target})
+		if err != nil {
+			return "", "", err
+		}
+		if _, stillMissing := missing[target]; stillMissing {
+			return target, source, nil
+		}
+		s.frontier.Resolve(target)
+	}
+	return "", "", nil
+}
+
+// handleFrontierRandom sends the reader to an existing page, scrolled to one of
+// its links to an unwritten page. Following that link carries the page as
+// origin, so the generation gate in handleWiki lets it through.
+func (s *Server) handleFrontierRandom(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	target, source, err := s.pickFrontierLink(r.Context())
+	if err != nil {
+		log.Printf("frontier pick: %v", err)
+		http.Error(w, "failed to load frontier", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("X-Frontier-Size", strconv.Itoa(s.frontier.Size()))
+	if target == "" {
+		http.Redirect(w, r, s.path("/random"), http.StatusFound)
+		return
+	}
+	http.Redirect(w, r, s.path("/wiki/"+url.PathEscape(source)+"#"+url.PathEscape(missingLinkID(target))), http.StatusFound)
+}
+
+// missingLinkID is the element id given to the first new-page link for slug.
+func missingLinkID(slug string) string {
+	return "new-" + slug
+}
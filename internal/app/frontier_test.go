@@ -0,0 +1,43 @@
+package app
+
+import (
+	"context"
+	"testing"
+)
+
+func TestFrontierIndexTracksMissingTargets(t *testing.T) {
+	index := newFrontierIndex(func(context.Context) (map[string][]string, error) {
+		return map[string][]string{
+			"atlantis": {"oceans"},
+			"lemuria":  {"oceans", "myths"},
+		}, nil
+	})
+	if got := index.Size(); got != -1 {
+		t.Fatalf("size before load = %d, want -1", got)
+	}
+	if err := index.ensure(context.Background()); err != nil {
+		t.Fatalf("ensure: %v", err)
+	}
+
+	index.Resolve("atlantis")
+	index.Add("myths", map[string]struct{}{"mu": {}, "lemuria": {}})
+
+	if got := index.Size(); got != 2 {
+		t.Fatalf("size = %d, want 2", got)
+	}
+	if got := index.sources["lemuria"]; len(got) != 2 {
+		t.Fatalf("lemuria sources = %v, want oceans and myths once each", got)
+	}
+
+	seen := make(map[string]bool)
+	for i := range 2 {
+		target, source, ok := index.Pick(func(n int) int { return min(i, n-1) })
+		if !ok || source == "" {
+			t.Fatalf("Pick returned no entry")
+		}
+		seen[target] = true
+	}
+	if !seen["mu"] || !seen["lemuria"] || seen["atlantis"] {
+		t.Fatalf("unexpected frontier targets %v", seen)
+	}
+}
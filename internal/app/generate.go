@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 )
@@ -14,26 +15,71 @@ import (
 const groqEndpoint = "https://api.groq.com/openai/v1/chat/completions"
 const groqModel = "openai/gpt-oss-120b"
 
+// defaultSetting frames generation prompts unless a world sets its own.
+const defaultSetting = "You are writing for a fictional encyclopedia."
+
+// setting returns the world's description of itself for prompts, asking for
+// the edition's language when it is not English.
+func (cfg Config) setting() string {
+	setting := defaultSetting
+	if cfg.SystemPrompt != "" {
+		setting = cfg.SystemPrompt
+	}
+	if lang := cfg.lang(); lang != defaultLang {
+		setting += fmt.Sprintf(" Write in %s.", languageName(lang))
+	}
+	return setting
+}
+
+func (cfg Config) model() string {
+	if cfg.Model != "" {
+		return cfg.Model
+	}
+	return groqModel
+}
+
 // GeneratePageHTML produces article HTML for a slug, calling Groq when possible.
-func GeneratePageHTML(ctx context.Context, client *http.Client, cfg Config, slug string) (string, error) {
+// The HTML ends with a meta trailer (see splitMetaTrailer) carrying the
+// article's categories. lore lists facts other articles have established about
+// the topic, which the article must not contradict; source, when set, is the
+// same article in another edition to base this one on.
+func GeneratePageHTML(ctx context.Context, client *http.Client, cfg Config, slug string, lore []loreFact, source *translationSource) (string, error) {
 	if cfg.GroqAPIKey == "" {
 		return stubPage(slug), nil
 	}
 
-	payload := groqChatRequest{
-		Model: groqModel,
-		Messages: []groqMessage{
-			{
-				Role:    "system",
-				Content: "You are composing clean HTML for a fictional encyclopedia. Output only valid HTML with a single <h1> title and a <div class=\"endlesswiki-body\"> wrapping the body. Include 3-6 internal links in the body pointing to related topics using <a href=\"/wiki/...\"> text.",
-			},
-			{
-				Role:    "user",
-				Content: fmt.Sprintf("Write a concise Wikipedia-style article about '%s'. Keep to 5 short paragraphs and include an unordered list summarizing key facts.", SlugTitle(slug)),
-			},
+	messages := []groqMessage{
+		{
+			Role:    "system",
+			Content: cfg.setting() + " Compose clean HTML. Output only valid HTML with a single <h1> title and a <div class=\"endlesswiki-body\"> wrapping the body. Include 3-6 internal links in the body pointing to related topics using <a href=\"/wiki/...\"> text. " + metaTrailerInstructions(),
+		},
+		{
+			Role:    "user",
+			Content: fmt.Sprintf("Write a concise Wikipedia-style article about '%s'. Keep to 5 short paragraphs and include an unordered list summarizing key facts.", SlugTitle(slug)) + "\n\n" + loreInstructions(lore) + translationInstructions(cfg, source),
 		},
-		Temperature: 0.7,
-		MaxTokens:   900,
+	}
+
+	content, err := groqComplete(ctx, client, cfg, messages, 0.7, 1000)
+	if err != nil {
+		return "", err
+	}
+
+	content = stripHTMLCodeFence(content)
+	if content == "" {
+		return "", fmt.Errorf("groq response empty")
+	}
+
+	return content, nil
+}
+
+// groqComplete sends one chat completion request and returns the trimmed
+// reply text.
+func groqComplete(ctx context.Context, client *http.Client, cfg Config, messages []groqMessage, temperature float64, maxTokens int) (string, error) {
+	payload := groqChatRequest{
+		Model:       cfg.model(),
+		Messages:    messages,
+		Temperature: temperature,
+		MaxTokens:   maxTokens,
 	}
 
 	buf, err := json.Marshal(payload)
@@ -82,12 +128,6 @@ func GeneratePageHTML(ctx context.Context, client *http.Client, cfg Config, slug
 	if content == "" {
 		return "", fmt.Errorf("groq response empty")
 	}
-
-	content = stripHTMLCodeFence(content)
-	if content == "" {
-		return "", fmt.Errorf("groq response empty")
-	}
-
 	return content, nil
 }
 
@@ -106,14 +146,15 @@ func stubPage(slug string) string {
 	b.WriteString("<ul class=\"endlesswiki-summary\">\n")
 	for _, link := range links {
 		b.WriteString("  <li><a href=\"/wiki/")
-		b.WriteString(link)
+		b.WriteString(url.PathEscape(link))
 		b.WriteString("\">")
 		b.WriteString(templateEscape(SlugTitle(link)))
 		b.WriteString("</a></li>\n")
 	}
 	b.WriteString("</ul>\n")
 	b.WriteString("<p>Use the related links to continue your journey through the infinite encyclopedia.</p>\n")
-	b.WriteString("</div>")
+	b.WriteString("</div>\n")
+	b.WriteString(formatMetaTrailer(pageMeta{Categories: stubCategories}))
 
 	return b.String()
 }
@@ -8,7 +8,7 @@ import (
 
 func TestGeneratePageHTMLWithoutGroqKeyUsesStub(t *testing.T) {
 	cfg := Config{}
-	html, err := GeneratePageHTML(context.Background(), nil, cfg, "test_topic")
+	html, err := GeneratePageHTML(context.Background(), nil, cfg, "test_topic", nil, nil)
 	if err != nil {
 		t.Fatalf("GeneratePageHTML returned error: %v", err)
 	}
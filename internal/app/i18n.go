@@ -0,0 +1,116 @@
+package app
+
+import (
+	"fmt"
+	"strings"
+)
+
+// uiMessages translates the interface strings of the templates, keyed by
+// language and then by the English text. Languages or strings that are
+// missing fall back to English.
+var uiMessages = map[string]map[string]string{
+	"de": {
+		"The infinite encyclopedia. %d pages discovered so far.": "Die unendliche Enzyklopädie. Bisher %d Seiten entdeckt.",
+		"Search EndlessWiki":       "EndlessWiki durchsuchen",
+		"Search":                   "Suchen",
+		"Navigation":               "Navigation",
+		"Main page":                "Hauptseite",
+		"Random page":              "Zufälliger Artikel",
+		"Unexplored link":          "Unerforschter Link",
+		"Most recent":              "Neueste Seite",
+		"Constellation map":        "Sternbildkarte",
+		"Statistics":               "Statistik",
+		"Special pages":            "Spezialseiten",
+		"Languages":                "In anderen Sprachen",
+		"Write this article in %s": "Diesen Artikel auf %s schreiben",
+		"(Redirected from %s)":     "(Weitergeleitet von %s)",
+		"Contents":                 "Inhaltsverzeichnis",
+		"Subtopic (optional)":      "Unterthema (optional)",
+		"Subtopic to expand on":    "Unterthema, das ergänzt werden soll",
+		"Expand this article":      "Diesen Artikel erweitern",
+		"Categories":               "Kategorien",
+		"EndlessWiki pages are generated on demand. Internal links will create new articles when visited.": "EndlessWiki-Seiten werden bei Bedarf erzeugt. Interne Links legen beim Aufruf neue Artikel an.",
+		"This is a read-only snapshot of EndlessWiki.":                                                     "Dies ist eine schreibgeschützte Momentaufnahme von EndlessWiki.",
+		"Built by": "Erstellt von",
+		"This article has not been written yet. Follow the link to generate it.": "Dieser Artikel wurde noch nicht geschrieben. Folge dem Link, um ihn zu erzeugen.",
+		"Discovered":              "Entdeckt am",
+		`Search results for "%s"`: "Suchergebnisse für „%s“",
+		"No results found. You'll have to discover this content via links.": "Keine Ergebnisse. Diese Inhalte musst du über Links entdecken.",
+	},
+	"es": {
+		"The infinite encyclopedia. %d pages discovered so far.": "La enciclopedia infinita. %d páginas descubiertas hasta ahora.",
+		"Search EndlessWiki":       "Buscar en EndlessWiki",
+		"Search":                   "Buscar",
+		"Navigation":               "Navegación",
+		"Main page":                "Portada",
+		"Random page":              "Página aleatoria",
+		"Unexplored link":          "Enlace sin explorar",
+		"Most recent":              "Más reciente",
+		"Constellation map":        "Mapa de constelaciones",
+		"Statistics":               "Estadísticas",
+		"Special pages":            "Páginas especiales",
+		"Languages":                "En otros idiomas",
+		"Write this article in %s": "Escribir este artículo en %s",
+		"(Redirected from %s)":     "(Redirigido desde %s)",
+		"Contents":                 "Índice",
+		"Subtopic (optional)":      "Subtema (opcional)",
+		"Subtopic to expand on":    "Subtema que ampliar",
+		"Expand this article":      "Ampliar este artículo",
+		"Categories":               "Categorías",
+		"EndlessWiki pages are generated on demand. Internal links will create new articles when visited.": "Las páginas de EndlessWiki se generan a demanda. Los enlaces internos crean artículos nuevos al visitarlos.",
+		"This is a read-only snapshot of EndlessWiki.":                                                     "Esta es una copia de solo lectura de EndlessWiki.",
+		"Built by": "Creado por",
+		"This article has not been written yet. Follow the link to generate it.": "Este artículo aún no se ha escrito. Sigue el enlace para generarlo.",
+		"Discovered":              "Descubierto el",
+		`Search results for "%s"`: "Resultados de la búsqueda de «%s»",
+		"No results found. You'll have to discover this content via links.": "No hay resultados. Tendrás que descubrir este contenido a través de los enlaces.",
+	},
+	"fr": {
+		"The infinite encyclopedia. %d pages discovered so far.": "L’encyclopédie infinie. %d pages découvertes jusqu’ici.",
+		"Search EndlessWiki":       "Rechercher dans EndlessWiki",
+		"Search":                   "Rechercher",
+		"Navigation":               "Navigation",
+		"Main page":                "Accueil",
+		"Random page":              "Article au hasard",
+		"Unexplored link":          "Lien inexploré",
+		"Most recent":              "Le plus récent",
+		"Constellation map":        "Carte des constellations",
+		"Statistics":               "Statistiques",
+		"Special pages":            "Pages spéciales",
+		"Languages":                "Dans d’autres langues",
+		"Write this article in %s": "Écrire cet article en %s",
+		"(Redirected from %s)":     "(Redirigé depuis %s)",
+		"Contents":                 "Sommaire",
+		"Subtopic (optional)":      "Sous-thème (facultatif)",
+		"Subtopic to expand on":    "Sous-thème à développer",
+		"Expand this article":      "Développer cet article",
+		"Categories":               "Catégories",
+		"EndlessWiki pages are generated on demand. Internal links will create new articles when visited.": "Les pages d’EndlessWiki sont générées à la demande. Les liens internes créent de nouveaux articles lorsqu’on les suit.",
+		"This is a read-only snapshot of EndlessWiki.":                                                     "Ceci est un instantané en lecture seule d’EndlessWiki.",
+		"Built by": "Créé par",
+		"This article has not been written yet. Follow the link to generate it.": "Cet article n’a pas encore été écrit. Suivez le lien pour le générer.",
+		"Discovered":              "Découvert le",
+		`Search results for "%s"`: "Résultats de recherche pour « %s »",
+		"No results found. You'll have to discover this content via links.": "Aucun résultat. Il faudra découvrir ce contenu en suivant les liens.",
+	},
+}
+
+// translator returns the template function t for lang, which translates an
+// English interface string and formats it with args.
+func translator(lang string) func(msg string, args ...any) string {
+	messages := uiMessages[lang]
+	if messages == nil {
+		// A regional code such as de-ch falls back to de.
+		base, _, _ := strings.Cut(lang, "-")
+		messages = uiMessages[base]
+	}
+	return func(msg string, args ...any) string {
+		if translated, ok := messages[msg]; ok {
+			msg = translated
+		}
+		if len(args) == 0 {
+			return msg
+		}
+		return fmt.Sprintf(msg, args...)
+	}
+}
@@ -0,0 +1,382 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const maxInfoboxValueLen = 200
+
+// Infobox field kinds. Numbers and dates also get a numeric value so facts can
+// be compared; dates store their year, negative for BC.
+const (
+	fieldText   = "text"
+	fieldNumber = "number"
+	fieldDate   = "date"
+	fieldEntity = "entity"
+)
+
+type infoboxField struct {
+	Name  string
+	Label string
+	Kind  string
+}
+
+// infoboxSchema lists the fields an infobox type accepts, in display order.
+type infoboxSchema struct {
+	Type   string
+	Label  string
+	Fields []infoboxField
+}
+
+var infoboxSchemas = []infoboxSchema{
+	{Type: "person", Label: "Person", Fields: []infoboxField{
+		{"born", "Born", fieldDate},
+		{"died", "Died", fieldDate},
+		{"nationality", "Nationality", fieldText},
+		{"occupation", "Occupation", fieldText},
+		{"known_for", "Known for", fieldText},
+		{"spouse", "Spouse", fieldEntity},
+	}},
+	{Type: "city", Label: "City", Fields: []infoboxField{
+		{"country", "Country", fieldEntity},
+		{"founded", "Founded", fieldDate},
+		{"population", "Population", fieldNumber},
+		{"area_km2", "Area (km²)", fieldNumber},
+		{"ruler", "Ruler", fieldEntity},
+	}},
+	{Type: "country", Label: "Country", Fields: []infoboxField{
+		{"capital", "Capital", fieldEntity},
+		{"founded", "Founded", fieldDate},
+		{"dissolved", "Dissolved", fieldDate},
+		{"population", "Population", fieldNumber},
+		{"government", "Government", fieldText},
+		{"currency", "Currency", fieldText},
+		{"language", "Language", fieldText},
+	}},
+	{Type: "organization", Label: "Organization", Fields: []infoboxField{
+		{"founded", "Founded", fieldDate},
+		{"dissolved", "Dissolved", fieldDate},
+		{"founder", "Founder", fieldEntity},
+		{"headquarters", "Headquarters", fieldEntity},
+		{"members", "Members", fieldNumber},
+		{"purpose", "Purpose", fieldText},
+	}},
+	{Type: "event", Label: "Event", Fields: []infoboxField{
+		{"date", "Date", fieldDate},
+		{"location", "Location", fieldEntity},
+		{"participants", "Participants", fieldText},
+		{"outcome", "Outcome", fieldText},
+	}},
+	{Type: "species", Label: "Species", Fields: []infoboxField{
+		{"classification", "Classification", fieldText},
+		{"habitat", "Habitat", fieldText},
+		{"lifespan_years", "Lifespan (years)", fieldNumber},
+		{"discovered", "Discovered", fieldDate},
+	}},
+	{Type: "concept", Label: "Concept", Fields: []infoboxField{
+		{"field", "Field", fieldText},
+		{"introduced", "Introduced", fieldDate},
+		{"originator", "Originator", fieldEntity},
+	}},
+}
+
+func findInfoboxSchema(kind string) (infoboxSchema, bool) {
+	for _, schema := range infoboxSchemas {
+		if schema.Type == kind {
+			return schema, true
+		}
+	}
+	return infoboxSchema{}, false
+}
+
+func (schema infoboxSchema) field(name string) (infoboxField, bool) {
+	for _, f := range schema.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return infoboxField{}, false
+}
+
+// infoboxPromptSpec describes the accepted infobox types for the model.
+func infoboxPromptSpec() string {
+	parts := make([]string, len(infoboxSchemas))
+	for i, schema := range infoboxSchemas {
+		names := make([]string, len(schema.Fields))
+		for j, f := range schema.Fields {
+			names[j] = f.Name
+		}
+		parts[i] = schema.Type + " (" + strings.Join(names, ", ") + ")"
+	}
+	return strings.Join(parts, "; ")
+}
+
+// rawInfobox is the infobox as returned by the model, before validation.
+type rawInfobox struct {
+	Type  string            `json:"type"`
+	Facts map[string]string `json:"facts"`
+}
+
+// infoboxFact is one validated fact. Number is set for number and date
+// fields, Slug for entity fields.
+type infoboxFact struct {
+	Field  string
+	Value  string
+	Number *float64
+	Slug   string
+}
+
+// infobox is a validated infobox with facts in schema order.
+type infobox struct {
+	Type  string
+	Facts []infoboxFact
+}
+
+// validateInfobox checks raw against its type's schema. Unknown fields and
+// values that do not parse as their kind are dropped; an unknown type or an
+// infobox left without facts is an error.
+func validateInfobox(raw *rawInfobox) (*infobox, error) {
+	kind := strings.ToLower(strings.TrimSpace(raw.Type))
+	schema, ok := findInfoboxSchema(kind)
+	if !ok {
+		return nil, fmt.Errorf("unknown infobox type %q", raw.Type)
+	}
+
+	values := make(map[string]string, len(raw.Facts))
+	for key, value := range raw.Facts {
+		name := strings.ReplaceAll(strings.ToLower(strings.TrimSpace(key)), " ", "_")
+		values[name] = cleanTitle(value)
+	}
+
+	box := &infobox{Type: schema.Type}
+	for _, field := range schema.Fields {
+		value := values[field.Name]
+		if value == "" {
+			continue
+		}
+		if runes := []rune(value); len(runes) > maxInfoboxValueLen {
+			value = string(runes[:maxInfoboxValueLen])
+		}
+		fact := infoboxFact{Field: field.Name, Value: value}
+		switch field.Kind {
+		case fieldNumber:
+			n, ok := parseInfoboxNumber(value)
+			if !ok {
+				continue
+			}
+			fact.Number = &n
+		case fieldDate:
+			year, ok := parseInfoboxYear(value)
+			if !ok {
+				continue
+			}
+			fact.Number = &year
+		case fieldEntity:
+			slug, err := NormalizeSlug(value)
+			if err != nil {
+				continue
+			}
+			fact.Slug = slug
+		}
+		box.Facts = append(box.Facts, fact)
+	}
+	if len(box.Facts) == 0 {
+		return nil, errors.New("infobox has no valid facts")
+	}
+	return box, nil
+}
+
+var (
+	infoboxNumberPattern = regexp.MustCompile(`^-?\d[\d,]*(\.\d+)?`)
+	infoboxYearPattern   = regexp.MustCompile(`(?i)^(?:c\.|ca\.|circa)?\s*(\d{1,5})(?:-\d{1,2}-\d{1,2})?\s*(bce|bc|ce|ad)?\b`)
+)
+
+// parseInfoboxNumber reads the leading number of values like "42,000" or
+// "1.5 million". Trailing words are ignored.
+func parseInfoboxNumber(value string) (float64, bool) {
+	match := infoboxNumberPattern.FindString(strings.TrimSpace(value))
+	if match == "" {
+		return 0, false
+	}
+	n, err := strconv.ParseFloat(strings.ReplaceAll(match, ",", ""), 64)
+	return n, err == nil
+}
+
+// parseInfoboxYear reads the year of values like "1203", "1203-05-01",
+// "c. 300 BC", or "AD 44"; BC years are negative.
+func parseInfoboxYear(value string) (float64, bool) {
+	value = strings.TrimSpace(value)
+	if rest, ok := cutPrefixFold(value, "ad "); ok {
+		value = rest + " AD"
+	}
+	m := infoboxYearPattern.FindStringSubmatch(value)
+	if m == nil {
+		return 0, false
+	}
+	year, err := strconv.Atoi(m[1])
+	if err != nil {
+		return 0, false
+	}
+	if era := strings.ToLower(m[2]); era == "bc" || era == "bce" {
+		year = -year
+	}
+	return float64(year), true
+}
+
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix) {
+		return s[len(prefix):], true
+	}
+	return s, false
+}
+
+// replaceInfobox stores the validated infobox for slug, replacing any previous
+// one. A nil infobox just clears the old rows.
+func replaceInfobox(ctx context.Context, db execer, slug string, box *infobox) error {
+	if _, err := db.ExecContext(ctx, `DELETE FROM infobox_facts WHERE page_slug = ?`, slug); err != nil {
+		return err
+	}
+	if _, err := db.ExecContext(ctx, `DELETE FROM infoboxes WHERE page_slug = ?`, slug); err != nil {
+		return err
+	}
+	if box == nil {
+		return nil
+	}
+
+	if _, err := db.ExecContext(ctx, `INSERT INTO infoboxes (page_slug, type) VALUES (?, ?)`, slug, box.Type); err != nil {
+		return err
+	}
+	for i, fact := range box.Facts {
+		var number, target any
+		if fact.Number != nil {
+			number = *fact.Number
+		}
+		if fact.Slug != "" {
+			target = fact.Slug
+		}
+		const insert = `INSERT INTO infobox_facts (page_slug, field, position, value_text, value_number, value_slug)
+			VALUES (?, ?, ?, ?, ?, ?)`
+		if _, err := db.ExecContext(ctx, insert, slug, fact.Field, i, fact.Value, number, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadInfobox returns the stored infobox for slug, or nil when it has none.
+func loadInfobox(ctx context.Context, db *sql.DB, slug string) (*infobox, error) {
+	box := &infobox{}
+	err := db.QueryRowContext(ctx, `SELECT type FROM infoboxes WHERE page_slug = ?`, slug).Scan(&box.Type)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	facts, err := loadInfoboxFacts(ctx, db, []string{slug})
+	if err != nil {
+		return nil, err
+	}
+	box.Facts = facts[slug]
+	return box, nil
+}
+
+// loadInfoboxFacts returns the facts of each listed page in display order.
+func loadInfoboxFacts(ctx context.Context, db *sql.DB, slugs []string) (map[string][]infoboxFact, error) {
+	if len(slugs) == 0 {
+		return nil, nil
+	}
+	placeholders := make([]string, len(slugs))
+	args := make([]any, len(slugs))
+	for i, slug := range slugs {
+		placeholders[i] = "?"
+		args[i] = slug
+	}
+	query := `SELECT page_slug, field, value_text, value_number, value_slug FROM infobox_facts
+		WHERE page_slug IN (` + strings.Join(placeholders, ",") + `)
+		ORDER BY page_slug, position`
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	facts := make(map[string][]infoboxFact, len(slugs))
+	for rows.Next() {
+		var slug string
+		var fact infoboxFact
+		var number sql.NullFloat64
+		var target sql.NullString
+		if err := rows.Scan(&slug, &fact.Field, &fact.Value, &number, &target); err != nil {
+			return nil, err
+		}
+		if number.Valid {
+			fact.Number = &number.Float64
+		}
+		fact.Slug = target.String
+		facts[slug] = append(facts[slug], fact)
+	}
+	return facts, rows.Err()
+}
+
+// entitySlugs lists the pages the infobox refers to.
+func (box *infobox) entitySlugs() []string {
+	if box == nil {
+		return nil
+	}
+	var slugs []string
+	for _, fact := range box.Facts {
+		if fact.Slug != "" {
+			slugs = append(slugs, fact.Slug)
+		}
+	}
+	return slugs
+}
+
+// infoboxView is what the infobox template renders.
+type infoboxView struct {
+	Type      string
+	TypeLabel string
+	Title     string
+	Rows      []infoboxRow
+}
+
+type infoboxRow struct {
+	Label string
+	Value string
+	Href  string
+}
+
+// view prepares the infobox for display. Entity facts link to their page only
+// when it exists, since the generation gate only accepts links that appear in
+// the article body.
+func (box *infobox) view(title string, missing map[string]struct{}) *infoboxView {
+	if box == nil {
+		return nil
+	}
+	schema, ok := findInfoboxSchema(box.Type)
+	if !ok {
+		return nil
+	}
+	v := &infoboxView{Type: box.Type, TypeLabel: schema.Label, Title: title}
+	for _, fact := range box.Facts {
+		field, ok := schema.field(fact.Field)
+		if !ok {
+			continue
+		}
+		row := infoboxRow{Label: field.Label, Value: fact.Value}
+		if _, isMissing := missing[fact.Slug]; fact.Slug != "" && !isMissing {
+			row.Href = "/wiki/" + url.PathEscape(fact.Slug)
+		}
+		v.Rows = append(v.Rows, row)
+	}
+	return v
+}
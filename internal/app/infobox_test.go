@@ -0,0 +1,87 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateInfobox(t *testing.T) {
+	box, err := validateInfobox(&rawInfobox{
+		Type: "City",
+		Facts: map[string]string{
+			"Founded":    "c. 1203",
+			"population": "42,000 inhabitants",
+			"country":    "Kingdom of Veldor",
+			"area_km2":   "unknown",
+			"mascot":     "A heron",
+		},
+	})
+	if err != nil {
+		t.Fatalf("validateInfobox: %v", err)
+	}
+	if box.Type != "city" || len(box.Facts) != 3 {
+		t.Fatalf("box = %+v", box)
+	}
+	country, founded, population := box.Facts[0], box.Facts[1], box.Facts[2]
+	if country.Field != "country" || country.Slug != "kingdom_of_veldor" {
+		t.Errorf("country fact = %+v", country)
+	}
+	if founded.Field != "founded" || founded.Number == nil || *founded.Number != 1203 {
+		t.Errorf("founded fact = %+v", founded)
+	}
+	if population.Number == nil || *population.Number != 42000 {
+		t.Errorf("population fact = %+v", population)
+	}
+}
+
+func TestValidateInfoboxRejectsUnknownTypeAndEmptyFacts(t *testing.T) {
+	if _, err := validateInfobox(&rawInfobox{Type: "spaceship", Facts: map[string]string{"crew": "4"}}); err == nil {
+		t.Fatalf("expected error for unknown type")
+	}
+	if _, err := validateInfobox(&rawInfobox{Type: "person", Facts: map[string]string{"born": "long ago"}}); err == nil {
+		t.Fatalf("expected error for infobox without valid facts")
+	}
+}
+
+func TestParseInfoboxYear(t *testing.T) {
+	cases := map[string]float64{
+		"1203":       1203,
+		"1203-05-01": 1203,
+		"c. 300 BC":  -300,
+		"44 BCE":     -44,
+		"AD 44":      44,
+		"circa 950":  950,
+	}
+	for input, want := range cases {
+		if got, ok := parseInfoboxYear(input); !ok || got != want {
+			t.Errorf("parseInfoboxYear(%q) = %v, %v; want %v", input, got, ok, want)
+		}
+	}
+	if _, ok := parseInfoboxYear("the third age"); ok {
+		t.Errorf("parseInfoboxYear accepted text")
+	}
+}
+
+func TestInfoboxTemplateLinksExistingEntities(t *testing.T) {
+	srv, err := NewServer(nil, Config{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	box := &infobox{Type: "city", Facts: []infoboxFact{
+		{Field: "country", Value: "Veldor", Slug: "veldor"},
+		{Field: "ruler", Value: "Queen Ama", Slug: "queen_ama"},
+	}}
+	view := box.view("Port Ama", map[string]struct{}{"queen_ama": {}})
+
+	var b strings.Builder
+	if err := srv.templates.ExecuteTemplate(&b, "infobox", view); err != nil {
+		t.Fatalf("render infobox: %v", err)
+	}
+	out := b.String()
+	if !strings.Contains(out, `<a href="/wiki/veldor">Veldor</a>`) {
+		t.Fatalf("existing entity not linked: %s", out)
+	}
+	if strings.Contains(out, "/wiki/queen_ama") || !strings.Contains(out, "Queen Ama") {
+		t.Fatalf("missing entity should render as text: %s", out)
+	}
+}
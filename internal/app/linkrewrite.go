@@ -1,125 +1,258 @@
 package app
 
 import (
-	"fmt"
 	"html"
 	"net/url"
-	"regexp"
-	"sort"
 	"strings"
+
+	nethtml "golang.org/x/net/html"
 )
 
-var doubleQuoteHref = regexp.MustCompile(`href="(/wiki/[^"]+)"`)
-var singleQuoteHref = regexp.MustCompile(`href='(/wiki/[^']+)'`)
-var wikiHref = regexp.MustCompile(`href=['"](/wiki/[^'"#?]+(?:[^'" ]*)?)['"]`)
-var anchorTag = regexp.MustCompile(`(?i)<a\b([^>]*?)href=(['"])(/wiki/[^'"#?]+(?:[^'" ]*)?)(['"])([^>]*)>`)
+// linkWalker streams HTML through a tokenizer, collecting the wiki slugs it
+// links to and, when rewrite is set, decorating /wiki/ anchors in the same
+// pass. Tokens that are not rewritten are copied byte-for-byte, so markup the
+// walker does not understand passes through untouched.
+type linkWalker struct {
+	origin  string
+	missing map[string]struct{}
+	rewrite bool
+	// base prefixes rewritten links for a wiki served below the root.
+	base string
+	// static rewrites links for an export: existing pages become relative
+	// links to slug+suffix and missing ones inert spans. entries names the
+	// entry a slug is exported as when that is not the slug itself.
+	static  bool
+	suffix  string
+	entries map[string]string
+}
+
+// walk returns the (possibly rewritten) content and the distinct slugs linked
+// from it, in document order.
+func (lw linkWalker) walk(content string) (string, []string) {
+	z := nethtml.NewTokenizer(strings.NewReader(content))
 
-func decorateInternalLinks(content, origin string, missing map[string]struct{}) string {
-	if origin != "" {
-		content = addOriginParam(content, origin)
+	var b strings.Builder
+	emit := func(s string) {
+		if lw.rewrite {
+			b.WriteString(s)
+		}
 	}
-	if len(missing) > 0 {
-		content = markMissingLinks(content, missing)
+	if lw.rewrite {
+		b.Grow(len(content) + len(content)/8)
 	}
-	return content
-}
 
-func addOriginParam(content, origin string) string {
-	rewrite := func(match string, re *regexp.Regexp) string {
-		sub := re.FindStringSubmatch(match)
-		if len(sub) != 2 {
-			return match
-		}
-		href := sub[1]
-		if strings.Contains(href, "origin=") {
-			return match
-		}
+	var slugs []string
+	seen := make(map[string]struct{})
+	marked := make(map[string]struct{})
+	// anchors records, for every open <a>, whether it was turned into a span.
+	var anchors []bool
 
-		href = injectOrigin(href, origin)
-		if re == doubleQuoteHref {
-			return fmt.Sprintf("href=\"%s\"", href)
+	for {
+		tt := z.Next()
+		// Raw must be copied before TagName/TagAttr, which lower-case in place.
+		raw := string(z.Raw())
+		if tt == nethtml.ErrorToken {
+			emit(raw)
+			break
 		}
-		return fmt.Sprintf("href='%s'", href)
-	}
 
-	content = doubleQuoteHref.ReplaceAllStringFunc(content, func(s string) string {
-		return rewrite(s, doubleQuoteHref)
-	})
+		switch tt {
+		case nethtml.StartTagToken, nethtml.SelfClosingTagToken:
+			name, hasAttr := z.TagName()
+			if string(name) != "a" {
+				emit(raw)
+				continue
+			}
+			// Every <a> is tracked, even a bare one, so its </a> closes it
+			// and not the anchor around it.
+			var attrs []nethtml.Attribute
+			if hasAttr {
+				attrs = readAttrs(z)
+			}
+			href, hrefIdx := wikiHrefAttr(attrs)
+			if hrefIdx == -1 {
+				if tt == nethtml.StartTagToken {
+					anchors = append(anchors, false)
+				}
+				emit(raw)
+				continue
+			}
 
-	content = singleQuoteHref.ReplaceAllStringFunc(content, func(s string) string {
-		return rewrite(s, singleQuoteHref)
-	})
+			slug := slugFromHref(href)
+			if slug != "" {
+				if _, ok := seen[slug]; !ok {
+					seen[slug] = struct{}{}
+					slugs = append(slugs, slug)
+				}
+			}
+			if !lw.rewrite {
+				continue
+			}
 
-	return content
-}
+			if lw.static {
+				if _, isMissing := lw.missing[slug]; slug == "" || isMissing {
+					if tt == nethtml.StartTagToken {
+						anchors = append(anchors, true)
+						emit(`<span class="missing-link">`)
+					}
+					continue
+				}
+				if tt == nethtml.StartTagToken {
+					anchors = append(anchors, false)
+				}
+				if entry, ok := lw.entries[slug]; ok {
+					slug = entry
+				}
+				attrs[hrefIdx].Val = exportHref(href, slug, lw.suffix)
+				writeTag(&b, "a", attrs, tt == nethtml.SelfClosingTagToken)
+				continue
+			}
 
-func markMissingLinks(content string, missing map[string]struct{}) string {
-	matches := anchorTag.FindAllStringSubmatchIndex(content, -1)
-	if len(matches) == 0 {
-		return content
+			if lw.origin != "" && !strings.Contains(href, "origin=") {
+				href = injectOrigin(href, lw.origin)
+			}
+			href = lw.base + href
+			_, isMissing := lw.missing[slug]
+			if slug != "" && isMissing && tt == nethtml.StartTagToken {
+				anchors = append(anchors, true)
+				id := ""
+				if _, ok := marked[slug]; !ok {
+					marked[slug] = struct{}{}
+					id = missingLinkID(slug)
+				}
+				emit(missingLinkSpanOpen(href, id))
+				continue
+			}
+			if tt == nethtml.StartTagToken {
+				anchors = append(anchors, false)
+			}
+			if href == attrs[hrefIdx].Val {
+				emit(raw)
+				continue
+			}
+			attrs[hrefIdx].Val = href
+			writeTag(&b, "a", attrs, tt == nethtml.SelfClosingTagToken)
+		case nethtml.EndTagToken:
+			name, _ := z.TagName()
+			if string(name) == "a" && len(anchors) > 0 {
+				converted := anchors[len(anchors)-1]
+				anchors = anchors[:len(anchors)-1]
+				if converted {
+					emit("</span>")
+					continue
+				}
+			}
+			emit(raw)
+		default:
+			emit(raw)
+		}
 	}
 
-	type replacement struct {
-		start int
-		end   int
-		text  string
+	if !lw.rewrite {
+		return content, slugs
 	}
+	return b.String(), slugs
+}
 
-	replacements := make([]replacement, 0, len(matches))
-	for _, loc := range matches {
-		tagStart := loc[0]
-		tagEnd := loc[1]
-		hrefStart := loc[6]
-		hrefEnd := loc[7]
-		hrefValue := content[hrefStart:hrefEnd]
-		slug := slugFromHref(hrefValue)
-		if slug == "" {
-			continue
-		}
-		if _, ok := missing[slug]; !ok {
-			continue
+func readAttrs(z *nethtml.Tokenizer) []nethtml.Attribute {
+	var attrs []nethtml.Attribute
+	for {
+		key, val, more := z.TagAttr()
+		attrs = append(attrs, nethtml.Attribute{Key: string(key), Val: string(val)})
+		if !more {
+			return attrs
 		}
+	}
+}
 
-		restLower := strings.ToLower(content[tagEnd:])
-		closingIdx := strings.Index(restLower, "</a>")
-		if closingIdx == -1 {
+// wikiHrefAttr returns the first href attribute pointing at /wiki/ and its
+// index, or -1 when the anchor is not an internal wiki link.
+func wikiHrefAttr(attrs []nethtml.Attribute) (string, int) {
+	for i, attr := range attrs {
+		if attr.Key != "href" {
 			continue
 		}
-		anchorEnd := tagEnd + closingIdx + len("</a>")
-		inner := content[tagEnd : anchorEnd-len("</a>")]
-		span := buildMissingLinkSpan(hrefValue, inner)
-		replacements = append(replacements, replacement{start: tagStart, end: anchorEnd, text: span})
+		href := strings.TrimSpace(attr.Val)
+		if !strings.HasPrefix(href, "/wiki/") {
+			return "", -1
+		}
+		return href, i
 	}
+	return "", -1
+}
+
+func writeTag(b *strings.Builder, name string, attrs []nethtml.Attribute, selfClosing bool) {
+	b.WriteString("<")
+	b.WriteString(name)
+	for _, attr := range attrs {
+		b.WriteString(" ")
+		b.WriteString(attr.Key)
+		b.WriteString(`="`)
+		b.WriteString(html.EscapeString(attr.Val))
+		b.WriteString(`"`)
+	}
+	if selfClosing {
+		b.WriteString("/")
+	}
+	b.WriteString(">")
+}
+
+func decorateInternalLinks(content, origin string, missing map[string]struct{}) string {
+	return decorateInternalLinksUnder("", content, origin, missing)
+}
 
-	if len(replacements) == 0 {
+// decorateInternalLinksUnder decorates links for a wiki served below base,
+// e.g. "/w/scifi". Stored content always links to /wiki/ at the root.
+func decorateInternalLinksUnder(base, content, origin string, missing map[string]struct{}) string {
+	if base == "" && origin == "" && len(missing) == 0 {
 		return content
 	}
+	decorated, _ := linkWalker{origin: origin, missing: missing, rewrite: true, base: base}.walk(content)
+	return decorated
+}
 
-	sort.Slice(replacements, func(i, j int) bool {
-		return replacements[i].start < replacements[j].start
-	})
+// staticLinks rewrites the links of content for a static export, where pages
+// are files next to each other.
+func staticLinks(content string, missing map[string]struct{}) string {
+	return exportLinks(content, missing, nil, ".html")
+}
 
-	var b strings.Builder
-	last := 0
-	for _, rep := range replacements {
-		if rep.start < last {
-			continue
-		}
-		b.WriteString(content[last:rep.start])
-		b.WriteString(rep.text)
-		last = rep.end
+// exportLinks rewrites the links of content for an export in which every page
+// is an entry named slug+suffix beside the others. Links to a slug in entries
+// point at the entry it maps to instead.
+func exportLinks(content string, missing map[string]struct{}, entries map[string]string, suffix string) string {
+	rewritten, _ := linkWalker{missing: missing, rewrite: true, static: true, suffix: suffix, entries: entries}.walk(content)
+	return rewritten
+}
+
+// staticHref is the relative file a link to slug points at in a static
+// export, keeping its fragment.
+func staticHref(href, slug string) string {
+	return exportHref(href, slug, ".html")
+}
+
+func exportHref(href, slug, suffix string) string {
+	fragment := ""
+	if idx := strings.Index(href, "#"); idx >= 0 {
+		fragment = href[idx:]
 	}
-	b.WriteString(content[last:])
-	return b.String()
+	return url.PathEscape(slug) + suffix + fragment
 }
 
-func buildMissingLinkSpan(href, inner string) string {
+// missingLinkSpanOpen starts the span that replaces an anchor to a missing
+// page. The first span for each slug also carries an id so /frontier/random
+// can scroll to it.
+func missingLinkSpanOpen(href, id string) string {
 	var b strings.Builder
-	b.WriteString(`<span class="new-page-link" role="link" tabindex="0" data-href="`)
+	b.WriteString(`<span class="new-page-link" role="link" tabindex="0" `)
+	if id != "" {
+		b.WriteString(`id="`)
+		b.WriteString(html.EscapeString(id))
+		b.WriteString(`" `)
+	}
+	b.WriteString(`data-href="`)
 	b.WriteString(html.EscapeString(href))
 	b.WriteString(`">`)
-	b.WriteString(inner)
-	b.WriteString(`</span>`)
 	return b.String()
 }
 
@@ -131,9 +264,9 @@ func injectOrigin(href, origin string) string {
 	}
 
 	if strings.Contains(href, "?") {
-		href = href + "&origin=" + origin
+		href = href + "&origin=" + url.QueryEscape(origin)
 	} else {
-		href = href + "?origin=" + origin
+		href = href + "?origin=" + url.QueryEscape(origin)
 	}
 
 	return href + fragment
@@ -151,31 +284,8 @@ func hasLinkTo(content, target string) bool {
 
 // ExtractLinkedSlugs returns normalised wiki slugs referenced within HTML content.
 func ExtractLinkedSlugs(content string) []string {
-	matches := wikiHref.FindAllStringSubmatch(content, -1)
-	if len(matches) == 0 {
-		return nil
-	}
-
-	seen := make(map[string]struct{})
-	for _, match := range matches {
-		if len(match) < 2 {
-			continue
-		}
-		href := match[1]
-		slug := slugFromHref(href)
-		if slug == "" {
-			continue
-		}
-		if _, ok := seen[slug]; !ok {
-			seen[slug] = struct{}{}
-		}
-	}
-
-	result := make([]string, 0, len(seen))
-	for slug := range seen {
-		result = append(result, slug)
-	}
-	return result
+	_, slugs := linkWalker{}.walk(content)
+	return slugs
 }
 
 func slugFromHref(href string) string {
@@ -0,0 +1,86 @@
+package app
+
+import (
+	"strings"
+	"testing"
+
+	nethtml "golang.org/x/net/html"
+)
+
+// FuzzDecorateInternalLinks checks that decoration only ever rewrites anchor
+// tags: the text and the sequence of non-anchor tags must survive unchanged,
+// and no link may appear that was not in the input.
+func FuzzDecorateInternalLinks(f *testing.F) {
+	f.Add(`<p><a href="/wiki/made_up">New</a> and <a href="/wiki/existing">Old</a></p>`, "source_page")
+	f.Add(`<A HREF=/wiki/x?y=1#z><i>x</i></A>`, "origin")
+	f.Add(`<a href="/wiki/odd"><a href="/wiki/even">nested</a></a>`, "o")
+
+	f.Fuzz(func(t *testing.T, content, origin string) {
+		if normalized, err := NormalizeSlug(origin); err == nil {
+			origin = normalized
+		} else {
+			origin = ""
+		}
+
+		missing := make(map[string]struct{})
+		for _, slug := range ExtractLinkedSlugs(content) {
+			if len(slug)%2 == 1 {
+				missing[slug] = struct{}{}
+			}
+		}
+
+		out := decorateInternalLinks(content, origin, missing)
+
+		before, after := markupSkeleton(content), markupSkeleton(out)
+		if before != after {
+			t.Fatalf("markup changed\n in: %q\nout: %q\nskeleton before %q\nskeleton after  %q", content, out, before, after)
+		}
+
+		inLinks := make(map[string]struct{})
+		for _, slug := range ExtractLinkedSlugs(content) {
+			inLinks[slug] = struct{}{}
+		}
+		for _, slug := range ExtractLinkedSlugs(out) {
+			if _, ok := inLinks[slug]; !ok {
+				t.Fatalf("decoration introduced link to %q\n in: %q\nout: %q", slug, content, out)
+			}
+		}
+	})
+}
+
+// markupSkeleton flattens content into its token stream. Anchors and spans are
+// folded into one attribute-free placeholder, since decoration swaps one for
+// the other and rewrites their attributes.
+func markupSkeleton(content string) string {
+	z := nethtml.NewTokenizer(strings.NewReader(content))
+	var b strings.Builder
+	for {
+		tt := z.Next()
+		switch tt {
+		case nethtml.ErrorToken:
+			b.WriteString("EOF:")
+			b.Write(z.Raw())
+			return b.String()
+		case nethtml.StartTagToken, nethtml.SelfClosingTagToken, nethtml.EndTagToken:
+			tok := z.Token()
+			name := tok.Data
+			if name == "a" || name == "span" {
+				name = "link"
+			}
+			b.WriteString(tt.String())
+			b.WriteString(":")
+			b.WriteString(name)
+			if name != "link" {
+				for _, attr := range tok.Attr {
+					b.WriteString(" " + attr.Key + "=" + attr.Val)
+				}
+			}
+			b.WriteString("|")
+		default:
+			b.WriteString(tt.String())
+			b.WriteString(":")
+			b.Write(z.Raw())
+			b.WriteString("|")
+		}
+	}
+}
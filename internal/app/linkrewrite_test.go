@@ -28,3 +28,81 @@ func TestDecorateInternalLinksConvertsMissingAnchors(t *testing.T) {
 func contains(haystack, needle string) bool {
 	return strings.Contains(haystack, needle)
 }
+
+func TestDecorateInternalLinksHandlesUnquotedAndUppercaseHrefs(t *testing.T) {
+	content := `<p><A HREF=/wiki/made_up>New <b>bold</b></A> and <a href='/wiki/existing#history'>Old</a></p>`
+	missing := map[string]struct{}{"made_up": {}}
+
+	result := decorateInternalLinks(content, "source_page", missing)
+
+	want := `<p><span class="new-page-link" role="link" tabindex="0" id="new-made_up" data-href="/wiki/made_up?origin=source_page">New <b>bold</b></span> and <a href="/wiki/existing?origin=source_page#history">Old</a></p>`
+	if result != want {
+		t.Fatalf("decorateInternalLinks:\n got %s\nwant %s", result, want)
+	}
+}
+
+func TestDecorateInternalLinksLeavesCodeUntouched(t *testing.T) {
+	content := `<p>Write <code>&lt;a href="/wiki/made_up"&gt;</code> or <code>href="/wiki/made_up"</code>.</p>`
+	missing := map[string]struct{}{"made_up": {}}
+
+	if result := decorateInternalLinks(content, "source_page", missing); result != content {
+		t.Fatalf("code content was rewritten: %s", result)
+	}
+	if slugs := ExtractLinkedSlugs(content); len(slugs) != 0 {
+		t.Fatalf("ExtractLinkedSlugs found links inside code: %v", slugs)
+	}
+}
+
+func TestExtractLinkedSlugsDocumentOrder(t *testing.T) {
+	content := `<a href="/wiki/Beta?origin=x">b</a><a HREF=/wiki/alpha>a</a><a href="/wiki/beta">again</a><a href="https://example.com/wiki/gamma">ext</a>`
+
+	got := ExtractLinkedSlugs(content)
+	want := []string{"beta", "alpha"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("ExtractLinkedSlugs = %v, want %v", got, want)
+	}
+}
+
+func TestUnicodeLinksAreNormalizedAndOriginEscaped(t *testing.T) {
+	content := `<a href="/wiki/%D0%9C%D0%BE%D1%81%D0%BA%D0%B2%D0%B0">Moscow</a><a href="/wiki/東京">Tokyo</a>`
+	slugs := ExtractLinkedSlugs(content)
+	if len(slugs) != 2 || slugs[0] != "москва" || slugs[1] != "東京" {
+		t.Fatalf("ExtractLinkedSlugs = %v", slugs)
+	}
+
+	result := decorateInternalLinks(`<a href="/wiki/東京">Tokyo</a>`, "москва", nil)
+	if !contains(result, `href="/wiki/東京?origin=%D0%BC%D0%BE%D1%81%D0%BA%D0%B2%D0%B0"`) {
+		t.Fatalf("origin not query-escaped: %s", result)
+	}
+}
+
+func TestDecorateInternalLinksTracksBareAnchors(t *testing.T) {
+	content := `<a href="/wiki/made_up">New <a>bare</a> tail</a> after`
+	missing := map[string]struct{}{"made_up": {}}
+
+	result := decorateInternalLinks(content, "", missing)
+
+	want := `<span class="new-page-link" role="link" tabindex="0" id="new-made_up" data-href="/wiki/made_up">New <a>bare</a> tail</span> after`
+	if result != want {
+		t.Fatalf("decorateInternalLinks:\n got %s\nwant %s", result, want)
+	}
+}
+
+func TestExportLinks(t *testing.T) {
+	missing := map[string]struct{}{"carthage": {}}
+	content := `<p><a href="/wiki/Rome#history" title="Rome">Rome</a>, <A HREF=/wiki/carthage>Carthage</A>, ` +
+		`<a href="/wiki/%E6%9D%B1%E4%BA%AC">Tokyo</a>, <a href="/wiki/roma">Roma</a>, <a href="https://example.com/">out</a>, <a href="#notes">notes</a></p>`
+	got := exportLinks(content, missing, map[string]string{"roma": "rome"}, "")
+	for _, want := range []string{
+		`<a href="rome#history" title="Rome">Rome</a>`,
+		`<span class="missing-link">Carthage</span>`,
+		`<a href="%E6%9D%B1%E4%BA%AC">Tokyo</a>`,
+		`<a href="rome">Roma</a>`,
+		`<a href="https://example.com/">out</a>`,
+		`<a href="#notes">notes</a>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %s:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+)
+
+const pageBatchSize = 200
+
+// execer is satisfied by both *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// replacePageLinks rewrites the page_links rows for source to match content.
+func replacePageLinks(ctx context.Context, db execer, source, content string) error {
+	if _, err := db.ExecContext(ctx, `DELETE FROM page_links WHERE source_slug = ?`, source); err != nil {
+		return err
+	}
+
+	var targets []string
+	for _, target := range ExtractLinkedSlugs(content) {
+		if target != source {
+			targets = append(targets, target)
+		}
+	}
+	if len(targets) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, len(targets))
+	args := make([]any, 0, len(targets)*2)
+	for i, target := range targets {
+		placeholders[i] = "(?, ?)"
+		args = append(args, source, target)
+	}
+	query := "INSERT INTO page_links (source_slug, target_slug) VALUES " + strings.Join(placeholders, ",")
+	_, err := db.ExecContext(ctx, query, args...)
+	return err
+}
+
+// updatePageContent replaces a page's content and its link rows atomically.
+func updatePageContent(ctx context.Context, db *sql.DB, slug, content string) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `UPDATE pages SET content = ? WHERE slug = ?`, content, slug); err != nil {
+		return err
+	}
+	if err := replacePageLinks(ctx, tx, slug, content); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// BackfillLinks rebuilds page_links from the content of every stored page and
+// returns the number of pages processed.
+func BackfillLinks(ctx context.Context, db *sql.DB) (int, error) {
+	return eachPage(ctx, db, func(p Page) error {
+		return replacePageLinks(ctx, db, p.Slug, p.Content)
+	})
+}
+
+// eachPage calls fn for every stored page in slug order, reading them in
+// batches so fn may write to the database between reads. It returns the
+// number of pages fn accepted.
+func eachPage(ctx context.Context, db *sql.DB, fn func(Page) error) (int, error) {
+	processed := 0
+	after := ""
+	for {
+		rows, err := db.QueryContext(ctx, `SELECT slug, content FROM pages WHERE slug > ? ORDER BY slug LIMIT ?`, after, pageBatchSize)
+		if err != nil {
+			return processed, err
+		}
+		var batch []Page
+		for rows.Next() {
+			var p Page
+			if err := rows.Scan(&p.Slug, &p.Content); err != nil {
+				rows.Close()
+				return processed, err
+			}
+			batch = append(batch, p)
+		}
+		if err := rows.Err(); err != nil {
+			rows.Close()
+			return processed, err
+		}
+		rows.Close()
+
+		if len(batch) == 0 {
+			return processed, nil
+		}
+		for _, p := range batch {
+			if err := fn(p); err != nil {
+				return processed, err
+			}
+			processed++
+		}
+		after = batch[len(batch)-1].Slug
+	}
+}
@@ -0,0 +1,266 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+const (
+	// maxPageFacts bounds the facts stored for one article, infobox included.
+	maxPageFacts = 20
+	// maxLoreFactsInPrompt bounds the established facts sent with a generation.
+	maxLoreFactsInPrompt = 20
+	maxLoreAttributeLen  = 64
+)
+
+// loreClaim is a fact as listed in the meta trailer.
+type loreClaim struct {
+	Subject   string `json:"subject"`
+	Attribute string `json:"attribute"`
+	Value     string `json:"value"`
+}
+
+// loreFact is a normalised claim made by the article Source about Subject.
+// Key is the value reduced for comparison, so "1203" and "c. 1203 AD" agree.
+type loreFact struct {
+	Source    string
+	Subject   string
+	Attribute string
+	Value     string
+	Key       string
+}
+
+var loreAttributePattern = regexp.MustCompile(`^[a-z0-9_]+$`)
+
+// normalizeLoreFacts turns the infobox and trailer claims of the article slug
+// into facts. Infobox facts describe the article's own subject and come first;
+// only the first value for each subject and attribute is kept.
+func normalizeLoreFacts(slug string, box *infobox, claims []loreClaim) []loreFact {
+	var facts []loreFact
+	seen := make(map[string]struct{})
+	add := func(subject, attribute, value string) {
+		if len(facts) >= maxPageFacts {
+			return
+		}
+		attribute = strings.ReplaceAll(strings.ToLower(strings.Join(strings.Fields(attribute), "_")), "-", "_")
+		if len(attribute) > maxLoreAttributeLen || !loreAttributePattern.MatchString(attribute) {
+			return
+		}
+		value = cleanTitle(value)
+		if runes := []rune(value); len(runes) > maxTitleLen {
+			value = string(runes[:maxTitleLen])
+		}
+		key := loreValueKey(value)
+		if key == "" {
+			return
+		}
+		if _, dup := seen[subject+"\x00"+attribute]; dup {
+			return
+		}
+		seen[subject+"\x00"+attribute] = struct{}{}
+		facts = append(facts, loreFact{Source: slug, Subject: subject, Attribute: attribute, Value: value, Key: key})
+	}
+
+	if box != nil {
+		for _, fact := range box.Facts {
+			add(slug, fact.Field, fact.Value)
+		}
+	}
+	for _, claim := range claims {
+		subject, err := NormalizeSlug(claim.Subject)
+		if err != nil {
+			continue
+		}
+		add(subject, claim.Attribute, claim.Value)
+	}
+	return facts
+}
+
+var loreYearPattern = regexp.MustCompile(`(?i)^(?:(?:c\.|ca\.|circa)\s*)?(?:ad\s+)?\d{1,5}(?:-\d{1,2}-\d{1,2})?(?:\s*(?:bce|bc|ce|ad))?$`)
+
+// loreValueKey reduces a value for comparison: bare years become "year:N"
+// (negative for BC), anything else its lower-cased words joined by
+// underscores.
+func loreValueKey(value string) string {
+	value = strings.TrimSpace(value)
+	if loreYearPattern.MatchString(value) {
+		if year, ok := parseInfoboxYear(value); ok {
+			return fmt.Sprintf("year:%d", int(year))
+		}
+	}
+	words := strings.FieldsFunc(strings.ToLower(stripDiacritics(value)), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	key := strings.Join(words, "_")
+	if runes := []rune(key); len(runes) > maxTitleLen {
+		key = string(runes[:maxTitleLen])
+	}
+	return key
+}
+
+// replacePageFacts rewrites the facts stated by the article slug. Subjects
+// that are aliases are stored under their canonical page.
+func replacePageFacts(ctx context.Context, db execer, slug string, facts []loreFact) error {
+	if _, err := db.ExecContext(ctx, `DELETE FROM page_facts WHERE page_slug = ?`, slug); err != nil {
+		return err
+	}
+	for _, fact := range facts {
+		const insert = `INSERT INTO page_facts (page_slug, subject_slug, attribute, value, value_key)
+			SELECT ?, COALESCE((SELECT target_slug FROM redirects WHERE alias_slug = ?), ?), ?, ?, ?`
+		if _, err := db.ExecContext(ctx, insert, slug, fact.Subject, fact.Subject, fact.Attribute, fact.Value, fact.Key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// repointLoreFacts moves facts about from onto into, when from becomes an
+// alias of into.
+func repointLoreFacts(ctx context.Context, db execer, from, into string) error {
+	_, err := db.ExecContext(ctx, `UPDATE page_facts SET subject_slug = ? WHERE subject_slug = ?`, into, from)
+	return err
+}
+
+// loadLoreFacts returns what other articles claim about subject.
+func loadLoreFacts(ctx context.Context, db *sql.DB, subject string, limit int) ([]loreFact, error) {
+	const query = `SELECT f.page_slug, COALESCE(p.title, ''), f.attribute, f.value, f.value_key FROM page_facts f
+		LEFT JOIN pages p ON p.slug = f.page_slug
+		WHERE f.subject_slug = ? AND f.page_slug <> ?
+		ORDER BY f.attribute, f.page_slug
+		LIMIT ?`
+	rows, err := db.QueryContext(ctx, query, subject, subject, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var facts []loreFact
+	for rows.Next() {
+		fact := loreFact{Subject: subject}
+		var title string
+		if err := rows.Scan(&fact.Source, &title, &fact.Attribute, &fact.Value, &fact.Key); err != nil {
+			return nil, err
+		}
+		fact.Source = DisplayTitle(fact.Source, title)
+		facts = append(facts, fact)
+	}
+	return facts, rows.Err()
+}
+
+// loreInstructions lists established facts for the generation prompt, or
+// returns "" when there are none.
+func loreInstructions(facts []loreFact) string {
+	if len(facts) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("Other articles already establish these facts about this topic. Stay consistent with them:\n")
+	for _, fact := range facts {
+		fmt.Fprintf(&b, "- %s: %s (from the article %q)\n", strings.ReplaceAll(fact.Attribute, "_", " "), fact.Value, fact.Source)
+	}
+	return b.String()
+}
+
+// contradiction is a pair of articles that disagree about one attribute of
+// the same subject.
+type contradiction struct {
+	Subject   string
+	Attribute string
+	First     loreFact
+	Second    loreFact
+}
+
+func loadContradictions(ctx context.Context, db *sql.DB, limit, offset int) ([]contradiction, error) {
+	const query = `SELECT a.subject_slug, a.attribute, a.page_slug, a.value, b.page_slug, b.value FROM page_facts a
+		JOIN page_facts b ON b.subject_slug = a.subject_slug AND b.attribute = a.attribute
+			AND b.page_slug > a.page_slug AND b.value_key <> a.value_key
+		ORDER BY a.subject_slug, a.attribute, a.page_slug, b.page_slug
+		LIMIT ? OFFSET ?`
+	rows, err := db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var found []contradiction
+	for rows.Next() {
+		var c contradiction
+		if err := rows.Scan(&c.Subject, &c.Attribute, &c.First.Source, &c.First.Value, &c.Second.Source, &c.Second.Value); err != nil {
+			return nil, err
+		}
+		found = append(found, c)
+	}
+	return found, rows.Err()
+}
+
+// handleContradictions serves /admin/contradictions, listing pairs of articles
+// whose stored facts disagree. It requires the admin token and does not exist
+// when none is configured.
+func (s *Server) handleContradictions(w http.ResponseWriter, r *http.Request) {
+	if s.cfg.AdminToken == "" {
+		http.NotFound(w, r)
+		return
+	}
+	if !s.isAdmin(r) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="EndlessWiki admin"`)
+		http.Error(w, "admin token required", http.StatusUnauthorized)
+		return
+	}
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	count, err := s.pageCount(ctx)
+	if err != nil {
+		log.Printf("page count: %v", err)
+		count = 0
+	}
+
+	limit, offset := pagination(r)
+	found, err := loadContradictions(ctx, s.db, limit+1, offset)
+	if err != nil {
+		log.Printf("contradictions: %v", err)
+		http.Error(w, "failed to load contradictions", http.StatusInternalServerError)
+		return
+	}
+
+	data := struct {
+		Contradictions []contradiction
+		Offset         int
+		Limit          int
+		PrevOffset     int
+		NextOffset     int
+		HasPrev        bool
+		HasNext        bool
+		PageCount      int
+		SearchQuery    string
+	}{
+		Offset:    offset,
+		Limit:     limit,
+		PageCount: count,
+	}
+	if len(found) > limit {
+		found = found[:limit]
+		data.HasNext = true
+		data.NextOffset = offset + limit
+	}
+	data.Contradictions = found
+	if offset > 0 {
+		data.HasPrev = true
+		data.PrevOffset = max(0, offset-limit)
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := s.templates.ExecuteTemplate(w, "contradictions.gohtml", data); err != nil {
+		log.Printf("render contradictions: %v", err)
+	}
+}
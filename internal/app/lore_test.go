@@ -0,0 +1,114 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoreValueKey(t *testing.T) {
+	for _, tc := range []struct{ a, b string }{
+		{"1203", "c. 1203 AD"},
+		{"300 BC", "300 BCE"},
+		{"Aldric the Bold", "aldric the bold."},
+		{"Kingdom of Veldor", "Kingdom  of  Veldor"},
+	} {
+		if ka, kb := loreValueKey(tc.a), loreValueKey(tc.b); ka != kb {
+			t.Errorf("loreValueKey(%q) = %q, loreValueKey(%q) = %q", tc.a, ka, tc.b, kb)
+		}
+	}
+	for _, tc := range []struct{ a, b string }{
+		{"1203", "1250"},
+		{"300 BC", "300 AD"},
+		{"42,000", "42"},
+	} {
+		if loreValueKey(tc.a) == loreValueKey(tc.b) {
+			t.Errorf("loreValueKey(%q) == loreValueKey(%q)", tc.a, tc.b)
+		}
+	}
+}
+
+func TestNormalizeLoreFacts(t *testing.T) {
+	founded := 1203.0
+	box := &infobox{Type: "city", Facts: []infoboxFact{{Field: "founded", Value: "1203", Number: &founded}}}
+	claims := []loreClaim{
+		{Subject: "Veldor", Attribute: "Founded", Value: "1250"}, // infobox wins
+		{Subject: "Aldric the Bold", Attribute: "born in", Value: "Veldor"},
+		{Subject: "Aldric the Bold", Attribute: "died", Value: "1290"},
+		{Subject: "a/b", Attribute: "founded", Value: "1"},      // bad subject
+		{Subject: "Veldor", Attribute: "motto!", Value: "Salt"}, // bad attribute
+		{Subject: "Veldor", Attribute: "river", Value: " — "},   // empty value
+	}
+	got := normalizeLoreFacts("veldor", box, claims)
+	want := []loreFact{
+		{Source: "veldor", Subject: "veldor", Attribute: "founded", Value: "1203", Key: "year:1203"},
+		{Source: "veldor", Subject: "aldric_the_bold", Attribute: "born_in", Value: "Veldor", Key: "veldor"},
+		{Source: "veldor", Subject: "aldric_the_bold", Attribute: "died", Value: "1290", Key: "year:1290"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("normalizeLoreFacts = %+v, want %+v", got, want)
+	}
+}
+
+func TestSplitMetaTrailerReadsFacts(t *testing.T) {
+	_, meta := splitMetaTrailer(`<p>x</p><!-- endlesswiki-meta {"facts": [{"subject": "Veldor", "attribute": "founded", "value": "1203"}]} -->`)
+	if want := []loreClaim{{Subject: "Veldor", Attribute: "founded", Value: "1203"}}; !reflect.DeepEqual(meta.Facts, want) {
+		t.Fatalf("facts = %+v", meta.Facts)
+	}
+}
+
+func TestLoreInstructions(t *testing.T) {
+	if loreInstructions(nil) != "" {
+		t.Fatalf("instructions without facts should be empty")
+	}
+	got := loreInstructions([]loreFact{{Source: "Aldric the Bold", Attribute: "founded_by", Value: "Aldric"}})
+	if !strings.Contains(got, `- founded by: Aldric (from the article "Aldric the Bold")`) {
+		t.Fatalf("loreInstructions = %q", got)
+	}
+}
+
+func TestContradictionsRequireAdmin(t *testing.T) {
+	srv, err := NewServer(nil, Config{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/contradictions", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status without admin token configured = %d, want 404", rec.Code)
+	}
+
+	srv.cfg.AdminToken = "secret"
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/contradictions", nil))
+	if rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") == "" {
+		t.Fatalf("status = %d, WWW-Authenticate = %q", rec.Code, rec.Header().Get("WWW-Authenticate"))
+	}
+}
+
+func TestContradictionsTemplate(t *testing.T) {
+	srv, err := NewServer(nil, Config{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	data := map[string]any{
+		"Offset": 0,
+		"Contradictions": []contradiction{{
+			Subject:   "veldor",
+			Attribute: "founded",
+			First:     loreFact{Source: "aldric_the_bold", Value: "1203"},
+			Second:    loreFact{Source: "veldor", Value: "1250"},
+		}},
+	}
+	var b strings.Builder
+	if err := srv.templates.ExecuteTemplate(&b, "contradictions.gohtml", data); err != nil {
+		t.Fatalf("render contradictions: %v", err)
+	}
+	for _, want := range []string{`<a href="/wiki/veldor">Veldor</a>`, `says &ldquo;1203&rdquo;`, `says &ldquo;1250&rdquo;`} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("output missing %s", want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package app
+
+import (
+	"container/list"
+	"time"
+)
+
+// lruCache is a least-recently-used cache with an optional time-to-live. It is
+// not safe for concurrent use; callers guard it with their own mutex so they
+// can keep related bookkeeping consistent with evictions.
+type lruCache[V any] struct {
+	capacity int
+	ttl      time.Duration
+	ll       *list.List
+	items    map[string]*list.Element
+	onEvict  func(key string, value V)
+	now      func() time.Time
+}
+
+type lruEntry[V any] struct {
+	key    string
+	value  V
+	stored time.Time
+}
+
+func newLRUCache[V any](capacity int, ttl time.Duration) *lruCache[V] {
+	return &lruCache[V]{
+		capacity: capacity,
+		ttl:      ttl,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+		now:      time.Now,
+	}
+}
+
+// Get returns the cached value for key, dropping it if it has expired.
+func (c *lruCache[V]) Get(key string) (V, bool) {
+	var zero V
+	el, ok := c.items[key]
+	if !ok {
+		return zero, false
+	}
+	entry := el.Value.(*lruEntry[V])
+	if c.ttl > 0 && c.now().Sub(entry.stored) >= c.ttl {
+		c.removeElement(el)
+		return zero, false
+	}
+	c.ll.MoveToFront(el)
+	return entry.value, true
+}
+
+// Add stores value under key, evicting the least recently used entries when
+// the cache is over capacity.
+func (c *lruCache[V]) Add(key string, value V) {
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+	el := c.ll.PushFront(&lruEntry[V]{key: key, value: value, stored: c.now()})
+	c.items[key] = el
+	for c.capacity > 0 && c.ll.Len() > c.capacity {
+		c.removeElement(c.ll.Back())
+	}
+}
+
+// Remove drops key from the cache if present.
+func (c *lruCache[V]) Remove(key string) {
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+}
+
+// Len reports the number of cached entries, including expired ones not yet
+// collected.
+func (c *lruCache[V]) Len() int {
+	return c.ll.Len()
+}
+
+func (c *lruCache[V]) removeElement(el *list.Element) {
+	entry := c.ll.Remove(el).(*lruEntry[V])
+	delete(c.items, entry.key)
+	if c.onEvict != nil {
+		c.onEvict(entry.key, entry.value)
+	}
+}
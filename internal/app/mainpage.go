@@ -1,5 +1,14 @@
 package app
 
+// defaultMainPage is the main page stored when it is first requested: the
+// configured one, or the curated article below.
+func (s *Server) defaultMainPage() string {
+	if s.cfg.MainPageHTML != "" {
+		return s.cfg.MainPageHTML
+	}
+	return mainPageHTML()
+}
+
 // mainPageHTML returns a curated article for the EndlessWiki main page.
 func mainPageHTML() string {
 	return `
@@ -0,0 +1,350 @@
+package app
+
+import (
+	"bufio"
+	"compress/bzip2"
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ImportOptions configures ImportMediaWiki.
+type ImportOptions struct {
+	// Conflict decides what happens to pages that already exist. Stored
+	// pages are compared with the latest revision in the export.
+	Conflict ConflictPolicy
+}
+
+// ImportStats counts what ImportMediaWiki did.
+type ImportStats struct {
+	Pages     int
+	Redirects int
+	// Skipped counts pages and redirects that were already stored and kept.
+	Skipped int
+	// Ignored counts pages outside the main namespace, pages whose titles
+	// make no valid slug, and pages with nothing left after conversion.
+	Ignored int
+	// RedirectLoops counts redirects whose chain leads back to itself.
+	RedirectLoops int
+	// DanglingRedirects counts redirects that lead to no page.
+	DanglingRedirects int
+}
+
+// mwPage is a page of a MediaWiki export, reduced to its latest revision.
+type mwPage struct {
+	Title     string
+	NS        int
+	Redirect  string
+	Text      string
+	Timestamp time.Time
+}
+
+type mwXMLPage struct {
+	Title    string `xml:"title"`
+	NS       *int   `xml:"ns"`
+	Redirect *struct {
+		Title string `xml:"title,attr"`
+	} `xml:"redirect"`
+	Revisions []struct {
+		Timestamp string `xml:"timestamp"`
+		Text      string `xml:"text"`
+	} `xml:"revision"`
+}
+
+type mwXMLNamespace struct {
+	Key  int    `xml:"key,attr"`
+	Name string `xml:",chardata"`
+}
+
+var mwRedirectPattern = regexp.MustCompile(`(?i)^\s*#REDIRECT\s*:?\s*\[\[([^\]|]+)`)
+
+// readMediaWiki streams the pages of a MediaWiki XML export to fn, along
+// with the namespaces its siteinfo declares.
+func readMediaWiki(r io.Reader, fn func(mwPage, mwNamespaces) error) error {
+	dec := xml.NewDecoder(r)
+	namespaces := mwNamespaces{}
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("read export: %w", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "namespace":
+			var ns mwXMLNamespace
+			if err := dec.DecodeElement(&ns, &start); err != nil {
+				return fmt.Errorf("read namespace: %w", err)
+			}
+			if name := strings.TrimSpace(ns.Name); name != "" {
+				namespaces[strings.ToLower(name)] = ns.Key
+			}
+			// The canonical English names work on every wiki.
+			for name, key := range mwDefaultNamespaces {
+				if key == ns.Key {
+					namespaces[name] = key
+				}
+			}
+		case "page":
+			var raw mwXMLPage
+			if err := dec.DecodeElement(&raw, &start); err != nil {
+				return fmt.Errorf("read page: %w", err)
+			}
+			if err := fn(raw.page(namespaces), namespaces); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// page keeps the latest revision of raw.
+func (raw *mwXMLPage) page(namespaces mwNamespaces) mwPage {
+	p := mwPage{Title: strings.TrimSpace(raw.Title)}
+	if raw.NS != nil {
+		p.NS = *raw.NS
+	} else {
+		p.NS, _ = namespaces.split(p.Title)
+	}
+	for _, rev := range raw.Revisions {
+		ts, err := time.Parse(time.RFC3339, strings.TrimSpace(rev.Timestamp))
+		if err != nil || !ts.Before(p.Timestamp) {
+			p.Timestamp, p.Text = ts, rev.Text
+		}
+	}
+	if raw.Redirect != nil && raw.Redirect.Title != "" {
+		p.Redirect = raw.Redirect.Title
+	} else if m := mwRedirectPattern.FindStringSubmatch(p.Text); m != nil {
+		p.Redirect = strings.TrimSpace(m[1])
+	}
+	return p
+}
+
+// openImportReader returns r, decompressed if it is gzipped or bzip2ed, the
+// formats MediaWiki dumps are distributed in.
+func openImportReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(3); err == nil && string(magic) == "BZh" {
+		return bzip2.NewReader(br), nil
+	}
+	return openDumpReader(br)
+}
+
+// ImportMediaWiki reads a MediaWiki XML export and stores the articles in its
+// main namespace as pages, converted from wikitext to the HTML generated
+// articles use. Their links become links between pages and their categories
+// categories. Redirect pages become redirects to the page their chain ends
+// at, unless a page already has the alias as its slug; redirects that loop
+// or lead nowhere are dropped.
+func ImportMediaWiki(ctx context.Context, db *sql.DB, r io.Reader, opts ImportOptions) (ImportStats, error) {
+	var stats ImportStats
+	r, err := openImportReader(r)
+	if err != nil {
+		return stats, err
+	}
+
+	// Redirects wait until every page is in, so that an alias can be checked
+	// against pages anywhere in the export.
+	redirects := make(map[string]string)
+	seen := make(map[string]bool)
+	// pages holds the slugs that are stored once the import is done.
+	pages := make(map[string]bool)
+	err = readMediaWiki(r, func(p mwPage, namespaces mwNamespaces) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		slug, err := mwSlug(p.Title)
+		if p.NS != mwMainNamespace || err != nil {
+			stats.Ignored++
+			return nil
+		}
+
+		if p.Redirect != "" {
+			target, _, _ := strings.Cut(p.Redirect, "#")
+			targetSlug, err := mwSlug(target)
+			if ns, _ := namespaces.split(target); ns != mwMainNamespace || err != nil || targetSlug == slug {
+				stats.Ignored++
+				return nil
+			}
+			redirects[slug] = targetSlug
+			return nil
+		}
+
+		// Titles differing only in case or punctuation share a slug; the
+		// first one wins.
+		if seen[slug] {
+			stats.Ignored++
+			return nil
+		}
+		seen[slug] = true
+		title := mwDisplayTitle(p.Title)
+		content, categories := wikitextToHTML(title, p.Text, namespaces)
+		if strings.HasSuffix(content, "<div class=\"endlesswiki-body\">\n</div>\n") {
+			stats.Ignored++
+			return nil
+		}
+		stored, err := importPage(ctx, db, slug, title, content, categories, p.Timestamp, opts.Conflict)
+		if err != nil {
+			return fmt.Errorf("import %s: %w", p.Title, err)
+		}
+		if stored {
+			stats.Pages++
+		} else {
+			stats.Skipped++
+		}
+		pages[slug] = true
+		return nil
+	})
+	if err != nil {
+		return stats, err
+	}
+
+	for alias := range redirects {
+		if seen[alias] {
+			stats.Ignored++
+			delete(redirects, alias)
+		}
+	}
+	for alias := range redirects {
+		target, ok := resolveRedirect(redirects, alias)
+		if !ok {
+			stats.RedirectLoops++
+			continue
+		}
+		if !pages[target] {
+			exists, err := pageExists(ctx, db, target)
+			if err != nil {
+				return stats, fmt.Errorf("import redirect %s: %w", alias, err)
+			}
+			if !exists {
+				stats.DanglingRedirects++
+				continue
+			}
+		}
+		stored, err := importRedirect(ctx, db, alias, target, opts.Conflict)
+		if err != nil {
+			return stats, fmt.Errorf("import redirect %s: %w", alias, err)
+		}
+		if stored {
+			stats.Redirects++
+		} else {
+			stats.Skipped++
+		}
+	}
+	return stats, nil
+}
+
+// resolveRedirect follows alias through redirects to the slug its chain ends
+// at, so stored redirects never chain. It reports false when the chain loops.
+func resolveRedirect(redirects map[string]string, alias string) (string, bool) {
+	visited := map[string]bool{alias: true}
+	target := redirects[alias]
+	for {
+		next, ok := redirects[target]
+		if !ok {
+			return target, true
+		}
+		if visited[target] {
+			return "", false
+		}
+		visited[target] = true
+		target = next
+	}
+}
+
+func pageExists(ctx context.Context, db *sql.DB, slug string) (bool, error) {
+	var page int
+	err := db.QueryRowContext(ctx, `SELECT 1 FROM pages WHERE slug = ?`, slug).Scan(&page)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func importPage(ctx context.Context, db *sql.DB, slug, title, content string, categories []string, modified time.Time, policy ConflictPolicy) (bool, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var created time.Time
+	var updated sql.NullTime
+	err = tx.QueryRowContext(ctx, `SELECT created_at, updated_at FROM pages WHERE slug = ? FOR UPDATE`, slug).Scan(&created, &updated)
+	exists := err == nil
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return false, err
+	}
+	if exists {
+		stored := created
+		if updated.Valid && updated.Time.After(stored) {
+			stored = updated.Time
+		}
+		if !policy.replaces(stored, modified) {
+			return false, nil
+		}
+	}
+
+	var timestamp any
+	if !modified.IsZero() {
+		timestamp = modified
+	}
+	if exists {
+		const update = `UPDATE pages SET title = ?, content = ?, updated_at = COALESCE(?, CURRENT_TIMESTAMP) WHERE slug = ?`
+		_, err = tx.ExecContext(ctx, update, title, content, timestamp, slug)
+	} else {
+		const insert = `INSERT INTO pages (slug, title, content, created_at) VALUES (?, ?, ?, COALESCE(?, CURRENT_TIMESTAMP))`
+		_, err = tx.ExecContext(ctx, insert, slug, title, content, timestamp)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := replacePageLinks(ctx, tx, slug, content); err != nil {
+		return false, err
+	}
+	if err := replacePageCategories(ctx, tx, slug, categories); err != nil {
+		return false, err
+	}
+	// An overwritten page loses the infobox and facts of its old content.
+	if err := replaceInfobox(ctx, tx, slug, nil); err != nil {
+		return false, err
+	}
+	if err := replacePageFacts(ctx, tx, slug, nil); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+func importRedirect(ctx context.Context, db *sql.DB, alias, target string, policy ConflictPolicy) (bool, error) {
+	isPage, err := pageExists(ctx, db, alias)
+	if err != nil || isPage {
+		// A page keeps its slug.
+		return false, err
+	}
+
+	var stored string
+	err = db.QueryRowContext(ctx, `SELECT target_slug FROM redirects WHERE alias_slug = ?`, alias).Scan(&stored)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return true, createRedirect(ctx, db, alias, target)
+	case err != nil:
+		return false, err
+	case stored == target || policy != ConflictOverwrite:
+		// Redirects in an export carry no timestamps, so keep-newer keeps
+		// what is stored.
+		return false, nil
+	}
+	_, err = db.ExecContext(ctx, `UPDATE redirects SET target_slug = ? WHERE alias_slug = ?`, target, alias)
+	return err == nil, err
+}
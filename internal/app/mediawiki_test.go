@@ -0,0 +1,135 @@
+package app
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+const mediaWikiExport = `<mediawiki xmlns="http://www.mediawiki.org/xml/export-0.11/" version="0.11" xml:lang="de">
+  <siteinfo>
+    <sitename>Testwiki</sitename>
+    <namespaces>
+      <namespace key="0" case="first-letter" />
+      <namespace key="6" case="first-letter">Datei</namespace>
+      <namespace key="14" case="first-letter">Kategorie</namespace>
+    </namespaces>
+  </siteinfo>
+  <page>
+    <title>Rom</title>
+    <ns>0</ns>
+    <revision>
+      <timestamp>2020-01-01T00:00:00Z</timestamp>
+      <text xml:space="preserve">old</text>
+    </revision>
+    <revision>
+      <timestamp>2021-06-01T12:00:00Z</timestamp>
+      <text xml:space="preserve">'''Rom''' &amp; [[Tiber]] [[Kategorie:Hauptstadt]] [[Datei:Rom.jpg]] [[Category:Stadt]]</text>
+    </revision>
+  </page>
+  <page>
+    <title>Roma</title>
+    <ns>0</ns>
+    <redirect title="Rom" />
+    <revision>
+      <timestamp>2021-01-01T00:00:00Z</timestamp>
+      <text xml:space="preserve">#WEITERLEITUNG [[Rom]]</text>
+    </revision>
+  </page>
+  <page>
+    <title>Urbs</title>
+    <revision>
+      <timestamp>2021-01-01T00:00:00Z</timestamp>
+      <text xml:space="preserve">#REDIRECT [[Rom#Geschichte]]</text>
+    </revision>
+  </page>
+  <page>
+    <title>Kategorie:Hauptstadt</title>
+    <revision>
+      <text xml:space="preserve">Capitals.</text>
+    </revision>
+  </page>
+</mediawiki>`
+
+func TestReadMediaWiki(t *testing.T) {
+	var pages []mwPage
+	var namespaces mwNamespaces
+	err := readMediaWiki(strings.NewReader(mediaWikiExport), func(p mwPage, ns mwNamespaces) error {
+		pages, namespaces = append(pages, p), ns
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pages) != 4 {
+		t.Fatalf("read %d pages", len(pages))
+	}
+
+	rom := pages[0]
+	if rom.Title != "Rom" || rom.NS != 0 || rom.Redirect != "" || !rom.Timestamp.Equal(time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("Rom = %+v", rom)
+	}
+	content, categories := wikitextToHTML(rom.Title, rom.Text, namespaces)
+	if !strings.Contains(content, `<p><b>Rom</b> &amp; <a href="/wiki/tiber">Tiber</a></p>`) || strings.Contains(content, "Rom.jpg") {
+		t.Errorf("Rom content = %s", content)
+	}
+	if got := strings.Join(categories, "|"); got != "Hauptstadt|Stadt" {
+		t.Errorf("categories = %q", got)
+	}
+
+	if pages[1].Redirect != "Rom" || pages[2].Redirect != "Rom#Geschichte" {
+		t.Errorf("redirects = %q, %q", pages[1].Redirect, pages[2].Redirect)
+	}
+	if pages[3].NS != mwCategoryNamespace {
+		t.Errorf("namespace of %s = %d", pages[3].Title, pages[3].NS)
+	}
+}
+
+func TestReadMediaWikiRejectsBadXML(t *testing.T) {
+	err := readMediaWiki(strings.NewReader("<mediawiki><page><title>Rom</title>"), func(mwPage, mwNamespaces) error { return nil })
+	if err == nil {
+		t.Error("truncated export accepted")
+	}
+}
+
+func TestOpenImportReader(t *testing.T) {
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	io.WriteString(zw, mediaWikiExport)
+	zw.Close()
+	for name, data := range map[string][]byte{"plain": []byte(mediaWikiExport), "gzip": gz.Bytes()} {
+		r, err := openImportReader(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got, err := io.ReadAll(r); err != nil || string(got) != mediaWikiExport {
+			t.Errorf("%s: read %.20q, %v", name, got, err)
+		}
+	}
+}
+
+func TestResolveRedirect(t *testing.T) {
+	redirects := map[string]string{
+		"rome_city": "roma",
+		"roma":      "rome",
+		"a":         "b",
+		"b":         "c",
+		"c":         "a",
+		"d":         "a",
+	}
+	for alias, want := range map[string]string{"rome_city": "rome", "roma": "rome", "a": "", "d": ""} {
+		got, ok := resolveRedirect(redirects, alias)
+		if want == "" {
+			if ok {
+				t.Errorf("resolveRedirect(%q) = %q, want a loop", alias, got)
+			}
+			continue
+		}
+		if !ok || got != want {
+			t.Errorf("resolveRedirect(%q) = %q, %v; want %q", alias, got, ok, want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package app
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+const (
+	metaTrailerOpen  = "<!-- endlesswiki-meta"
+	metaTrailerClose = "-->"
+)
+
+// metaTrailerInstructions asks the model to append structured data after the
+// article HTML.
+func metaTrailerInstructions() string {
+	return `After the HTML, append exactly one line of the form <!-- endlesswiki-meta {"categories": ["..."], "infobox": {"type": "...", "facts": {"field": "value"}}, "facts": [{"subject": "...", "attribute": "...", "value": "..."}]} -->. ` +
+		`List 1-4 short category names; write "Parent > Child" to place a category under a broader one. ` +
+		`Include the infobox only when the topic is one of these types, using only the listed fields: ` + infoboxPromptSpec() + `. ` +
+		`Dates are years such as "1203" or "300 BC"; entity fields name another article. ` +
+		`Under "facts", list up to 8 key claims the article makes about named people, places, and organisations (including its own subject), each with a single-valued attribute such as founded, founder, born, died, located_in, or capital; the subject is the name of the entity's article.`
+}
+
+// pageMeta is the structured data the model returns alongside an article.
+type pageMeta struct {
+	Categories []string    `json:"categories,omitempty"`
+	Infobox    *rawInfobox `json:"infobox,omitempty"`
+	Facts      []loreClaim `json:"facts,omitempty"`
+}
+
+// splitMetaTrailer removes the last endlesswiki-meta comment from generated
+// content and decodes it. Content without a trailer, or with one that is not
+// valid JSON, yields an empty pageMeta; a malformed trailer is still removed.
+func splitMetaTrailer(content string) (string, pageMeta) {
+	var meta pageMeta
+	start := strings.LastIndex(content, metaTrailerOpen)
+	if start < 0 {
+		return content, meta
+	}
+	rest := content[start+len(metaTrailerOpen):]
+	end := strings.Index(rest, metaTrailerClose)
+	if end < 0 {
+		return content, meta
+	}
+
+	if err := json.Unmarshal([]byte(strings.TrimSpace(rest[:end])), &meta); err != nil {
+		meta = pageMeta{}
+	}
+	stripped := content[:start] + rest[end+len(metaTrailerClose):]
+	return strings.TrimSpace(stripped), meta
+}
+
+// formatMetaTrailer renders meta the way the model is asked to.
+func formatMetaTrailer(meta pageMeta) string {
+	buf, err := json.Marshal(meta)
+	if err != nil {
+		return ""
+	}
+	// "--" may not appear inside an HTML comment; in JSON it can only occur
+	// within a string, where an escaped hyphen decodes to the same text.
+	encoded := strings.ReplaceAll(string(buf), "--", `-\u002d`)
+	return metaTrailerOpen + " " + encoded + " " + metaTrailerClose
+}
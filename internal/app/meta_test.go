@@ -0,0 +1,47 @@
+package app
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitMetaTrailer(t *testing.T) {
+	content := "<h1>Rome</h1>\n<p>Body</p>\n<!-- endlesswiki-meta {\"categories\": [\"History > Ancient Rome\", \"Cities\"]} -->\n"
+	html, meta := splitMetaTrailer(content)
+	if html != "<h1>Rome</h1>\n<p>Body</p>" {
+		t.Fatalf("html = %q", html)
+	}
+	if want := []string{"History > Ancient Rome", "Cities"}; !reflect.DeepEqual(meta.Categories, want) {
+		t.Fatalf("categories = %v, want %v", meta.Categories, want)
+	}
+}
+
+func TestSplitMetaTrailerDropsMalformedJSON(t *testing.T) {
+	html, meta := splitMetaTrailer("<p>Body</p><!-- endlesswiki-meta {categories: -->")
+	if html != "<p>Body</p>" || meta.Categories != nil {
+		t.Fatalf("splitMetaTrailer = %q, %+v", html, meta)
+	}
+	if html, _ := splitMetaTrailer("<p>No trailer</p>"); html != "<p>No trailer</p>" {
+		t.Fatalf("content without trailer changed: %q", html)
+	}
+}
+
+func TestFormatMetaTrailerRoundTrips(t *testing.T) {
+	meta := pageMeta{Categories: []string{"Rock -- and roll"}}
+	trailer := formatMetaTrailer(meta)
+	if got := trailer[len(metaTrailerOpen) : len(trailer)-len(metaTrailerClose)]; containsDoubleHyphen(got) {
+		t.Fatalf("trailer body contains --: %s", trailer)
+	}
+	if _, decoded := splitMetaTrailer("<p>x</p>" + trailer); !reflect.DeepEqual(decoded, meta) {
+		t.Fatalf("round trip = %+v, want %+v", decoded, meta)
+	}
+}
+
+func containsDoubleHyphen(s string) bool {
+	for i := 0; i+1 < len(s); i++ {
+		if s[i] == '-' && s[i+1] == '-' {
+			return true
+		}
+	}
+	return false
+}
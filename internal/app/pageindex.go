@@ -0,0 +1,134 @@
+package app
+
+import (
+	"context"
+	"log"
+	"maps"
+	"sync"
+	"time"
+)
+
+// pageIndexReloadInterval controls how often the page index is rebuilt to
+// pick up pages created by other instances or maintenance commands.
+const pageIndexReloadInterval = 30 * time.Minute
+
+// pageIndex keeps the slug and title of every page in memory, so generating
+// a page does not scan the pages table. Like the frontier index it is loaded
+// on first use, updated as pages are created, and rebuilt periodically.
+type pageIndex struct {
+	mu        sync.Mutex
+	loaded    bool
+	reloading bool
+	loadedAt  time.Time
+	titles    map[string]string
+	// autolinks is built from titles on demand and dropped when they change.
+	autolinks autolinkIndex
+	load      func(context.Context) ([]autolinkTarget, error)
+}
+
+func newPageIndex(load func(context.Context) ([]autolinkTarget, error)) *pageIndex {
+	return &pageIndex{load: load}
+}
+
+// ensure loads the index on first use and schedules a background rebuild once
+// it is older than pageIndexReloadInterval.
+func (p *pageIndex) ensure(ctx context.Context) error {
+	p.mu.Lock()
+	if p.loaded {
+		if !p.reloading && time.Since(p.loadedAt) >= pageIndexReloadInterval {
+			p.reloading = true
+			go p.reload()
+		}
+		p.mu.Unlock()
+		return nil
+	}
+	p.mu.Unlock()
+
+	targets, err := p.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.loaded {
+		p.replace(targets)
+	}
+	return nil
+}
+
+func (p *pageIndex) reload() {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	targets, err := p.load(ctx)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.reloading = false
+	if err != nil {
+		log.Printf("reload page index: %v", err)
+		return
+	}
+	p.replace(targets)
+}
+
+// replace swaps in a freshly loaded index. Callers hold p.mu.
+func (p *pageIndex) replace(targets []autolinkTarget) {
+	p.titles = make(map[string]string, len(targets))
+	for _, target := range targets {
+		p.titles[target.Slug] = target.Title
+	}
+	p.autolinks = nil
+	p.loaded, p.loadedAt = true, time.Now()
+}
+
+// Add records a page that was created, or that a new alias points at.
+func (p *pageIndex) Add(slug, title string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.loaded {
+		return
+	}
+	title = DisplayTitle(slug, title)
+	current, ok := p.titles[slug]
+	if ok && current == title {
+		return
+	}
+	p.titles[slug] = title
+	switch {
+	case ok:
+		// A retitled page: its old candidate has to go.
+		p.autolinks = nil
+	case p.autolinks != nil:
+		p.autolinks.add(autolinkTarget{Slug: slug, Title: title})
+	}
+}
+
+// findDuplicate runs findDuplicate against the indexed slugs.
+func (p *pageIndex) findDuplicate(ctx context.Context, slug string) (duplicateMatch, bool, error) {
+	if err := p.ensure(ctx); err != nil {
+		return duplicateMatch{}, false, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	match, ok := findDuplicate(slug, maps.Keys(p.titles))
+	return match, ok, nil
+}
+
+// autolink links mentions of indexed pages in content, as autolinkMentions.
+func (p *pageIndex) autolink(ctx context.Context, slug, content string) (string, error) {
+	if err := p.ensure(ctx); err != nil {
+		return content, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.autolinks == nil {
+		targets := make([]autolinkTarget, 0, len(p.titles))
+		for s, title := range p.titles {
+			targets = append(targets, autolinkTarget{Slug: s, Title: title})
+		}
+		p.autolinks = newAutolinkIndex(targets)
+	}
+	return autolinkMentions(content, slug, p.autolinks, maxAutolinksPerArticle), nil
+}
@@ -0,0 +1,39 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestPageIndexLinksPagesAddedAfterLoad(t *testing.T) {
+	loads := 0
+	index := newPageIndex(func(context.Context) ([]autolinkTarget, error) {
+		loads++
+		return []autolinkTarget{{Slug: "atlantis", Title: "Atlantis"}}, nil
+	})
+	ctx := context.Background()
+
+	content := "<p>Atlantis sank near the Sunken Library.</p>"
+	got, err := index.autolink(ctx, "oceans", content)
+	if err != nil {
+		t.Fatalf("autolink: %v", err)
+	}
+	if strings.Contains(got, "sunken_library") {
+		t.Fatalf("linked a page before it was added: %s", got)
+	}
+
+	index.Add("sunken_library", "Sunken Library")
+	got, err = index.autolink(ctx, "oceans", content)
+	if err != nil {
+		t.Fatalf("autolink: %v", err)
+	}
+	for _, want := range []string{`href="/wiki/atlantis"`, `href="/wiki/sunken_library"`} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("missing %s in %s", want, got)
+		}
+	}
+	if loads != 1 {
+		t.Fatalf("loads = %d, want 1", loads)
+	}
+}
@@ -0,0 +1,217 @@
+package app
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	nethtml "golang.org/x/net/html"
+)
+
+const (
+	previewCacheSize = 4096
+	// previewCacheTTL bounds how long a preview may miss changes made by
+	// other instances or maintenance commands.
+	previewCacheTTL = 30 * time.Minute
+	// previewExcerptLen is the longest lead excerpt, in runes.
+	previewExcerptLen = 320
+
+	// Existing pages rarely change, so browsers may keep their previews for a
+	// while; a missing page may be written at any moment.
+	previewMaxAge        = "public, max-age=3600"
+	previewMissingMaxAge = "public, max-age=60"
+)
+
+// pagePreview is the /api/preview response. Missing pages only carry Slug,
+// Title, and URL.
+type pagePreview struct {
+	Slug           string     `json:"slug"`
+	Title          string     `json:"title"`
+	URL            string     `json:"url"`
+	Exists         bool       `json:"exists"`
+	Extract        string     `json:"extract,omitempty"`
+	CreatedAt      *time.Time `json:"created_at,omitempty"`
+	RedirectedFrom string     `json:"redirected_from,omitempty"`
+}
+
+// previewCache holds previews keyed by canonical slug, including the "not yet
+// written" answers for missing slugs. Aliases map to their canonical slug, so
+// invalidating a page also drops what its aliases preview as.
+type previewCache struct {
+	mu      sync.Mutex
+	entries *lruCache[*pagePreview]
+	aliases *lruCache[string]
+}
+
+func newPreviewCache(capacity int, ttl time.Duration) *previewCache {
+	return &previewCache{
+		entries: newLRUCache[*pagePreview](capacity, ttl),
+		aliases: newLRUCache[string](capacity, ttl),
+	}
+}
+
+func (c *previewCache) Get(slug string) (*pagePreview, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	canonical, ok := c.aliases.Get(slug)
+	if !ok {
+		return c.entries.Get(slug)
+	}
+	preview, ok := c.entries.Get(canonical)
+	if !ok {
+		return nil, false
+	}
+	redirected := *preview
+	redirected.RedirectedFrom = slug
+	return &redirected, true
+}
+
+func (c *previewCache) Put(slug string, preview *pagePreview) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if preview.RedirectedFrom == "" {
+		c.entries.Add(slug, preview)
+		return
+	}
+	canonical := *preview
+	canonical.RedirectedFrom = ""
+	c.aliases.Add(slug, canonical.Slug)
+	c.entries.Add(canonical.Slug, &canonical)
+}
+
+// Invalidate drops slug, so a page that was just written no longer previews
+// as missing and its aliases pick up its new content.
+func (c *previewCache) Invalidate(slug string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries.Remove(slug)
+	c.aliases.Remove(slug)
+}
+
+// leadExcerpt returns the plain text of the article's lead: everything after
+// the <h1> and before the first section heading, truncated at a word boundary
+// to about limit runes.
+func leadExcerpt(content string, limit int) string {
+	z := nethtml.NewTokenizer(strings.NewReader(content))
+	var b strings.Builder
+	skipping := ""
+	if headingEnd(content) > 0 {
+		skipping = "h1"
+	}
+
+	for utf8.RuneCountInString(b.String()) <= limit {
+		tt := z.Next()
+		if tt == nethtml.ErrorToken {
+			break
+		}
+		switch tt {
+		case nethtml.StartTagToken:
+			name, _ := z.TagName()
+			tag := string(name)
+			if skipping == "" && (tag == "script" || tag == "style") {
+				skipping = tag
+			}
+			if skipping == "" && sectionLevel(tag) > 0 {
+				return truncateWords(b.String(), limit)
+			}
+		case nethtml.EndTagToken:
+			name, _ := z.TagName()
+			if string(name) == skipping {
+				skipping = ""
+			}
+		case nethtml.TextToken:
+			if skipping != "" {
+				continue
+			}
+			if text := strings.Join(strings.Fields(string(z.Text())), " "); text != "" {
+				if b.Len() > 0 {
+					b.WriteByte(' ')
+				}
+				b.WriteString(text)
+			}
+		}
+	}
+	return truncateWords(b.String(), limit)
+}
+
+// truncateWords shortens s to at most limit runes, cutting at the last space
+// and marking the cut with an ellipsis.
+func truncateWords(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	cut := string(runes[:limit])
+	if i := strings.LastIndexByte(cut, ' '); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,;:.") + "…"
+}
+
+// loadPreview builds the preview for slug, following a redirect to its
+// canonical page.
+func (s *Server) loadPreview(ctx context.Context, slug string) (*pagePreview, error) {
+	preview := &pagePreview{Slug: slug, Title: SlugTitle(slug), URL: s.path("/wiki/" + url.PathEscape(slug))}
+
+	page, err := s.lookupPage(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if page == nil {
+		target, err := lookupRedirect(ctx, s.db, slug)
+		if err != nil || target == "" {
+			return preview, err
+		}
+		if page, err = s.lookupPage(ctx, target); err != nil || page == nil {
+			return preview, err
+		}
+		preview.RedirectedFrom = slug
+	}
+
+	created := page.CreatedAt.UTC()
+	preview.Slug = page.Slug
+	preview.Title = page.DisplayTitle()
+	preview.URL = s.path("/wiki/" + url.PathEscape(page.Slug))
+	preview.Exists = true
+	preview.Extract = leadExcerpt(page.Content, previewExcerptLen)
+	preview.CreatedAt = &created
+	return preview, nil
+}
+
+// handlePreview serves /api/preview/{slug} for the hover cards. It never
+// generates pages.
+func (s *Server) handlePreview(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	slug, err := NormalizeSlug(strings.TrimPrefix(r.URL.Path, "/api/preview/"))
+	if err != nil {
+		writeJSONError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	preview, ok := s.previews.Get(slug)
+	if !ok {
+		preview, err = s.loadPreview(r.Context(), slug)
+		if err != nil {
+			log.Printf("preview %s: %v", slug, err)
+			writeJSONError(w, http.StatusInternalServerError, "preview failed")
+			return
+		}
+		s.previews.Put(slug, preview)
+	}
+
+	if preview.Exists {
+		w.Header().Set("Cache-Control", previewMaxAge)
+	} else {
+		w.Header().Set("Cache-Control", previewMissingMaxAge)
+	}
+	writeJSON(w, http.StatusOK, preview)
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLeadExcerpt(t *testing.T) {
+	content := `<h1>Veldor</h1><div class="endlesswiki-body"><p>Veldor is a <a href="/wiki/city">city</a> &amp; port.</p>` +
+		`<style>p { color: red; }</style><p>It was founded in 1203.</p>` +
+		`<h2>History</h2><p>Not part of the lead.</p></div>`
+
+	if got, want := leadExcerpt(content, 200), "Veldor is a city & port. It was founded in 1203."; got != want {
+		t.Fatalf("leadExcerpt = %q, want %q", got, want)
+	}
+	if got, want := leadExcerpt(content, 20), "Veldor is a city &…"; got != want {
+		t.Fatalf("truncated leadExcerpt = %q, want %q", got, want)
+	}
+}
+
+func TestPreviewServedFromCache(t *testing.T) {
+	srv, err := NewServer(nil, Config{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	srv.previews.Put("veldor", &pagePreview{Slug: "veldor", Title: "Veldor", URL: "/wiki/veldor", Exists: true, Extract: "A city."})
+	srv.previews.Put("nowhere", &pagePreview{Slug: "nowhere", Title: "Nowhere", URL: "/wiki/nowhere"})
+
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/preview/Veldor", nil))
+	if rec.Code != http.StatusOK || rec.Header().Get("Cache-Control") != previewMaxAge {
+		t.Fatalf("status = %d, Cache-Control = %q", rec.Code, rec.Header().Get("Cache-Control"))
+	}
+	var got pagePreview
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil || !got.Exists || got.Extract != "A city." {
+		t.Fatalf("preview = %+v, %v", got, err)
+	}
+
+	rec = httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/preview/nowhere", nil))
+	if rec.Header().Get("Cache-Control") != previewMissingMaxAge || strings.Contains(rec.Body.String(), "extract") {
+		t.Fatalf("missing preview = %q, Cache-Control = %q", rec.Body.String(), rec.Header().Get("Cache-Control"))
+	}
+
+	srv.previews.Invalidate("nowhere")
+	if _, ok := srv.previews.Get("nowhere"); ok {
+		t.Fatalf("invalidated preview still cached")
+	}
+}
+
+func TestPreviewCacheDropsAliasesWithCanonicalPage(t *testing.T) {
+	cache := newPreviewCache(16, time.Hour)
+	cache.Put("veldor_city", &pagePreview{Slug: "veldor", Title: "Veldor", Exists: true, RedirectedFrom: "veldor_city"})
+
+	got, ok := cache.Get("veldor_city")
+	if !ok || got.Slug != "veldor" || got.RedirectedFrom != "veldor_city" {
+		t.Fatalf("alias preview = %+v, %v", got, ok)
+	}
+	if got, ok := cache.Get("veldor"); !ok || got.RedirectedFrom != "" {
+		t.Fatalf("canonical preview = %+v, %v", got, ok)
+	}
+
+	cache.Invalidate("veldor")
+	if _, ok := cache.Get("veldor_city"); ok {
+		t.Fatalf("alias preview survived invalidating its page")
+	}
+}
+
+func TestPreviewRejectsInvalidSlug(t *testing.T) {
+	srv, err := NewServer(nil, Config{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	rec := httptest.NewRecorder()
+	srv.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/preview/a%3Fb", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status = %d, want 400", rec.Code)
+	}
+}
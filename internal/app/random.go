@@ -0,0 +1,215 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	// randomRecentWindow is how many of the newest pages "recent" mode samples.
+	randomRecentWindow    = 500
+	constellationSnapshot = "static/constellation.json"
+)
+
+// Random page modes accepted by /random?mode=.
+const (
+	randomUniform  = ""
+	randomRecent   = "recent"
+	randomCluster  = "cluster"
+	randomFrontier = "frontier"
+)
+
+func (s *Server) handleRandomPage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var slug string
+	var err error
+	switch mode := r.URL.Query().Get("mode"); mode {
+	case randomUniform:
+		slug, err = s.randomSlug(ctx)
+	case randomRecent:
+		slug, err = s.randomRecentSlug(ctx)
+	case randomCluster:
+		slug, err = s.randomClusterSlug(ctx)
+	case randomFrontier:
+		slug, err = s.randomFrontierSlug(ctx)
+	default:
+		http.Error(w, "unknown random mode", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		log.Printf("random slug: %v", err)
+		http.Error(w, "failed to load random page", http.StatusInternalServerError)
+		return
+	}
+	if slug == "" {
+		http.Redirect(w, r, s.path("/"), http.StatusFound)
+		return
+	}
+	http.Redirect(w, r, s.path("/wiki/"+url.PathEscape(slug)), http.StatusFound)
+}
+
+// randomSlug picks a page uniformly (up to id gaps) by probing a random id on
+// the dense id index rather than sorting the table.
+func (s *Server) randomSlug(ctx context.Context) (string, error) {
+	return s.sampleSlugFrom(ctx, 1)
+}
+
+// randomRecentSlug samples among the newest randomRecentWindow pages.
+func (s *Server) randomRecentSlug(ctx context.Context) (string, error) {
+	maxID, err := s.maxPageID(ctx)
+	if err != nil || maxID == 0 {
+		return "", err
+	}
+	return s.sampleSlugFrom(ctx, max(1, maxID-randomRecentWindow+1))
+}
+
+// randomFrontierSlug picks a page that still links to at least one unwritten
+// topic, falling back to any page when the frontier is empty.
+func (s *Server) randomFrontierSlug(ctx context.Context) (string, error) {
+	_, source, err := s.pickFrontierLink(ctx)
+	if err != nil || source != "" {
+		return source, err
+	}
+	return s.randomSlug(ctx)
+}
+
+// randomClusterSlug favours small constellation clusters, picking a cluster
+// with probability inversely proportional to its size and then one of its
+// sampled members. Without a constellation snapshot it samples uniformly.
+func (s *Server) randomClusterSlug(ctx context.Context) (string, error) {
+	if snapshot := s.clusterSnapshot(); snapshot != nil {
+		if slug := snapshot.pickUnderexplored(rand.Float64(), rand.IntN); slug != "" {
+			return slug, nil
+		}
+	}
+	return s.randomSlug(ctx)
+}
+
+// clusterSnapshot returns the constellation snapshot, or nil when there is
+// none. The snapshot only covers the default wiki, so worlds and editions never
+// have one.
+func (s *Server) clusterSnapshot() *clusterSnapshot {
+	if !s.cfg.hasConstellation() {
+		return nil
+	}
+	snapshot, err := s.constellation.Load(constellationSnapshot)
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Printf("load constellation: %v", err)
+		}
+		return nil
+	}
+	return snapshot
+}
+
+func (s *Server) sampleSlugFrom(ctx context.Context, minID int64) (string, error) {
+	maxID, err := s.maxPageID(ctx)
+	if err != nil || maxID == 0 {
+		return "", err
+	}
+	if minID > maxID {
+		minID = maxID
+	}
+
+	probe := minID + rand.Int64N(maxID-minID+1)
+	const query = `SELECT slug FROM pages WHERE id >= ? ORDER BY id LIMIT 1`
+	var slug string
+	if err := s.db.QueryRowContext(ctx, query, probe).Scan(&slug); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		return "", err
+	}
+	return slug, nil
+}
+
+func (s *Server) maxPageID(ctx context.Context) (int64, error) {
+	var maxID sql.NullInt64
+	if err := s.db.QueryRowContext(ctx, `SELECT MAX(id) FROM pages`).Scan(&maxID); err != nil {
+		return 0, err
+	}
+	return maxID.Int64, nil
+}
+
+// clusterSnapshot is the subset of the constellation export random sampling
+// needs.
+type clusterSnapshot struct {
+	Clusters []struct {
+		ID     int `json:"id"`
+		Size   int `json:"size"`
+		Sample []struct {
+			Slug string `json:"slug"`
+		} `json:"sample"`
+	} `json:"clusters"`
+}
+
+// pickUnderexplored chooses a cluster weighted by 1/size using roll in [0,1),
+// then a member via intn.
+func (c *clusterSnapshot) pickUnderexplored(roll float64, intn func(int) int) string {
+	total := 0.0
+	for _, cluster := range c.Clusters {
+		if cluster.Size > 0 && len(cluster.Sample) > 0 {
+			total += 1 / float64(cluster.Size)
+		}
+	}
+	if total == 0 {
+		return ""
+	}
+
+	target := roll * total
+	chosen := -1
+	for i, cluster := range c.Clusters {
+		if cluster.Size <= 0 || len(cluster.Sample) == 0 {
+			continue
+		}
+		// Remember the last eligible cluster in case rounding leaves target >= 0.
+		chosen = i
+		target -= 1 / float64(cluster.Size)
+		if target < 0 {
+			break
+		}
+	}
+	sample := c.Clusters[chosen].Sample
+	return sample[intn(len(sample))].Slug
+}
+
+// constellationCache reloads the exported snapshot only when the file changes.
+type constellationCache struct {
+	mu       sync.Mutex
+	modTime  time.Time
+	snapshot *clusterSnapshot
+}
+
+func (c *constellationCache) Load(path string) (*clusterSnapshot, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.snapshot != nil && info.ModTime().Equal(c.modTime) {
+		return c.snapshot, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var snapshot clusterSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, err
+	}
+	c.snapshot, c.modTime = &snapshot, info.ModTime()
+	return c.snapshot, nil
+}
@@ -0,0 +1,30 @@
+package app
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPickUnderexploredFavoursSmallClusters(t *testing.T) {
+	var snapshot clusterSnapshot
+	raw := `{"clusters":[
+		{"id":1,"size":90,"sample":[{"slug":"big_a"},{"slug":"big_b"}]},
+		{"id":2,"size":10,"sample":[{"slug":"small"}]},
+		{"id":3,"size":5,"sample":[]}
+	]}`
+	if err := json.Unmarshal([]byte(raw), &snapshot); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	first := func(int) int { return 0 }
+
+	// Weights are 1/90 and 1/10, so the small cluster owns the top 90% of rolls.
+	if got := snapshot.pickUnderexplored(0.05, first); got != "big_a" {
+		t.Fatalf("low roll picked %q, want big_a", got)
+	}
+	if got := snapshot.pickUnderexplored(0.5, first); got != "small" {
+		t.Fatalf("mid roll picked %q, want small", got)
+	}
+	if got := snapshot.pickUnderexplored(0.999999, first); got != "small" {
+		t.Fatalf("high roll picked %q, want small", got)
+	}
+}
@@ -0,0 +1,108 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// lookupRedirect returns the canonical slug that alias points at, or "" when
+// alias is not a redirect.
+func lookupRedirect(ctx context.Context, db *sql.DB, alias string) (string, error) {
+	const query = `SELECT target_slug FROM redirects WHERE alias_slug = ?`
+	var target string
+	if err := db.QueryRowContext(ctx, query, alias).Scan(&target); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", nil
+		}
+		return "", err
+	}
+	return target, nil
+}
+
+// createRedirect records alias as pointing at target. An existing alias row
+// is left untouched.
+func createRedirect(ctx context.Context, db execer, alias, target string) error {
+	const query = `INSERT INTO redirects (alias_slug, target_slug) VALUES (?, ?)
+		ON DUPLICATE KEY UPDATE alias_slug = alias_slug`
+	_, err := db.ExecContext(ctx, query, alias, target)
+	return err
+}
+
+// redirectURL is the location an alias redirects to. The alias travels along
+// so the canonical page can show a "Redirected from" notice.
+func redirectURL(target, alias string) string {
+	return "/wiki/" + url.PathEscape(target) + "?redirectedfrom=" + url.QueryEscape(alias)
+}
+
+// redirectedFrom returns the alias named by ?redirectedfrom= when it really
+// redirects to slug, so the notice cannot be forged with arbitrary text.
+func (s *Server) redirectedFrom(r *http.Request, slug string) string {
+	raw := r.URL.Query().Get("redirectedfrom")
+	if raw == "" {
+		return ""
+	}
+	alias, err := NormalizeSlug(raw)
+	if err != nil {
+		return ""
+	}
+	target, err := lookupRedirect(r.Context(), s.db, alias)
+	if err != nil || target != slug {
+		return ""
+	}
+	return alias
+}
+
+// MergePages deletes the page from and turns its slug into an alias of into,
+// repointing aliases of from so redirects never chain.
+func MergePages(ctx context.Context, db *sql.DB, from, into string) error {
+	if from == into {
+		return fmt.Errorf("cannot merge %q into itself", from)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, slug := range []string{from, into} {
+		var exists int
+		err := tx.QueryRowContext(ctx, `SELECT 1 FROM pages WHERE slug = ? FOR UPDATE`, slug).Scan(&exists)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("page %q does not exist", slug)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	statements := []struct {
+		query string
+		args  []any
+	}{
+		{`DELETE FROM pages WHERE slug = ?`, []any{from}},
+		{`DELETE FROM page_links WHERE source_slug = ?`, []any{from}},
+		{`DELETE FROM page_categories WHERE page_slug = ?`, []any{from}},
+		{`DELETE FROM infobox_facts WHERE page_slug = ?`, []any{from}},
+		{`DELETE FROM infoboxes WHERE page_slug = ?`, []any{from}},
+		{`DELETE FROM page_revisions WHERE page_slug = ?`, []any{from}},
+		{`DELETE FROM page_facts WHERE page_slug = ?`, []any{from}},
+		{`UPDATE page_facts SET subject_slug = ? WHERE subject_slug = ?`, []any{into, from}},
+		// Translations of into win over those of from.
+		{`UPDATE IGNORE langlinks SET page_slug = ? WHERE page_slug = ?`, []any{into, from}},
+		{`DELETE FROM langlinks WHERE page_slug = ?`, []any{from}},
+		{`UPDATE redirects SET target_slug = ? WHERE target_slug = ?`, []any{into, from}},
+		{`INSERT INTO redirects (alias_slug, target_slug) VALUES (?, ?)
+			ON DUPLICATE KEY UPDATE target_slug = VALUES(target_slug)`, []any{from, into}},
+	}
+	for _, stmt := range statements {
+		if _, err := tx.ExecContext(ctx, stmt.query, stmt.args...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,47 @@
+package app
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRedirectURLEscapesAlias(t *testing.T) {
+	got := redirectURL("roman_empire", "the_roman_empire")
+	if got != "/wiki/roman_empire?redirectedfrom=the_roman_empire" {
+		t.Fatalf("redirectURL = %q", got)
+	}
+}
+
+func TestRedirectedFromIgnoresInvalidAlias(t *testing.T) {
+	srv, err := NewServer(nil, Config{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	for _, target := range []string{"/wiki/rome", "/wiki/rome?redirectedfrom=a%2F..%2Fb"} {
+		if got := srv.redirectedFrom(httptest.NewRequest("GET", target, nil), "rome"); got != "" {
+			t.Fatalf("redirectedFrom(%s) = %q, want empty", target, got)
+		}
+	}
+}
+
+func TestWikiTemplateShowsRedirectNotice(t *testing.T) {
+	srv, err := NewServer(nil, Config{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	data := map[string]any{
+		"Title":          "Roman Empire",
+		"Slug":           "roman_empire",
+		"Content":        "",
+		"RedirectedFrom": "the_roman_empire",
+	}
+
+	var b strings.Builder
+	if err := srv.templates.ExecuteTemplate(&b, "wiki.gohtml", data); err != nil {
+		t.Fatalf("render wiki: %v", err)
+	}
+	if !strings.Contains(b.String(), "(Redirected from The Roman Empire)") {
+		t.Fatalf("missing redirect notice: %s", b.String())
+	}
+}
@@ -0,0 +1,178 @@
+package app
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	renderCacheSize = 2048
+	// renderCacheTTL bounds how long an entry may miss pages created by other
+	// instances or maintenance commands, which cannot invalidate this process.
+	renderCacheTTL = 10 * time.Minute
+)
+
+// renderedPage is a stored page with its links already decorated, ready to be
+// dropped into wiki.gohtml.
+type renderedPage struct {
+	Slug         string
+	Title        string
+	Body         string
+	ETag         string
+	LastModified time.Time
+	Infobox      *infoboxView
+	TOC          []tocEntry
+	Categories   []categoryLink
+	Languages    []languageLink
+	missing      []string
+	// headingEnd is the offset just past the article's <h1>, where the
+	// infobox is placed; 0 when the body has no heading.
+	headingEnd int
+	// tocAt is the offset of the first section heading, where the table of
+	// contents is placed; only meaningful when TOC is set.
+	tocAt int
+}
+
+// renderCache holds decorated page bodies keyed by slug. Each entry records the
+// missing slugs it rendered as new-page links; creating one of those pages
+// drops exactly the entries that link to it.
+type renderCache struct {
+	mu         sync.Mutex
+	pages      *lruCache[*renderedPage]
+	dependents map[string]map[string]struct{}
+}
+
+func newRenderCache(capacity int, ttl time.Duration) *renderCache {
+	c := &renderCache{
+		pages:      newLRUCache[*renderedPage](capacity, ttl),
+		dependents: make(map[string]map[string]struct{}),
+	}
+	c.pages.onEvict = c.forget
+	return c
+}
+
+// Get returns the cached render for slug.
+func (c *renderCache) Get(slug string) (*renderedPage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.pages.Get(slug)
+}
+
+// Put caches a render, replacing any previous entry for the same slug.
+func (c *renderCache) Put(page *renderedPage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pages.Add(page.Slug, page)
+	for _, target := range page.missing {
+		deps := c.dependents[target]
+		if deps == nil {
+			deps = make(map[string]struct{})
+			c.dependents[target] = deps
+		}
+		deps[page.Slug] = struct{}{}
+	}
+}
+
+// Invalidate drops the entry for slug and every entry that rendered slug as a
+// missing link.
+func (c *renderCache) Invalidate(slug string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.pages.Remove(slug)
+	for dependent := range c.dependents[slug] {
+		c.pages.Remove(dependent)
+	}
+	delete(c.dependents, slug)
+}
+
+// forget removes an evicted entry from the dependency index. It runs with c.mu
+// held, from inside the LRU.
+func (c *renderCache) forget(slug string, page *renderedPage) {
+	for _, target := range page.missing {
+		deps := c.dependents[target]
+		delete(deps, slug)
+		if len(deps) == 0 {
+			delete(c.dependents, target)
+		}
+	}
+}
+
+func newRenderedPage(page *Page, body string, missing map[string]struct{}, now time.Time) *renderedPage {
+	sum := sha256.Sum256([]byte(body))
+	rendered := &renderedPage{
+		Slug:         page.Slug,
+		Title:        page.DisplayTitle(),
+		Body:         body,
+		ETag:         `W/"` + hex.EncodeToString(sum[:12]) + `"`,
+		LastModified: now.UTC().Truncate(time.Second),
+		headingEnd:   headingEnd(body),
+	}
+	if len(missing) == 0 && !page.CreatedAt.IsZero() {
+		// Nothing on the page can change colour, so the row timestamp is exact.
+		modified := page.CreatedAt
+		if page.UpdatedAt.After(modified) {
+			modified = page.UpdatedAt
+		}
+		rendered.LastModified = modified.UTC().Truncate(time.Second)
+	}
+	for slug := range missing {
+		rendered.missing = append(rendered.missing, slug)
+	}
+	return rendered
+}
+
+// foldETag derives a new weak ETag from etag and extra state rendered
+// outside the body.
+func foldETag(etag string, extras ...string) string {
+	if len(extras) == 0 {
+		return etag
+	}
+	h := sha256.New()
+	h.Write([]byte(etag))
+	for _, extra := range extras {
+		h.Write([]byte{0})
+		h.Write([]byte(extra))
+	}
+	return `W/"` + hex.EncodeToString(h.Sum(nil)[:12]) + `"`
+}
+
+// headingEnd returns the offset just past the first </h1> in body, or 0.
+func headingEnd(body string) int {
+	const closing = "</h1>"
+	for i := strings.IndexByte(body, '<'); i >= 0 && i+len(closing) <= len(body); {
+		if strings.EqualFold(body[i:i+len(closing)], closing) {
+			return i + len(closing)
+		}
+		next := strings.IndexByte(body[i+1:], '<')
+		if next < 0 {
+			break
+		}
+		i += 1 + next
+	}
+	return 0
+}
+
+// notModified reports whether the request's validators match the render. The
+// ETag is weak because the surrounding chrome (page count) may differ.
+func notModified(r *http.Request, page *renderedPage) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimSpace(candidate)
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(page.ETag, "W/") {
+				return true
+			}
+		}
+		return false
+	}
+	if since := r.Header.Get("If-Modified-Since"); since != "" {
+		t, err := http.ParseTime(since)
+		if err == nil && !page.LastModified.After(t) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRenderCacheInvalidatesPagesLinkingToCreatedSlug(t *testing.T) {
+	cache := newRenderCache(10, 0)
+	now := time.Now()
+	cache.Put(newRenderedPage(&Page{Slug: "a"}, "a", map[string]struct{}{"new_topic": {}}, now))
+	cache.Put(newRenderedPage(&Page{Slug: "b"}, "b", map[string]struct{}{"other": {}}, now))
+	cache.Put(newRenderedPage(&Page{Slug: "c"}, "c", nil, now))
+
+	cache.Invalidate("new_topic")
+
+	if _, ok := cache.Get("a"); ok {
+		t.Fatalf("page linking to created slug should be invalidated")
+	}
+	for _, slug := range []string{"b", "c"} {
+		if _, ok := cache.Get(slug); !ok {
+			t.Fatalf("unrelated page %q should stay cached", slug)
+		}
+	}
+}
+
+func TestRenderCacheEvictionForgetsDependencies(t *testing.T) {
+	cache := newRenderCache(1, 0)
+	now := time.Now()
+	cache.Put(newRenderedPage(&Page{Slug: "a"}, "a", map[string]struct{}{"x": {}}, now))
+	cache.Put(newRenderedPage(&Page{Slug: "b"}, "b", nil, now))
+
+	if _, ok := cache.Get("a"); ok {
+		t.Fatalf("expected least recently used entry to be evicted")
+	}
+	if len(cache.dependents) != 0 {
+		t.Fatalf("evicted entry left dependencies behind: %v", cache.dependents)
+	}
+}
+
+func TestLRUCacheExpiresEntries(t *testing.T) {
+	cache := newLRUCache[int](4, time.Minute)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	cache.Add("k", 1)
+
+	now = now.Add(2 * time.Minute)
+	if _, ok := cache.Get("k"); ok {
+		t.Fatalf("expected entry to expire")
+	}
+}
+
+func TestNotModified(t *testing.T) {
+	page := newRenderedPage(&Page{Slug: "a"}, "body", map[string]struct{}{"x": {}}, time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC))
+
+	req := httptest.NewRequest(http.MethodGet, "/wiki/a", nil)
+	req.Header.Set("If-None-Match", page.ETag)
+	if !notModified(req, page) {
+		t.Fatalf("matching ETag should be not modified")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/wiki/a", nil)
+	req.Header.Set("If-None-Match", `"other"`)
+	req.Header.Set("If-Modified-Since", page.LastModified.Format(http.TimeFormat))
+	if notModified(req, page) {
+		t.Fatalf("If-None-Match mismatch must win over If-Modified-Since")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/wiki/a", nil)
+	req.Header.Set("If-Modified-Since", page.LastModified.Add(-time.Hour).Format(http.TimeFormat))
+	if notModified(req, page) {
+		t.Fatalf("older If-Modified-Since should be modified")
+	}
+}
+
+func TestFoldETagChangesWithExtras(t *testing.T) {
+	base := `W/"abc"`
+	if got := foldETag(base); got != base {
+		t.Fatalf("foldETag without extras = %q", got)
+	}
+	if got := foldETag(base, "history"); got == base {
+		t.Fatalf("foldETag ignored extras")
+	}
+}
+
+func TestHeadingEnd(t *testing.T) {
+	body := "<H1>Rome</H1>\n<p>Body</p>"
+	if got := headingEnd(body); body[:got] != "<H1>Rome</H1>" {
+		t.Fatalf("headingEnd = %d (%q)", got, body[:got])
+	}
+	if got := headingEnd("<p>No heading</p>"); got != 0 {
+		t.Fatalf("headingEnd without heading = %d", got)
+	}
+}
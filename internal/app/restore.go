@@ -0,0 +1,423 @@
+package app
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"time"
+)
+
+// checkpointEvery is how many records restore applies between checkpoints.
+const checkpointEvery = 100
+
+// errInvalidRecord marks a dump record that cannot be restored however often
+// the restore is retried, such as one naming an impossible slug.
+var errInvalidRecord = errors.New("invalid record")
+
+// ConflictPolicy decides what restore does with a page, redirect, or category
+// that already exists.
+type ConflictPolicy string
+
+const (
+	// ConflictSkip keeps what is stored.
+	ConflictSkip ConflictPolicy = "skip"
+	// ConflictOverwrite replaces it with the dump.
+	ConflictOverwrite ConflictPolicy = "overwrite"
+	// ConflictKeepNewer replaces pages and redirects the dump has a newer
+	// version of, by last modification time. Categories, which carry no
+	// timestamps, are kept.
+	ConflictKeepNewer ConflictPolicy = "keep-newer"
+)
+
+// ParseConflictPolicy validates a -conflict flag.
+func ParseConflictPolicy(value string) (ConflictPolicy, error) {
+	switch policy := ConflictPolicy(value); policy {
+	case ConflictSkip, ConflictOverwrite, ConflictKeepNewer:
+		return policy, nil
+	}
+	return "", fmt.Errorf("conflict policy %q must be skip, overwrite, or keep-newer", value)
+}
+
+// replaces reports whether a record modified at incoming replaces a stored one
+// modified at stored.
+func (p ConflictPolicy) replaces(stored, incoming time.Time) bool {
+	switch p {
+	case ConflictOverwrite:
+		return true
+	case ConflictKeepNewer:
+		return incoming.After(stored)
+	}
+	return false
+}
+
+// RestoreOptions configures RestoreWiki.
+type RestoreOptions struct {
+	Conflict ConflictPolicy
+	Filter   DateFilter
+	// Checkpoint, when set, is a file recording how far the restore got. An
+	// interrupted restore run again with the same checkpoint resumes after
+	// the last record it applied; the file is removed once the restore
+	// completes.
+	Checkpoint string
+}
+
+// RestoreStats reports what RestoreWiki did.
+type RestoreStats struct {
+	Pages      int
+	Redirects  int
+	Categories int
+	// Skipped counts records left alone because of the conflict policy.
+	Skipped int
+	// Filtered counts records outside the date filter.
+	Filtered int
+	// Resumed is the number of records a checkpoint let the restore skip.
+	Resumed int
+	// Invalid counts records that could not be restored and were logged.
+	Invalid int
+}
+
+// restoreCheckpoint is the content of a checkpoint file. The dump's creation
+// time identifies the dump it belongs to.
+type restoreCheckpoint struct {
+	DumpCreatedAt time.Time `json:"dump_created_at"`
+	Records       int       `json:"records"`
+}
+
+// RestoreWiki loads a dump written by DumpWiki, plain or gzipped, into db.
+// Each page is restored in its own transaction together with its links,
+// categories, infobox, facts, revisions, and language links.
+func RestoreWiki(ctx context.Context, db *sql.DB, r io.Reader, opts RestoreOptions) (RestoreStats, error) {
+	var stats RestoreStats
+	if opts.Conflict == "" {
+		opts.Conflict = ConflictSkip
+	}
+	in, err := openDumpReader(r)
+	if err != nil {
+		return stats, err
+	}
+	dec := json.NewDecoder(in)
+
+	header, err := readDumpHeader(dec)
+	if err != nil {
+		return stats, err
+	}
+	resume := 0
+	if opts.Checkpoint != "" {
+		cp, ok, err := readCheckpoint(opts.Checkpoint)
+		if err != nil {
+			return stats, err
+		}
+		if ok {
+			if !cp.DumpCreatedAt.Equal(header.CreatedAt) {
+				return stats, fmt.Errorf("checkpoint %s belongs to the dump of %s, not %s", opts.Checkpoint, cp.DumpCreatedAt.Format(time.RFC3339), header.CreatedAt.Format(time.RFC3339))
+			}
+			resume = cp.Records
+		}
+	}
+
+	for n := 1; ; n++ {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return stats, fmt.Errorf("record %d: %w", n, err)
+		}
+		if n <= resume {
+			stats.Resumed++
+			continue
+		}
+		if err := restoreRecord(ctx, db, raw, opts, &stats); errors.Is(err, errInvalidRecord) {
+			log.Printf("restore: skipping record %d: %v", n, err)
+			stats.Invalid++
+		} else if err != nil {
+			return stats, fmt.Errorf("record %d: %w", n, err)
+		}
+		if opts.Checkpoint != "" && n%checkpointEvery == 0 {
+			if err := writeCheckpoint(opts.Checkpoint, restoreCheckpoint{DumpCreatedAt: header.CreatedAt, Records: n}); err != nil {
+				return stats, err
+			}
+		}
+	}
+
+	if opts.Checkpoint != "" {
+		if err := os.Remove(opts.Checkpoint); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+// openDumpReader returns r, decompressed if it is gzipped.
+func openDumpReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	magic, err := br.Peek(2)
+	if err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		return gzip.NewReader(br)
+	}
+	return br, nil
+}
+
+func readDumpHeader(dec *json.Decoder) (dumpHeader, error) {
+	var header dumpHeader
+	if err := dec.Decode(&header); err != nil {
+		return header, fmt.Errorf("read dump header: %w", err)
+	}
+	if header.Type != "header" || header.Format != dumpFormat {
+		return header, errors.New("not an EndlessWiki dump")
+	}
+	if header.Version > dumpVersion {
+		return header, fmt.Errorf("dump version %d is newer than this build supports (%d)", header.Version, dumpVersion)
+	}
+	return header, nil
+}
+
+func restoreRecord(ctx context.Context, db *sql.DB, raw json.RawMessage, opts RestoreOptions, stats *RestoreStats) error {
+	var kind struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(raw, &kind); err != nil {
+		return fmt.Errorf("%w: %w", errInvalidRecord, err)
+	}
+
+	var applied bool
+	var err error
+	switch kind.Type {
+	case "category":
+		var c dumpCategory
+		if err := json.Unmarshal(raw, &c); err != nil {
+			return fmt.Errorf("%w: %w", errInvalidRecord, err)
+		}
+		if applied, err = restoreCategory(ctx, db, c, opts.Conflict); applied {
+			stats.Categories++
+		}
+	case "redirect":
+		var rd dumpRedirect
+		if err := json.Unmarshal(raw, &rd); err != nil {
+			return fmt.Errorf("%w: %w", errInvalidRecord, err)
+		}
+		if !opts.Filter.match(rd.CreatedAt) {
+			stats.Filtered++
+			return nil
+		}
+		if applied, err = restoreRedirect(ctx, db, rd, opts.Conflict); applied {
+			stats.Redirects++
+		}
+	case "page":
+		var p dumpPage
+		if err := json.Unmarshal(raw, &p); err != nil {
+			return fmt.Errorf("%w: %w", errInvalidRecord, err)
+		}
+		if !opts.Filter.match(p.CreatedAt) {
+			stats.Filtered++
+			return nil
+		}
+		if applied, err = restorePage(ctx, db, &p, opts.Conflict); applied {
+			stats.Pages++
+		}
+	default:
+		return fmt.Errorf("unknown record type %q", kind.Type)
+	}
+	if err == nil && !applied {
+		stats.Skipped++
+	}
+	return err
+}
+
+// checkDumpSlug rejects slugs that the wiki could never have produced.
+func checkDumpSlug(slug string) error {
+	normalized, err := NormalizeSlug(slug)
+	if err != nil {
+		return fmt.Errorf("%w: slug %q: %w", errInvalidRecord, slug, err)
+	}
+	if normalized != slug {
+		return fmt.Errorf("%w: slug %q is not normalised (expected %q)", errInvalidRecord, slug, normalized)
+	}
+	return nil
+}
+
+func restoreCategory(ctx context.Context, db *sql.DB, c dumpCategory, policy ConflictPolicy) (bool, error) {
+	parent, err := categoryParent(ctx, db, c.Name, c.Parent)
+	if err != nil {
+		return false, err
+	}
+	upsert := `INSERT INTO categories (name, title, parent) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE parent = COALESCE(parent, VALUES(parent))`
+	if policy == ConflictOverwrite {
+		upsert = `INSERT INTO categories (name, title, parent) VALUES (?, ?, ?)
+			ON DUPLICATE KEY UPDATE title = VALUES(title), parent = VALUES(parent)`
+	}
+	res, err := db.ExecContext(ctx, upsert, c.Name, c.Title, parent)
+	if err != nil {
+		return false, err
+	}
+	// MySQL reports 0 affected rows when an existing row was left unchanged.
+	affected, err := res.RowsAffected()
+	return err == nil && affected > 0, err
+}
+
+func restoreRedirect(ctx context.Context, db *sql.DB, rd dumpRedirect, policy ConflictPolicy) (bool, error) {
+	for _, slug := range []string{rd.Alias, rd.Target} {
+		if err := checkDumpSlug(slug); err != nil {
+			return false, err
+		}
+	}
+	var stored time.Time
+	err := db.QueryRowContext(ctx, `SELECT created_at FROM redirects WHERE alias_slug = ?`, rd.Alias).Scan(&stored)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+	case err != nil:
+		return false, err
+	case !policy.replaces(stored, rd.CreatedAt):
+		return false, nil
+	}
+
+	const upsert = `INSERT INTO redirects (alias_slug, target_slug, created_at) VALUES (?, ?, ?)
+		ON DUPLICATE KEY UPDATE target_slug = VALUES(target_slug), created_at = VALUES(created_at)`
+	_, err = db.ExecContext(ctx, upsert, rd.Alias, rd.Target, rd.CreatedAt)
+	return err == nil, err
+}
+
+func restorePage(ctx context.Context, db *sql.DB, p *dumpPage, policy ConflictPolicy) (bool, error) {
+	if err := checkDumpSlug(p.Slug); err != nil {
+		return false, err
+	}
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var created time.Time
+	var updated sql.NullTime
+	err = tx.QueryRowContext(ctx, `SELECT created_at, updated_at FROM pages WHERE slug = ? FOR UPDATE`, p.Slug).Scan(&created, &updated)
+	exists := err == nil
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return false, err
+	}
+	if exists {
+		stored := created
+		if updated.Valid && updated.Time.After(stored) {
+			stored = updated.Time
+		}
+		if !policy.replaces(stored, p.modified()) {
+			return false, nil
+		}
+	}
+
+	var updatedAt any
+	if p.UpdatedAt != nil {
+		updatedAt = *p.UpdatedAt
+	}
+	if exists {
+		const update = `UPDATE pages SET title = ?, content = ?, created_at = ?, updated_at = ? WHERE slug = ?`
+		_, err = tx.ExecContext(ctx, update, p.Title, p.Content, p.CreatedAt, updatedAt, p.Slug)
+	} else {
+		const insert = `INSERT INTO pages (slug, title, content, created_at, updated_at) VALUES (?, ?, ?, ?, ?)`
+		_, err = tx.ExecContext(ctx, insert, p.Slug, p.Title, p.Content, p.CreatedAt, updatedAt)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := replacePageLinks(ctx, tx, p.Slug, p.Content); err != nil {
+		return false, err
+	}
+	if err := restorePageDetails(ctx, tx, p); err != nil {
+		return false, err
+	}
+	return true, tx.Commit()
+}
+
+// restorePageDetails replaces the categories, infobox, facts, revisions, and
+// language links of p with those in the dump.
+func restorePageDetails(ctx context.Context, tx *sql.Tx, p *dumpPage) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM page_categories WHERE page_slug = ?`, p.Slug); err != nil {
+		return err
+	}
+	for _, category := range p.Categories {
+		// Category records come first in a dump; this only covers dumps
+		// that were edited by hand.
+		if _, err := tx.ExecContext(ctx, `INSERT IGNORE INTO categories (name, title) VALUES (?, ?)`, category, SlugTitle(category)); err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `INSERT IGNORE INTO page_categories (page_slug, category) VALUES (?, ?)`, p.Slug, category); err != nil {
+			return err
+		}
+	}
+
+	var box *infobox
+	if p.Infobox != nil {
+		box = &infobox{Type: p.Infobox.Type}
+		for _, fact := range p.Infobox.Facts {
+			box.Facts = append(box.Facts, infoboxFact{Field: fact.Field, Value: fact.Value, Number: fact.Number, Slug: fact.Slug})
+		}
+	}
+	if err := replaceInfobox(ctx, tx, p.Slug, box); err != nil {
+		return err
+	}
+
+	facts := make([]loreFact, 0, len(p.Facts))
+	for _, fact := range p.Facts {
+		facts = append(facts, loreFact{Source: p.Slug, Subject: fact.Subject, Attribute: fact.Attribute, Value: fact.Value, Key: fact.Key})
+	}
+	if err := replacePageFacts(ctx, tx, p.Slug, facts); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM page_revisions WHERE page_slug = ?`, p.Slug); err != nil {
+		return err
+	}
+	for _, rev := range p.Revisions {
+		const insert = `INSERT INTO page_revisions (page_slug, content, summary, created_at) VALUES (?, ?, ?, ?)`
+		if _, err := tx.ExecContext(ctx, insert, p.Slug, rev.Content, rev.Summary, rev.CreatedAt); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM langlinks WHERE page_slug = ?`, p.Slug); err != nil {
+		return err
+	}
+	for lang, target := range p.LangLinks {
+		if err := saveLangLink(ctx, tx, p.Slug, lang, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readCheckpoint(path string) (restoreCheckpoint, bool, error) {
+	var cp restoreCheckpoint
+	buf, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cp, false, nil
+	}
+	if err != nil {
+		return cp, false, err
+	}
+	if err := json.Unmarshal(buf, &cp); err != nil {
+		return cp, false, fmt.Errorf("checkpoint %s: %w", path, err)
+	}
+	return cp, true, nil
+}
+
+// writeCheckpoint replaces the checkpoint file atomically, so a crash leaves
+// either the old or the new position.
+func writeCheckpoint(path string, cp restoreCheckpoint) error {
+	buf, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, buf, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,222 @@
+package app
+
+import (
+	"html/template"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template/parse"
+
+	nethtml "golang.org/x/net/html"
+)
+
+// minTOCEntries is the fewest section headings for which a table of contents
+// is shown.
+const minTOCEntries = 3
+
+// tocEntry is one section in the table of contents. <h3> sections nest under
+// the preceding <h2>.
+type tocEntry struct {
+	ID       string
+	Text     string
+	Number   string
+	Children []tocEntry
+}
+
+// templateIDPattern matches an id attribute written out in template text.
+var templateIDPattern = regexp.MustCompile(`\bid="([^"{]+)"`)
+
+// templateIDs returns the literal element ids in every template of tmpl, which
+// section ids must not reuse.
+func templateIDs(tmpl *template.Template) map[string]struct{} {
+	ids := make(map[string]struct{})
+	for _, t := range tmpl.Templates() {
+		if t.Tree == nil {
+			continue
+		}
+		eachTemplateText(t.Tree.Root, func(text []byte) {
+			for _, m := range templateIDPattern.FindAllSubmatch(text, -1) {
+				ids[string(m[1])] = struct{}{}
+			}
+		})
+	}
+	return ids
+}
+
+// eachTemplateText calls fn with the text of every text node under node.
+func eachTemplateText(node parse.Node, fn func([]byte)) {
+	switch n := node.(type) {
+	case *parse.TextNode:
+		fn(n.Text)
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			eachTemplateText(child, fn)
+		}
+	case *parse.IfNode:
+		eachTemplateText(n.List, fn)
+		eachTemplateText(n.ElseList, fn)
+	case *parse.RangeNode:
+		eachTemplateText(n.List, fn)
+		eachTemplateText(n.ElseList, fn)
+	case *parse.WithNode:
+		eachTemplateText(n.List, fn)
+		eachTemplateText(n.ElseList, fn)
+	}
+}
+
+// addSectionAnchors gives every <h2> and <h3> without an id one derived from
+// its text, so /wiki/slug#section links resolve. Generated ids avoid reserved,
+// the ids of the page templates, and the ids of the missing links in body. It returns the rewritten
+// body, the table of contents (nil below minTOCEntries headings), and the
+// offset of the first section heading in the rewritten body.
+func addSectionAnchors(body string, reserved, missing map[string]struct{}) (string, []tocEntry, int) {
+	z := nethtml.NewTokenizer(strings.NewReader(body))
+	var b strings.Builder
+	b.Grow(len(body) + 256)
+
+	type heading struct {
+		level int
+		id    string
+		text  string
+	}
+	var headings []heading
+	used := make(map[string]struct{}, len(reserved)+len(missing))
+	for id := range reserved {
+		used[id] = struct{}{}
+	}
+	for slug := range missing {
+		used[missingLinkID(slug)] = struct{}{}
+	}
+	firstAt := -1
+
+	// Heading tokens are buffered until the end tag so the id can be derived
+	// from the full text before the start tag is written.
+	var open *heading
+	var startAttrs []nethtml.Attribute
+	var startRaw string
+	var inner strings.Builder
+	var text strings.Builder
+
+	for {
+		tt := z.Next()
+		if tt == nethtml.ErrorToken {
+			break
+		}
+		raw := string(z.Raw())
+
+		if open != nil {
+			if tt == nethtml.EndTagToken {
+				if name, _ := z.TagName(); string(name) == "h"+strconv.Itoa(open.level) {
+					open.text = strings.Join(strings.Fields(text.String()), " ")
+					if firstAt < 0 {
+						firstAt = b.Len()
+					}
+					if open.id == "" {
+						open.id = uniqueSectionID(open.text, used)
+						startAttrs = append(startAttrs, nethtml.Attribute{Key: "id", Val: open.id})
+						writeTag(&b, "h"+strconv.Itoa(open.level), startAttrs, false)
+					} else {
+						b.WriteString(startRaw)
+					}
+					b.WriteString(inner.String())
+					b.WriteString(raw)
+					headings = append(headings, *open)
+					open = nil
+					continue
+				}
+			}
+			if tt == nethtml.TextToken {
+				text.Write(z.Text())
+				text.WriteByte(' ')
+			}
+			inner.WriteString(raw)
+			continue
+		}
+
+		if tt == nethtml.StartTagToken {
+			name, hasAttr := z.TagName()
+			if level := sectionLevel(string(name)); level > 0 {
+				var attrs []nethtml.Attribute
+				if hasAttr {
+					attrs = readAttrs(z)
+				}
+				h := heading{level: level}
+				for _, attr := range attrs {
+					if attr.Key == "id" && attr.Val != "" {
+						h.id = attr.Val
+						used[attr.Val] = struct{}{}
+					}
+				}
+				open, startAttrs, startRaw = &h, attrs, raw
+				inner.Reset()
+				text.Reset()
+				continue
+			}
+		}
+		b.WriteString(raw)
+	}
+	if open != nil {
+		// Unterminated heading: keep the markup as written.
+		b.WriteString(startRaw)
+		b.WriteString(inner.String())
+	}
+
+	if len(headings) < minTOCEntries {
+		return b.String(), nil, firstAt
+	}
+
+	var toc []tocEntry
+	for _, h := range headings {
+		entry := tocEntry{ID: h.id, Text: h.text}
+		if h.level == 3 && len(toc) > 0 {
+			parent := &toc[len(toc)-1]
+			entry.Number = parent.Number + "." + strconv.Itoa(len(parent.Children)+1)
+			parent.Children = append(parent.Children, entry)
+			continue
+		}
+		entry.Number = strconv.Itoa(len(toc) + 1)
+		toc = append(toc, entry)
+	}
+	return b.String(), toc, firstAt
+}
+
+func sectionLevel(tag string) int {
+	switch tag {
+	case "h2":
+		return 2
+	case "h3":
+		return 3
+	}
+	return 0
+}
+
+// uniqueSectionID derives an id like "early_life" from heading text, adding
+// "_2", "_3", ... when the same heading appears more than once.
+func uniqueSectionID(text string, used map[string]struct{}) string {
+	// Characters NormalizeSlug rejects outright are dropped or spaced out.
+	cleaned := strings.Map(func(r rune) rune {
+		switch r {
+		case '\'', '"':
+			return -1
+		case '/', '\\', '?', '&', ':', '#', '.':
+			return ' '
+		}
+		return r
+	}, text)
+	base, err := NormalizeSlug(cleaned)
+	if err != nil {
+		base = "section"
+	}
+	id := base
+	for n := 2; ; n++ {
+		if _, taken := used[id]; !taken {
+			break
+		}
+		id = base + "_" + strconv.Itoa(n)
+	}
+	used[id] = struct{}{}
+	return id
+}
@@ -0,0 +1,112 @@
+package app
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAddSectionAnchors(t *testing.T) {
+	body := `<h1>Rome</h1><p>Intro</p>` +
+		`<h2>Early <i>life</i></h2><p>a</p>` +
+		`<h3>Childhood</h3><p>b</p>` +
+		`<h2 id="reign">Reign</h2><p>c</p>` +
+		`<h2>What's next?</h2>` +
+		`<h2>Early life</h2>`
+
+	out, toc, firstAt := addSectionAnchors(body, nil, nil)
+
+	for _, want := range []string{
+		`<h2 id="early_life">Early <i>life</i></h2>`,
+		`<h3 id="childhood">Childhood</h3>`,
+		`<h2 id="reign">Reign</h2>`,
+		`<h2 id="whats_next">What's next?</h2>`,
+		`<h2 id="early_life_2">Early life</h2>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %s:\n%s", want, out)
+		}
+	}
+	if !strings.HasPrefix(out[firstAt:], `<h2 id="early_life">`) {
+		t.Errorf("firstAt = %d points at %q", firstAt, out[firstAt:])
+	}
+
+	want := []tocEntry{
+		{ID: "early_life", Text: "Early life", Number: "1", Children: []tocEntry{
+			{ID: "childhood", Text: "Childhood", Number: "1.1"},
+		}},
+		{ID: "reign", Text: "Reign", Number: "2"},
+		{ID: "whats_next", Text: "What's next?", Number: "3"},
+		{ID: "early_life_2", Text: "Early life", Number: "4"},
+	}
+	if !reflect.DeepEqual(toc, want) {
+		t.Fatalf("toc = %+v, want %+v", toc, want)
+	}
+}
+
+func TestAddSectionAnchorsShortPage(t *testing.T) {
+	out, toc, firstAt := addSectionAnchors(`<h1>Rome</h1><p>Intro</p><h2>History</h2><p>a</p>`, nil, nil)
+	if toc != nil {
+		t.Fatalf("toc = %+v, want nil below %d headings", toc, minTOCEntries)
+	}
+	if !strings.Contains(out, `<h2 id="history">History</h2>`) {
+		t.Fatalf("heading not anchored: %s", out)
+	}
+	if firstAt != strings.Index(out, "<h2") {
+		t.Fatalf("firstAt = %d", firstAt)
+	}
+
+	if _, _, firstAt := addSectionAnchors(`<h1>Rome</h1><p>Intro</p>`, nil, nil); firstAt != -1 {
+		t.Fatalf("firstAt without sections = %d, want -1", firstAt)
+	}
+}
+
+func TestAddSectionAnchorsAvoidsPageIDs(t *testing.T) {
+	srv, err := NewServer(nil, Config{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	for _, id := range []string{"content", "catlinks", "mw-head", "search-heading"} {
+		if _, ok := srv.templateIDs[id]; !ok {
+			t.Errorf("template ids missing %s", id)
+		}
+	}
+	body := `<h2>Content</h2><h2>Catlinks</h2><h2>TOC</h2>`
+	out, _, _ := addSectionAnchors(body, srv.templateIDs, map[string]struct{}{"atlantis": {}})
+	for _, want := range []string{`id="content_2"`, `id="catlinks_2"`, `id="toc"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %s:\n%s", want, out)
+		}
+	}
+}
+
+func TestWikiTemplateRendersTOC(t *testing.T) {
+	srv, err := NewServer(nil, Config{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	data := map[string]any{
+		"Title":   "Rome",
+		"Slug":    "rome",
+		"Content": "",
+		"TOC": []tocEntry{
+			{ID: "history", Text: "History", Number: "1", Children: []tocEntry{
+				{ID: "founding", Text: "Founding", Number: "1.1"},
+			}},
+		},
+	}
+
+	var b strings.Builder
+	if err := srv.templates.ExecuteTemplate(&b, "wiki.gohtml", data); err != nil {
+		t.Fatalf("render wiki: %v", err)
+	}
+	for _, want := range []string{
+		`<details class="toc" open>`,
+		`<a href="#history"><span class="tocnumber">1</span> History</a>`,
+		`<a href="#founding"><span class="tocnumber">1.1</span> Founding</a>`,
+	} {
+		if !strings.Contains(b.String(), want) {
+			t.Errorf("toc missing %s", want)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"html/template"
 	"log"
 	"net"
@@ -20,14 +21,23 @@ import (
 
 // Server wires handlers, templates, and external dependencies together.
 type Server struct {
-	cfg        Config
-	db         *sql.DB
-	templates  *template.Template
-	httpClient *http.Client
-	mux        *http.ServeMux
-	genGroup   singleflight.Group
-	limitMu    sync.Mutex
-	limits     map[string]*rateRecord
+	cfg           Config
+	db            *sql.DB
+	templates     *template.Template
+	templateIDs   map[string]struct{}
+	httpClient    *http.Client
+	mux           *http.ServeMux
+	genGroup      singleflight.Group
+	limitMu       sync.Mutex
+	limits        map[string]*rateRecord
+	renders       *renderCache
+	previews      *previewCache
+	counter       *pageCounter
+	stats         statsCache
+	constellation constellationCache
+	frontier      *frontierIndex
+	pages         *pageIndex
+	editions      *editionSet
 }
 
 type rateRecord struct {
@@ -47,21 +57,41 @@ var ErrDuplicatePage = errors.New("duplicate page")
 func NewServer(db *sql.DB, cfg Config) (*Server, error) {
 	tmpl, err := template.New("base").Funcs(template.FuncMap{
 		"slugTitle": SlugTitle,
-	}).ParseFS(templateFS, "templates/wiki.gohtml", "templates/search.gohtml")
+		"percent":   percent,
+		"inc":       func(n int) int { return n + 1 },
+		// base prefixes root-relative links for worlds served under /w/ and
+		// editions served under /{lang}/.
+		"base":          func() string { return cfg.BasePath },
+		"link":          func(p string) string { return cfg.BasePath + p },
+		"static":        func() bool { return false },
+		"constellation": cfg.hasConstellation,
+		"lang":          cfg.lang,
+		"t":             translator(cfg.lang()),
+	}).ParseFS(templateFS, "templates/*.gohtml")
 	if err != nil {
 		return nil, err
 	}
 
 	srv := &Server{
-		cfg:       cfg,
-		db:        db,
-		templates: tmpl,
+		cfg:         cfg,
+		db:          db,
+		templates:   tmpl,
+		templateIDs: templateIDs(tmpl),
 		httpClient: &http.Client{
 			Timeout: 45 * time.Second,
 		},
-		mux:    http.NewServeMux(),
-		limits: make(map[string]*rateRecord),
-	}
+		mux:      http.NewServeMux(),
+		limits:   make(map[string]*rateRecord),
+		renders:  newRenderCache(renderCacheSize, renderCacheTTL),
+		previews: newPreviewCache(previewCacheSize, previewCacheTTL),
+	}
+	srv.counter = newPageCounter(pageCountRefreshInterval, srv.countPages)
+	srv.pages = newPageIndex(func(ctx context.Context) ([]autolinkTarget, error) {
+		return loadAutolinkTargets(ctx, srv.db)
+	})
+	srv.frontier = newFrontierIndex(func(ctx context.Context) (map[string][]string, error) {
+		return loadFrontier(ctx, srv.db)
+	})
 
 	srv.mux.HandleFunc("/", srv.handleIndex)
 	srv.mux.HandleFunc("/wiki/", srv.handleWiki)
@@ -70,12 +100,24 @@ func NewServer(db *sql.DB, cfg Config) (*Server, error) {
 	srv.mux.HandleFunc("/constellation", srv.handleConstellation)
 	srv.mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("static"))))
 	srv.mux.HandleFunc("/search", srv.handleSearch)
+	srv.mux.HandleFunc("/stats", srv.handleStats)
+	srv.mux.HandleFunc("/frontier/random", srv.handleFrontierRandom)
+	srv.mux.HandleFunc("/special/", srv.handleSpecial)
+	srv.mux.HandleFunc("/category/", srv.handleCategory)
+	srv.mux.HandleFunc("/api/infoboxes", srv.handleInfoboxAPI)
+	srv.mux.HandleFunc("/api/preview/", srv.handlePreview)
+	srv.mux.HandleFunc("/api.php", srv.handleActionAPI)
+	srv.mux.HandleFunc("/expand/", srv.handleExpand)
+	srv.mux.HandleFunc("/admin/contradictions", srv.handleContradictions)
 
 	return srv, nil
 }
 
 // ServeHTTP satisfies http.Handler.
 func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if s.serveEdition(w, r) {
+		return
+	}
 	s.mux.ServeHTTP(w, r)
 }
 
@@ -85,7 +127,13 @@ func (s *Server) handleIndex(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	http.Redirect(w, r, "/wiki/main_page", http.StatusFound)
+	http.Redirect(w, r, s.path("/wiki/main_page"), http.StatusFound)
+}
+
+// hasConstellation reports whether the wiki has a constellation map. The
+// exported snapshot only covers the default wiki.
+func (cfg Config) hasConstellation() bool {
+	return cfg.World == "" && !cfg.Edition
 }
 
 func (s *Server) handleConstellation(w http.ResponseWriter, r *http.Request) {
@@ -93,6 +141,10 @@ func (s *Server) handleConstellation(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusMethodNotAllowed)
 		return
 	}
+	if !s.cfg.hasConstellation() {
+		http.NotFound(w, r)
+		return
+	}
 
 	http.ServeFile(w, r, "static/constellation.html")
 }
@@ -105,7 +157,7 @@ func (s *Server) handleWiki(w http.ResponseWriter, r *http.Request) {
 
 	raw := strings.TrimPrefix(r.URL.Path, "/wiki/")
 	if raw == "" {
-		http.Redirect(w, r, "/wiki/main_page", http.StatusFound)
+		http.Redirect(w, r, s.path("/wiki/main_page"), http.StatusFound)
 		return
 	}
 
@@ -129,6 +181,11 @@ func (s *Server) handleWiki(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	if cached, ok := s.renders.Get(slug); ok {
+		s.writeWikiPage(w, r, cached)
+		return
+	}
+
 	ctx := r.Context()
 	page, err := s.lookupPage(ctx, slug)
 	if err != nil {
@@ -138,7 +195,37 @@ func (s *Server) handleWiki(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if page == nil {
-		if slug != "main_page" {
+		target, err := lookupRedirect(ctx, s.db, slug)
+		if err != nil {
+			log.Printf("lookup redirect %s: %v", slug, err)
+			http.Error(w, "database error", http.StatusInternalServerError)
+			return
+		}
+		if target != "" {
+			http.Redirect(w, r, s.path(redirectURL(target, slug)), http.StatusFound)
+			return
+		}
+
+		var source *translationSource
+		from := r.URL.Query().Get("from")
+		if slug == "main_page" && s.cfg.Edition && s.editions != nil {
+			// An edition's main page is written from the parent wiki's.
+			from = s.editions.langs[0]
+		}
+		if from != "" {
+			source, err = s.loadTranslationSource(ctx, from, slug)
+			if err != nil {
+				log.Printf("translation source %s:%s: %v", from, slug, err)
+				http.Error(w, "source lookup failed", http.StatusInternalServerError)
+				return
+			}
+			if source == nil && slug != "main_page" {
+				http.Error(w, "no such article in that edition", http.StatusForbidden)
+				return
+			}
+		}
+
+		if slug != "main_page" && source == nil {
 			if originSlug == "" {
 				http.Error(w, "new pages must be reached via existing links", http.StatusForbidden)
 				return
@@ -167,7 +254,7 @@ func (s *Server) handleWiki(w http.ResponseWriter, r *http.Request) {
 		}
 
 		result, genErr, _ := s.genGroup.Do(slug, func() (interface{}, error) {
-			return s.generateAndStore(ctx, slug)
+			return s.generateAndStore(ctx, slug, source)
 		})
 		if genErr != nil {
 			log.Printf("generate page %s: %v", slug, genErr)
@@ -182,58 +269,169 @@ func (s *Server) handleWiki(w http.ResponseWriter, r *http.Request) {
 			http.Error(w, "failed to generate page", http.StatusInternalServerError)
 			return
 		}
+		if page.Slug != slug {
+			// The slug duplicated an existing page and became its alias.
+			http.Redirect(w, r, s.path(redirectURL(page.Slug, slug)), http.StatusFound)
+			return
+		}
 	}
 
-	count, err := s.pageCount(ctx)
+	s.writeWikiPage(w, r, s.renderPage(ctx, page))
+}
+
+// renderPage decorates a stored page's links and section headings, loads its
+// infobox, categories, and language links, and caches the result.
+func (s *Server) renderPage(ctx context.Context, page *Page) *renderedPage {
+	now := time.Now()
+	rendered, err := s.decoratePage(ctx, page, linkMode{}, now)
+	if rendered == nil {
+		log.Printf("render %s: %v", page.Slug, err)
+		// Render with plain links but keep it out of the cache.
+		return newRenderedPage(page, decorateInternalLinksUnder(s.cfg.BasePath, page.Content, page.Slug, nil), nil, now)
+	}
 	if err != nil {
-		log.Printf("page count: %v", err)
-		count = 0
+		log.Printf("render %s: %v", page.Slug, err)
+		// Render without the category bar but keep it out of the cache.
+		return rendered
+	}
+	languages, err := s.languageLinks(ctx, page.Slug)
+	if err != nil {
+		log.Printf("language links for %s: %v", page.Slug, err)
+		// Render without the language sidebar but keep it out of the cache.
+		return rendered
 	}
+	rendered.Languages = languages
 
-	var missing map[string]struct{}
-	linked := ExtractLinkedSlugs(page.Content)
-	if len(linked) > 0 {
-		missing, err = s.missingSlugs(ctx, linked)
-		if err != nil {
-			log.Printf("missing slugs lookup for %s: %v", page.Slug, err)
-			missing = nil
+	// Categories may be backfilled and infobox entities created without the
+	// body changing, so both count towards the ETag.
+	var extras []string
+	for _, c := range rendered.Categories {
+		extras = append(extras, c.Name)
+	}
+	if rendered.Infobox != nil {
+		for _, row := range rendered.Infobox.Rows {
+			extras = append(extras, row.Href)
 		}
 	}
+	for _, l := range languages {
+		extras = append(extras, l.Href)
+	}
+	rendered.ETag = foldETag(rendered.ETag, extras...)
+	s.renders.Put(rendered)
+	return rendered
+}
 
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	decorated := decorateInternalLinks(page.Content, page.Slug, missing)
+// linkMode decides which linked pages exist and how links are written. The
+// zero value is the live wiki: missing pages are looked up in the database
+// and links decorated for the browser.
+type linkMode struct {
+	// existing is every page of an export; links to anything else are
+	// missing.
+	existing map[string]struct{}
+	// rewrite writes the links of an export's pages.
+	rewrite func(content string, missing map[string]struct{}) string
+}
 
-	data := struct {
-		Title       string
-		Slug        string
-		Content     template.HTML
-		PageCount   int
-		SearchQuery string
-	}{
-		Title:       SlugTitle(page.Slug),
-		Slug:        page.Slug,
-		Content:     template.HTML(decorated),
-		PageCount:   count,
-		SearchQuery: "",
+// decoratePage renders page's body with section anchors and table of
+// contents, and loads its infobox and categories, resolving links as mode
+// says. When only the categories fail to load, it returns the page without
+// them together with the error.
+func (s *Server) decoratePage(ctx context.Context, page *Page, mode linkMode, now time.Time) (*renderedPage, error) {
+	box, err := loadInfobox(ctx, s.db, page.Slug)
+	if err != nil {
+		return nil, fmt.Errorf("infobox: %w", err)
 	}
 
-	if err := s.templates.ExecuteTemplate(w, "wiki.gohtml", data); err != nil {
-		log.Printf("render page %s: %v", slug, err)
+	linked := append(ExtractLinkedSlugs(page.Content), box.entitySlugs()...)
+	var missing map[string]struct{}
+	var body string
+	if mode.existing != nil {
+		missing = make(map[string]struct{})
+		for _, slug := range linked {
+			if _, ok := mode.existing[slug]; !ok {
+				missing[slug] = struct{}{}
+			}
+		}
+		body = mode.rewrite(page.Content, missing)
+	} else {
+		if missing, err = s.missingSlugs(ctx, linked); err != nil {
+			return nil, fmt.Errorf("missing slugs: %w", err)
+		}
+		body = decorateInternalLinksUnder(s.cfg.BasePath, page.Content, page.Slug, missing)
 	}
+
+	body, toc, tocAt := addSectionAnchors(body, s.templateIDs, missing)
+	rendered := newRenderedPage(page, body, missing, now)
+	if toc != nil && tocAt >= rendered.headingEnd {
+		rendered.TOC, rendered.tocAt = toc, tocAt
+	}
+	rendered.Infobox = box.view(rendered.Title, missing)
+	if rendered.Categories, err = pageCategories(ctx, s.db, page.Slug); err != nil {
+		return rendered, fmt.Errorf("categories: %w", err)
+	}
+	return rendered, nil
 }
 
-func (s *Server) handleRandomPage(w http.ResponseWriter, r *http.Request) {
-	slug, err := s.randomSlug(r.Context())
-	if err != nil {
-		log.Printf("random slug: %v", err)
-		http.Error(w, "failed to load random page", http.StatusInternalServerError)
+// writeWikiPage renders a decorated page into wiki.gohtml, answering
+// conditional requests with 304 Not Modified.
+func (s *Server) writeWikiPage(w http.ResponseWriter, r *http.Request, page *renderedPage) {
+	w.Header().Set("ETag", page.ETag)
+	w.Header().Set("Last-Modified", page.LastModified.Format(http.TimeFormat))
+	w.Header().Set("Cache-Control", "no-cache")
+	if notModified(r, page) {
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
-	if slug == "" {
-		http.Redirect(w, r, "/", http.StatusFound)
-		return
+
+	count, err := s.pageCount(r.Context())
+	if err != nil {
+		log.Printf("page count: %v", err)
+		count = 0
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	data := newWikiPageView(page, s.redirectedFrom(r, page.Slug), count)
+	if err := s.templates.ExecuteTemplate(w, "wiki.gohtml", data); err != nil {
+		log.Printf("render page %s: %v", page.Slug, err)
+	}
+}
+
+// wikiPageView is the data wiki.gohtml renders.
+type wikiPageView struct {
+	Title          string
+	Slug           string
+	Heading        template.HTML
+	Infobox        *infoboxView
+	Content        template.HTML
+	TOC            []tocEntry
+	Sections       template.HTML
+	Categories     []categoryLink
+	Languages      []languageLink
+	RedirectedFrom string
+	PageCount      int
+	SearchQuery    string
+}
+
+func newWikiPageView(page *renderedPage, redirectedFrom string, count int) wikiPageView {
+	// The infobox follows the <h1>; the table of contents precedes the first
+	// section heading.
+	lead, sections := page.Body[page.headingEnd:], ""
+	if page.TOC != nil {
+		lead, sections = page.Body[page.headingEnd:page.tocAt], page.Body[page.tocAt:]
+	}
+	return wikiPageView{
+		Title:          page.Title,
+		Slug:           page.Slug,
+		Heading:        template.HTML(page.Body[:page.headingEnd]),
+		Infobox:        page.Infobox,
+		Content:        template.HTML(lead),
+		TOC:            page.TOC,
+		Sections:       template.HTML(sections),
+		Categories:     page.Categories,
+		Languages:      page.Languages,
+		RedirectedFrom: redirectedFrom,
+		PageCount:      count,
 	}
-	http.Redirect(w, r, "/wiki/"+url.PathEscape(slug), http.StatusFound)
 }
 
 func (s *Server) allowGeneration(ip string, now time.Time) bool {
@@ -250,7 +448,7 @@ func (s *Server) allowGeneration(ip string, now time.Time) bool {
 		s.limits[ip] = rec
 	}
 
-	if rec.count >= genLimitPerWindow {
+	if rec.count >= s.genLimit() {
 		return false
 	}
 
@@ -288,38 +486,44 @@ func (s *Server) handleRecentPage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	if slug == "" {
-		http.Redirect(w, r, "/", http.StatusFound)
+		http.Redirect(w, r, s.path("/"), http.StatusFound)
 		return
 	}
-	http.Redirect(w, r, "/wiki/"+url.PathEscape(slug), http.StatusFound)
+	http.Redirect(w, r, s.path("/wiki/"+url.PathEscape(slug)), http.StatusFound)
 }
 
 func (s *Server) lookupPage(ctx context.Context, slug string) (*Page, error) {
-	const query = `SELECT slug, content, created_at FROM pages WHERE slug = ?`
+	const query = `SELECT slug, title, content, created_at, updated_at FROM pages WHERE slug = ?`
 	row := s.db.QueryRowContext(ctx, query, slug)
 	var p Page
-	if err := row.Scan(&p.Slug, &p.Content, &p.CreatedAt); err != nil {
+	var updated sql.NullTime
+	if err := row.Scan(&p.Slug, &p.Title, &p.Content, &p.CreatedAt, &updated); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
 		return nil, err
 	}
+	p.UpdatedAt = updated.Time
 	return &p, nil
 }
 
+// missingSlugs returns the slugs that have neither a page nor a redirect.
 func (s *Server) missingSlugs(ctx context.Context, slugs []string) (map[string]struct{}, error) {
 	if len(slugs) == 0 {
 		return nil, nil
 	}
 
 	placeholders := make([]string, len(slugs))
-	args := make([]any, len(slugs))
+	args := make([]any, 0, len(slugs)*2)
 	for i, slug := range slugs {
 		placeholders[i] = "?"
-		args[i] = slug
+		args = append(args, slug)
 	}
+	args = append(args, args...)
 
-	query := "SELECT slug FROM pages WHERE slug IN (" + strings.Join(placeholders, ",") + ")"
+	in := "(" + strings.Join(placeholders, ",") + ")"
+	query := "SELECT slug FROM pages WHERE slug IN " + in +
+		" UNION ALL SELECT alias_slug FROM redirects WHERE alias_slug IN " + in
 	rows, err := s.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
@@ -358,7 +562,7 @@ func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
 
 	query := strings.TrimSpace(r.FormValue("q"))
 	if query == "" {
-		http.Redirect(w, r, "/", http.StatusFound)
+		http.Redirect(w, r, s.path("/"), http.StatusFound)
 		return
 	}
 
@@ -383,7 +587,7 @@ func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
 	data := struct {
 		Query       string
-		Results     []string
+		Results     []pageLink
 		PageCount   int
 		SearchQuery string
 	}{
@@ -398,34 +602,84 @@ func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func (s *Server) insertPage(ctx context.Context, page *Page) error {
-	const insert = `INSERT INTO pages (slug, content) VALUES (?, ?)`
-	if _, err := s.db.ExecContext(ctx, insert, page.Slug, page.Content); err != nil {
+// insertPage stores a new page together with the links and metadata derived
+// from it.
+func (s *Server) insertPage(ctx context.Context, page *Page, meta pageMeta) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	const insert = `INSERT INTO pages (slug, title, content) VALUES (?, ?, ?)`
+	if _, err := tx.ExecContext(ctx, insert, page.Slug, page.Title, page.Content); err != nil {
 		var mysqlErr *mysql.MySQLError
 		if errors.As(err, &mysqlErr) && mysqlErr.Number == 1062 {
 			return ErrDuplicatePage
 		}
 		return err
 	}
-	return nil
+	if err := replacePageLinks(ctx, tx, page.Slug, page.Content); err != nil {
+		return err
+	}
+	if err := replacePageCategories(ctx, tx, page.Slug, meta.Categories); err != nil {
+		return err
+	}
+	var box *infobox
+	if meta.Infobox != nil {
+		box, err = validateInfobox(meta.Infobox)
+		if err != nil {
+			log.Printf("infobox for %s: %v", page.Slug, err)
+		} else if err := replaceInfobox(ctx, tx, page.Slug, box); err != nil {
+			return err
+		}
+	}
+	if err := replacePageFacts(ctx, tx, page.Slug, normalizeLoreFacts(page.Slug, box, meta.Facts)); err != nil {
+		return err
+	}
+	return tx.Commit()
 }
 
-func (s *Server) generateAndStore(ctx context.Context, slug string) (*Page, error) {
+// generateAndStore writes the article slug, based on source when it is a
+// translation of an article in another edition.
+func (s *Server) generateAndStore(ctx context.Context, slug string, source *translationSource) (*Page, error) {
 	var content string
+	var meta pageMeta
 	var err error
 
-	if slug == "main_page" {
-		content = mainPageHTML()
+	if slug == "main_page" && source == nil {
+		content = s.defaultMainPage()
 	} else {
-		content, err = GeneratePageHTML(ctx, s.httpClient, s.cfg, slug)
+		if canonical := s.duplicateOf(ctx, slug); canonical != "" {
+			page, err := s.aliasTo(ctx, slug, canonical)
+			if err != nil {
+				return nil, err
+			}
+			if page != nil {
+				return page, nil
+			}
+		}
+
+		lore, err := loadLoreFacts(ctx, s.db, slug, maxLoreFactsInPrompt)
+		if err != nil {
+			log.Printf("lore facts for %s: %v", slug, err)
+		}
+		content, err = GeneratePageHTML(ctx, s.httpClient, s.cfg, slug, lore, source)
 		if err != nil {
 			return nil, err
 		}
+		content, meta = splitMetaTrailer(content)
+		content = s.autolink(ctx, slug, content)
 	}
 
-	page := &Page{Slug: slug, Content: content}
-	err = s.insertPage(ctx, page)
+	page := &Page{Slug: slug, Title: ExtractTitle(content), Content: content, CreatedAt: time.Now()}
+	err = s.insertPage(ctx, page, meta)
 	if err == nil {
+		s.counter.Add(1)
+		if source != nil {
+			s.linkTranslation(ctx, page.Slug, source)
+		}
+		s.pageCreated(ctx, page)
 		return page, nil
 	}
 	if errors.Is(err, ErrDuplicatePage) {
@@ -435,6 +689,7 @@ func (s *Server) generateAndStore(ctx context.Context, slug string) (*Page, erro
 			return nil, lookupErr
 		}
 		if stored != nil {
+			s.pageCreated(ctx, stored)
 			return stored, nil
 		}
 		return nil, err
@@ -442,17 +697,51 @@ func (s *Server) generateAndStore(ctx context.Context, slug string) (*Page, erro
 	return nil, err
 }
 
-func (s *Server) randomSlug(ctx context.Context) (string, error) {
-	const query = `SELECT slug FROM pages ORDER BY RAND() LIMIT 1`
-	row := s.db.QueryRowContext(ctx, query)
-	var slug string
-	if err := row.Scan(&slug); err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return "", nil
-		}
-		return "", err
+// pageCreated updates in-process state after a page has been stored.
+func (s *Server) pageCreated(ctx context.Context, page *Page) {
+	s.renders.Invalidate(page.Slug)
+	s.previews.Invalidate(page.Slug)
+	s.frontier.Resolve(page.Slug)
+	s.pages.Add(page.Slug, page.Title)
+
+	missing, err := s.missingSlugs(ctx, ExtractLinkedSlugs(page.Content))
+	if err != nil {
+		log.Printf("frontier links for %s: %v", page.Slug, err)
+		return
 	}
-	return slug, nil
+	s.frontier.Add(page.Slug, missing)
+}
+
+// aliasTo records slug as a redirect to the existing page canonical instead of
+// generating it, returning the canonical page. It returns nil if canonical has
+// disappeared in the meantime.
+func (s *Server) aliasTo(ctx context.Context, slug, canonical string) (*Page, error) {
+	page, err := s.lookupPage(ctx, canonical)
+	if err != nil || page == nil {
+		return nil, err
+	}
+	if err := createRedirect(ctx, s.db, slug, canonical); err != nil {
+		return nil, err
+	}
+	if err := repointLoreFacts(ctx, s.db, slug, canonical); err != nil {
+		log.Printf("repoint lore facts %s: %v", slug, err)
+	}
+	log.Printf("aliased %s to existing page %s", slug, canonical)
+	s.renders.Invalidate(slug)
+	s.previews.Invalidate(slug)
+	s.frontier.Resolve(slug)
+	s.pages.Add(page.Slug, page.Title)
+	return page, nil
+}
+
+// autolink links mentions of existing pages in freshly generated content. A
+// failure to load the page index is logged and the content kept as generated.
+func (s *Server) autolink(ctx context.Context, slug, content string) string {
+	linked, err := s.pages.autolink(ctx, slug, content)
+	if err != nil {
+		log.Printf("autolink targets for %s: %v", slug, err)
+	}
+	return linked
 }
 
 func (s *Server) recentSlug(ctx context.Context) (string, error) {
@@ -469,6 +758,10 @@ func (s *Server) recentSlug(ctx context.Context) (string, error) {
 }
 
 func (s *Server) pageCount(ctx context.Context) (int, error) {
+	return s.counter.Get(ctx)
+}
+
+func (s *Server) countPages(ctx context.Context) (int, error) {
 	const query = `SELECT COUNT(*) FROM pages`
 	row := s.db.QueryRowContext(ctx, query)
 	var count int
@@ -478,25 +771,33 @@ func (s *Server) pageCount(ctx context.Context) (int, error) {
 	return count, nil
 }
 
-func (s *Server) searchPages(ctx context.Context, query string) ([]string, error) {
-	const sqlQuery = `SELECT slug FROM pages WHERE slug LIKE ? OR content LIKE ? ORDER BY created_at DESC LIMIT 20`
+// pageLink is a page listed by title, e.g. a search hit or category member.
+type pageLink struct {
+	Slug  string
+	Title string
+}
+
+func (s *Server) searchPages(ctx context.Context, query string) ([]pageLink, error) {
+	const sqlQuery = `SELECT slug, title FROM pages WHERE slug LIKE ? OR title LIKE ? OR content LIKE ? ORDER BY created_at DESC LIMIT 20`
 	like := "%" + query + "%"
-	rows, err := s.db.QueryContext(ctx, sqlQuery, like, like)
+	// Slugs compare byte for byte and are stored case-folded.
+	rows, err := s.db.QueryContext(ctx, sqlQuery, strings.ToLower(like), like, like)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var slugs []string
+	var results []pageLink
 	for rows.Next() {
-		var slug string
-		if err := rows.Scan(&slug); err != nil {
+		var result pageLink
+		if err := rows.Scan(&result.Slug, &result.Title); err != nil {
 			return nil, err
 		}
-		slugs = append(slugs, slug)
+		result.Title = DisplayTitle(result.Slug, result.Title)
+		results = append(results, result)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
 	}
-	return slugs, nil
+	return results, nil
 }
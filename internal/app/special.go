@@ -0,0 +1,224 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	specialDefaultLimit = 100
+	specialMaxLimit     = 500
+)
+
+// specialEntry is one row of a special report.
+type specialEntry struct {
+	Slug  string
+	Title string
+	// Count is the inbound link count for WantedPages.
+	Count int
+	// Origin is a page linking to Slug, so a missing entry can be generated.
+	Origin string
+}
+
+// specialReport describes a paginated report served under /special/{Name}.
+type specialReport struct {
+	Name        string
+	Title       string
+	Description string
+	Missing     bool
+	query       func(ctx context.Context, db *sql.DB, limit, offset int) ([]specialEntry, error)
+}
+
+var specialReports = []specialReport{
+	{
+		Name:        "WantedPages",
+		Title:       "Wanted pages",
+		Description: "Unwritten topics ranked by how many existing pages link to them.",
+		Missing:     true,
+		query:       wantedPages,
+	},
+	{
+		Name:        "LonelyPages",
+		Title:       "Lonely pages",
+		Description: "Pages that no other page links to.",
+		query:       lonelyPages,
+	},
+	{
+		Name:        "DeadendPages",
+		Title:       "Dead-end pages",
+		Description: "Pages without any outbound wiki links.",
+		query:       deadendPages,
+	},
+	{
+		Name:        "AllPages",
+		Title:       "All pages",
+		Description: "Every page in alphabetical order.",
+		query:       allPages,
+	},
+}
+
+func findSpecialReport(name string) (specialReport, bool) {
+	for _, report := range specialReports {
+		if strings.EqualFold(report.Name, name) {
+			return report, true
+		}
+	}
+	return specialReport{}, false
+}
+
+func wantedPages(ctx context.Context, db *sql.DB, limit, offset int) ([]specialEntry, error) {
+	const query = `SELECT l.target_slug, COUNT(*) AS inbound, MIN(l.source_slug)
+		FROM page_links l
+		LEFT JOIN pages p ON p.slug = l.target_slug
+		LEFT JOIN redirects r ON r.alias_slug = l.target_slug
+		WHERE p.slug IS NULL AND r.alias_slug IS NULL
+		GROUP BY l.target_slug
+		ORDER BY inbound DESC, l.target_slug
+		LIMIT ? OFFSET ?`
+	rows, err := db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []specialEntry
+	for rows.Next() {
+		var e specialEntry
+		if err := rows.Scan(&e.Slug, &e.Count, &e.Origin); err != nil {
+			return nil, err
+		}
+		e.Title = SlugTitle(e.Slug)
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+func lonelyPages(ctx context.Context, db *sql.DB, limit, offset int) ([]specialEntry, error) {
+	const query = `SELECT p.slug, p.title FROM pages p
+		WHERE p.slug <> 'main_page'
+		AND NOT EXISTS (SELECT 1 FROM page_links l WHERE l.target_slug = p.slug)
+		AND NOT EXISTS (SELECT 1 FROM page_links l
+			JOIN redirects r ON r.alias_slug = l.target_slug
+			WHERE r.target_slug = p.slug)
+		ORDER BY p.slug
+		LIMIT ? OFFSET ?`
+	return scanSpecialSlugs(ctx, db, query, limit, offset)
+}
+
+func deadendPages(ctx context.Context, db *sql.DB, limit, offset int) ([]specialEntry, error) {
+	const query = `SELECT p.slug, p.title FROM pages p
+		WHERE NOT EXISTS (SELECT 1 FROM page_links l WHERE l.source_slug = p.slug)
+		ORDER BY p.slug
+		LIMIT ? OFFSET ?`
+	return scanSpecialSlugs(ctx, db, query, limit, offset)
+}
+
+func allPages(ctx context.Context, db *sql.DB, limit, offset int) ([]specialEntry, error) {
+	const query = `SELECT slug, title FROM pages ORDER BY slug LIMIT ? OFFSET ?`
+	return scanSpecialSlugs(ctx, db, query, limit, offset)
+}
+
+func scanSpecialSlugs(ctx context.Context, db *sql.DB, query string, limit, offset int) ([]specialEntry, error) {
+	rows, err := db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []specialEntry
+	for rows.Next() {
+		var e specialEntry
+		if err := rows.Scan(&e.Slug, &e.Title); err != nil {
+			return nil, err
+		}
+		e.Title = DisplayTitle(e.Slug, e.Title)
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// pagination parses ?limit= and ?offset=, clamping them to sane bounds.
+func pagination(r *http.Request) (limit, offset int) {
+	limit = specialDefaultLimit
+	if v, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && v > 0 {
+		limit = min(v, specialMaxLimit)
+	}
+	if v, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && v > 0 {
+		offset = v
+	}
+	return limit, offset
+}
+
+func (s *Server) handleSpecial(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	count, err := s.pageCount(ctx)
+	if err != nil {
+		log.Printf("page count: %v", err)
+		count = 0
+	}
+
+	data := struct {
+		Reports     []specialReport
+		Report      *specialReport
+		Entries     []specialEntry
+		Offset      int
+		Limit       int
+		PrevOffset  int
+		NextOffset  int
+		HasPrev     bool
+		HasNext     bool
+		PageCount   int
+		SearchQuery string
+	}{
+		Reports:   specialReports,
+		PageCount: count,
+	}
+
+	name := strings.Trim(strings.TrimPrefix(r.URL.Path, "/special"), "/")
+	if name != "" {
+		report, ok := findSpecialReport(name)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if report.Name != name {
+			http.Redirect(w, r, s.path("/special/"+report.Name), http.StatusMovedPermanently)
+			return
+		}
+
+		limit, offset := pagination(r)
+		// Fetch one extra row to learn whether a next page exists.
+		entries, err := report.query(ctx, s.db, limit+1, offset)
+		if err != nil {
+			log.Printf("special %s: %v", report.Name, err)
+			http.Error(w, "failed to load report", http.StatusInternalServerError)
+			return
+		}
+		data.Report = &report
+		data.Limit, data.Offset = limit, offset
+		if len(entries) > limit {
+			entries = entries[:limit]
+			data.HasNext = true
+			data.NextOffset = offset + limit
+		}
+		data.Entries = entries
+		if offset > 0 {
+			data.HasPrev = true
+			data.PrevOffset = max(0, offset-limit)
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.templates.ExecuteTemplate(w, "special.gohtml", data); err != nil {
+		log.Printf("render special: %v", err)
+	}
+}
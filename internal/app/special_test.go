@@ -0,0 +1,51 @@
+package app
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFindSpecialReportIgnoresCase(t *testing.T) {
+	report, ok := findSpecialReport("wantedpages")
+	if !ok || report.Name != "WantedPages" {
+		t.Fatalf("findSpecialReport(wantedpages) = %v, %v", report.Name, ok)
+	}
+	if _, ok := findSpecialReport("Nope"); ok {
+		t.Fatalf("unexpected report for unknown name")
+	}
+}
+
+func TestPaginationClampsValues(t *testing.T) {
+	limit, offset := pagination(httptest.NewRequest("GET", "/special/AllPages?limit=9999&offset=-5", nil))
+	if limit != specialMaxLimit || offset != 0 {
+		t.Fatalf("pagination = %d, %d; want %d, 0", limit, offset, specialMaxLimit)
+	}
+}
+
+func TestSpecialTemplateLinksWantedPagesWithOrigin(t *testing.T) {
+	srv, err := NewServer(nil, Config{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	report, _ := findSpecialReport("WantedPages")
+	data := map[string]any{
+		"Report":     &report,
+		"Entries":    []specialEntry{{Slug: "atlantis", Count: 3, Origin: "oceans"}},
+		"Offset":     0,
+		"Limit":      100,
+		"HasPrev":    false,
+		"HasNext":    true,
+		"NextOffset": 100,
+		"PageCount":  1,
+	}
+
+	var b strings.Builder
+	if err := srv.templates.ExecuteTemplate(&b, "special.gohtml", data); err != nil {
+		t.Fatalf("render special: %v", err)
+	}
+	out := b.String()
+	if !strings.Contains(out, `href="/wiki/atlantis?origin=oceans"`) || !strings.Contains(out, "offset=100") {
+		t.Fatalf("wanted pages output missing origin link or pager: %s", out)
+	}
+}
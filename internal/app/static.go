@@ -0,0 +1,334 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"html/template"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// maxStaticFileName is the longest file name most filesystems accept, in
+	// bytes. Pages whose slug does not fit are left out of static exports.
+	maxStaticFileName = 255
+	// staticExcerptLen is the length of the excerpts in the search index, in
+	// runes.
+	staticExcerptLen = 160
+)
+
+// StaticExportStats reports what ExportStatic wrote.
+type StaticExportStats struct {
+	Pages     int
+	Redirects int
+	// Skipped counts pages whose slug is too long for a file name.
+	Skipped int
+}
+
+// staticSearchEntry is one page in the prebuilt search index.
+type staticSearchEntry struct {
+	Href    string `json:"h"`
+	Title   string `json:"t"`
+	Excerpt string `json:"x,omitempty"`
+}
+
+// ExportStatic renders every stored page to plain HTML under dir, for
+// read-only hosting: wiki/{slug}.html for each page and redirect, search.html
+// backed by a prebuilt client-side index, index.html, and a copy of the
+// constellation map. Links between pages are relative, and links to pages that
+// were never written are inert.
+func ExportStatic(ctx context.Context, db *sql.DB, cfg Config, dir string) (StaticExportStats, error) {
+	srv, err := NewServer(db, cfg)
+	if err != nil {
+		return StaticExportStats{}, err
+	}
+	return srv.exportStatic(ctx, dir)
+}
+
+func (s *Server) exportStatic(ctx context.Context, dir string) (StaticExportStats, error) {
+	var stats StaticExportStats
+	pageTemplates, err := s.staticTemplates("..")
+	if err != nil {
+		return stats, err
+	}
+	rootTemplates, err := s.staticTemplates(".")
+	if err != nil {
+		return stats, err
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "wiki"), 0o755); err != nil {
+		return stats, err
+	}
+
+	slugs, err := loadStaticSlugs(ctx, s.db)
+	if err != nil {
+		return stats, fmt.Errorf("list pages: %w", err)
+	}
+	redirects, err := loadStaticRedirects(ctx, s.db)
+	if err != nil {
+		return stats, fmt.Errorf("list redirects: %w", err)
+	}
+
+	// existing holds every slug that gets a file; links to anything else are
+	// rendered as missing.
+	existing := make(map[string]struct{}, len(slugs)+len(redirects))
+	var pages []string
+	for _, slug := range slugs {
+		if !fitsStaticFile(slug) {
+			log.Printf("static export: skipping %s, slug too long for a file name", slug)
+			stats.Skipped++
+			continue
+		}
+		existing[slug] = struct{}{}
+		pages = append(pages, slug)
+	}
+	var aliases [][2]string
+	for _, redirect := range redirects {
+		if _, ok := existing[redirect[1]]; ok && fitsStaticFile(redirect[0]) {
+			aliases = append(aliases, redirect)
+		}
+	}
+	for _, redirect := range aliases {
+		existing[redirect[0]] = struct{}{}
+	}
+
+	var index []staticSearchEntry
+	for _, slug := range pages {
+		page, err := s.lookupPage(ctx, slug)
+		if err != nil {
+			return stats, fmt.Errorf("load %s: %w", slug, err)
+		}
+		if page == nil {
+			// Merged away since the listing.
+			continue
+		}
+		rendered, err := s.decoratePage(ctx, page, linkMode{existing: existing, rewrite: staticLinks}, page.CreatedAt)
+		if err != nil {
+			return stats, fmt.Errorf("render %s: %w", slug, err)
+		}
+		var buf bytes.Buffer
+		if err := pageTemplates.ExecuteTemplate(&buf, "wiki.gohtml", newWikiPageView(rendered, "", len(pages))); err != nil {
+			return stats, fmt.Errorf("render %s: %w", slug, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "wiki", slug+".html"), buf.Bytes(), 0o644); err != nil {
+			return stats, err
+		}
+		index = append(index, staticSearchEntry{
+			Href:    "wiki/" + staticHref("", slug),
+			Title:   rendered.Title,
+			Excerpt: leadExcerpt(page.Content, staticExcerptLen),
+		})
+		stats.Pages++
+	}
+
+	for _, redirect := range aliases {
+		stub := staticRedirectPage(s.cfg.lang(), SlugTitle(redirect[1]), staticHref("", redirect[1]))
+		if err := os.WriteFile(filepath.Join(dir, "wiki", redirect[0]+".html"), []byte(stub), 0o644); err != nil {
+			return stats, err
+		}
+		stats.Redirects++
+	}
+
+	if err := writeStaticSearch(dir, rootTemplates, index); err != nil {
+		return stats, err
+	}
+	home := []byte(staticRedirectPage(s.cfg.lang(), "EndlessWiki", "wiki/main_page.html"))
+	if _, ok := existing["main_page"]; !ok {
+		// No main page was written; list the pages instead.
+		page := &Page{Title: "EndlessWiki", Content: staticIndexContent(index)}
+		var buf bytes.Buffer
+		if err := rootTemplates.ExecuteTemplate(&buf, "wiki.gohtml", newWikiPageView(newRenderedPage(page, page.Content, nil, time.Now()), "", len(pages))); err != nil {
+			return stats, fmt.Errorf("render index: %w", err)
+		}
+		home = buf.Bytes()
+	}
+	if err := os.WriteFile(filepath.Join(dir, "index.html"), home, 0o644); err != nil {
+		return stats, err
+	}
+	if s.cfg.hasConstellation() {
+		if err := copyStaticConstellation("static", dir); err != nil {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+// staticTemplates returns the templates set up for a static export, for pages
+// rel (".." or ".") below the export root.
+func (s *Server) staticTemplates(rel string) (*template.Template, error) {
+	tmpl, err := s.templates.Clone()
+	if err != nil {
+		return nil, err
+	}
+	return tmpl.Funcs(template.FuncMap{
+		"base":   func() string { return rel },
+		"link":   func(p string) string { return staticLink(rel, p) },
+		"static": func() bool { return true },
+	}), nil
+}
+
+// staticLink maps a root-relative wiki path, such as "/wiki/rome" or
+// "/search", to its file in a static export.
+func staticLink(rel, p string) string {
+	if p == "/" {
+		return rel + "/index.html"
+	}
+	return rel + p + ".html"
+}
+
+func fitsStaticFile(slug string) bool {
+	return len(slug)+len(".html") <= maxStaticFileName
+}
+
+func loadStaticSlugs(ctx context.Context, db *sql.DB) ([]string, error) {
+	rows, err := db.QueryContext(ctx, `SELECT slug FROM pages ORDER BY slug`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var slugs []string
+	for rows.Next() {
+		var slug string
+		if err := rows.Scan(&slug); err != nil {
+			return nil, err
+		}
+		slugs = append(slugs, slug)
+	}
+	return slugs, rows.Err()
+}
+
+// loadStaticRedirects returns every alias and its target.
+func loadStaticRedirects(ctx context.Context, db *sql.DB) ([][2]string, error) {
+	rows, err := db.QueryContext(ctx, `SELECT alias_slug, target_slug FROM redirects ORDER BY alias_slug`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var redirects [][2]string
+	for rows.Next() {
+		var redirect [2]string
+		if err := rows.Scan(&redirect[0], &redirect[1]); err != nil {
+			return nil, err
+		}
+		redirects = append(redirects, redirect)
+	}
+	return redirects, rows.Err()
+}
+
+// staticRedirectPage is a page that sends browsers on to href.
+func staticRedirectPage(lang, title, href string) string {
+	title, href = html.EscapeString(title), html.EscapeString(href)
+	return `<!DOCTYPE html>
+<html lang="` + html.EscapeString(lang) + `">
+<head>
+<meta charset="utf-8">
+<title>` + title + `</title>
+<link rel="canonical" href="` + href + `">
+<meta http-equiv="refresh" content="0; url=` + href + `">
+</head>
+<body><p><a href="` + href + `">` + title + `</a></p></body>
+</html>
+`
+}
+
+// staticIndexContent is the article that stands in for a missing main page:
+// every exported page in title order.
+func staticIndexContent(index []staticSearchEntry) string {
+	entries := slices.Clone(index)
+	slices.SortStableFunc(entries, func(a, b staticSearchEntry) int { return strings.Compare(a.Title, b.Title) })
+	var b strings.Builder
+	b.WriteString("<h1>EndlessWiki</h1>\n<ul class=\"index\">\n")
+	for _, entry := range entries {
+		fmt.Fprintf(&b, "<li><a href=\"%s\">%s</a></li>\n", html.EscapeString(entry.Href), html.EscapeString(entry.Title))
+	}
+	b.WriteString("</ul>\n")
+	return b.String()
+}
+
+// writeStaticSearch writes search.html and the index it searches,
+// search-index.js. The index is a script rather than JSON so that it also
+// loads from file:// URLs.
+func writeStaticSearch(dir string, tmpl *template.Template, index []staticSearchEntry) error {
+	data := struct {
+		Query       string
+		Results     []pageLink
+		PageCount   int
+		SearchQuery string
+	}{PageCount: len(index)}
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "search.gohtml", data); err != nil {
+		return fmt.Errorf("render search: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "search.html"), buf.Bytes(), 0o644); err != nil {
+		return err
+	}
+
+	if index == nil {
+		index = []staticSearchEntry{}
+	}
+	encoded, err := json.Marshal(index)
+	if err != nil {
+		return err
+	}
+	script := "window.ENDLESSWIKI_SEARCH = " + string(encoded) + ";\n"
+	return os.WriteFile(filepath.Join(dir, "search-index.js"), []byte(script), 0o644)
+}
+
+// constellationStaticRewrites point the constellation page's absolute URLs at
+// the files of a static export.
+var constellationStaticRewrites = []struct{ from, to string }{
+	{`fetch('/static/constellation.json')`, `fetch('constellation.json')`},
+	{"link.href = `/wiki/${encodeURIComponent(member.slug)}`;", "link.href = `wiki/${encodeURIComponent(member.slug)}.html`;"},
+	{`href="/wiki/main_page"`, `href="wiki/main_page.html"`},
+}
+
+// copyStaticConstellation copies the constellation page and its snapshot from
+// staticDir into the export. A missing snapshot is skipped.
+func copyStaticConstellation(staticDir, dir string) error {
+	page, err := os.ReadFile(filepath.Join(staticDir, "constellation.html"))
+	if errors.Is(err, fs.ErrNotExist) {
+		log.Printf("static export: no constellation page in %s, skipping", staticDir)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	snapshot, err := os.ReadFile(filepath.Join(staticDir, "constellation.json"))
+	if errors.Is(err, fs.ErrNotExist) {
+		log.Printf("static export: no constellation snapshot in %s, skipping the map", staticDir)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	rewritten, err := rewriteConstellationPage(string(page))
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "constellation.html"), []byte(rewritten), 0o644); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "constellation.json"), snapshot, 0o644)
+}
+
+func rewriteConstellationPage(page string) (string, error) {
+	for _, rw := range constellationStaticRewrites {
+		if !strings.Contains(page, rw.from) {
+			return "", fmt.Errorf("constellation page has no %s to rewrite for the static export", rw.from)
+		}
+		page = strings.ReplaceAll(page, rw.from, rw.to)
+	}
+	return page, nil
+}
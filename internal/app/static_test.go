@@ -0,0 +1,153 @@
+package app
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestStaticLinks(t *testing.T) {
+	content := `<p><a href="/wiki/rome#history">Rome</a>, <a href="/wiki/Made%20Up?origin=x">made up</a> and <a href="https://example.com/">out</a>.</p>`
+	got := staticLinks(content, map[string]struct{}{"made_up": {}})
+	want := `<p><a href="rome.html#history">Rome</a>, <span class="missing-link">made up</span> and <a href="https://example.com/">out</a>.</p>`
+	if got != want {
+		t.Errorf("staticLinks =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestStaticLink(t *testing.T) {
+	for _, tc := range []struct{ rel, path, want string }{
+		{"..", "/", "../index.html"},
+		{"..", "/wiki/main_page", "../wiki/main_page.html"},
+		{"..", "/wiki/caf%C3%A9", "../wiki/caf%C3%A9.html"},
+		{".", "/search", "./search.html"},
+	} {
+		if got := staticLink(tc.rel, tc.path); got != tc.want {
+			t.Errorf("staticLink(%q, %q) = %q, want %q", tc.rel, tc.path, got, tc.want)
+		}
+	}
+}
+
+func TestStaticWikiTemplate(t *testing.T) {
+	srv, err := NewServer(nil, Config{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	tmpl, err := srv.staticTemplates("..")
+	if err != nil {
+		t.Fatalf("staticTemplates: %v", err)
+	}
+	page := &renderedPage{
+		Slug:       "rome",
+		Title:      "Rome",
+		Body:       `<h1>Rome</h1><p>See <a href="carthage.html">Carthage</a>.</p>`,
+		Infobox:    &infoboxView{Type: "place", Title: "Rome", Rows: []infoboxRow{{Label: "Founder", Value: "Romulus", Href: "/wiki/romulus"}}},
+		Categories: []categoryLink{{Name: "cities", Title: "Cities"}},
+		headingEnd: len("<h1>Rome</h1>"),
+	}
+	var b strings.Builder
+	if err := tmpl.ExecuteTemplate(&b, "wiki.gohtml", newWikiPageView(page, "", 2)); err != nil {
+		t.Fatalf("render: %v", err)
+	}
+	out := b.String()
+	for _, want := range []string{
+		`href="../wiki/main_page.html"`,
+		`action="../search.html"`,
+		`<a href="../wiki/romulus.html">Romulus</a>`,
+		`<a href="carthage.html">Carthage</a>`,
+		`<li>Cities</li>`,
+		"read-only snapshot",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %s", want)
+		}
+	}
+	for _, unwanted := range []string{"/random", "/expand/", "/api/preview/", "/category/", "/stats"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("static output links to %s", unwanted)
+		}
+	}
+}
+
+func TestStaticIndexContent(t *testing.T) {
+	got := staticIndexContent([]staticSearchEntry{
+		{Href: "wiki/rome.html", Title: "Rome"},
+		{Href: "wiki/carthage.html", Title: "Carthage & Co"},
+	})
+	want := "<h1>EndlessWiki</h1>\n<ul class=\"index\">\n" +
+		"<li><a href=\"wiki/carthage.html\">Carthage &amp; Co</a></li>\n" +
+		"<li><a href=\"wiki/rome.html\">Rome</a></li>\n</ul>\n"
+	if got != want {
+		t.Errorf("staticIndexContent =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestWriteStaticSearch(t *testing.T) {
+	srv, err := NewServer(nil, Config{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	tmpl, err := srv.staticTemplates(".")
+	if err != nil {
+		t.Fatalf("staticTemplates: %v", err)
+	}
+	dir := t.TempDir()
+	index := []staticSearchEntry{{Href: "wiki/rome.html", Title: "Rome", Excerpt: "A city </script> on seven hills."}}
+	if err := writeStaticSearch(dir, tmpl, index); err != nil {
+		t.Fatalf("writeStaticSearch: %v", err)
+	}
+
+	page, err := os.ReadFile(filepath.Join(dir, "search.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`<script src="search-index.js"></script>`, `data-pattern="Search results for &#34;%s&#34;"`, `action="./search.html"`} {
+		if !strings.Contains(string(page), want) {
+			t.Errorf("search.html missing %s", want)
+		}
+	}
+
+	script, err := os.ReadFile(filepath.Join(dir, "search-index.js"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	encoded, ok := strings.CutPrefix(strings.TrimSpace(string(script)), "window.ENDLESSWIKI_SEARCH = ")
+	if !ok || strings.Contains(encoded, "</script>") {
+		t.Fatalf("search-index.js = %s", script)
+	}
+	var decoded []staticSearchEntry
+	if err := json.Unmarshal([]byte(strings.TrimSuffix(encoded, ";")), &decoded); err != nil {
+		t.Fatalf("decode index: %v", err)
+	}
+	if len(decoded) != 1 || decoded[0] != index[0] {
+		t.Errorf("index = %+v", decoded)
+	}
+}
+
+func TestRewriteConstellationPage(t *testing.T) {
+	page, err := os.ReadFile(filepath.Join("..", "..", "static", "constellation.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	rewritten, err := rewriteConstellationPage(string(page))
+	if err != nil {
+		t.Fatalf("rewriteConstellationPage: %v", err)
+	}
+	if strings.Contains(rewritten, "'/static/") || strings.Contains(rewritten, `"/wiki/`) || strings.Contains(rewritten, "`/wiki/") {
+		t.Error("rewritten page still has absolute links")
+	}
+	if _, err := rewriteConstellationPage("<html></html>"); err == nil {
+		t.Error("rewrote a page without the expected links")
+	}
+}
+
+func TestStaticRedirectPage(t *testing.T) {
+	got := staticRedirectPage("en", `Tom & "Jerry"`, "tom_and_jerry.html")
+	for _, want := range []string{`<title>Tom &amp; &#34;Jerry&#34;</title>`, `content="0; url=tom_and_jerry.html"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("redirect page missing %s:\n%s", want, got)
+		}
+	}
+}
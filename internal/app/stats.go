@@ -0,0 +1,235 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// pageCountRefreshInterval controls how often the in-memory page total is
+	// reconciled with COUNT(*), picking up pages inserted by other instances.
+	pageCountRefreshInterval = 5 * time.Minute
+	siteStatsTTL             = 5 * time.Minute
+	siteStatsDays            = 30
+)
+
+// pageCounter keeps the page total in memory. Inserts bump it directly and a
+// background COUNT(*) reconciles it once the value is older than interval.
+type pageCounter struct {
+	mu          sync.Mutex
+	count       int
+	loaded      bool
+	refreshing  bool
+	refreshedAt time.Time
+	interval    time.Duration
+	load        func(context.Context) (int, error)
+}
+
+func newPageCounter(interval time.Duration, load func(context.Context) (int, error)) *pageCounter {
+	return &pageCounter{interval: interval, load: load}
+}
+
+// Get returns the current total. Only the very first call waits on the
+// database; later calls refresh asynchronously when the value is stale.
+func (c *pageCounter) Get(ctx context.Context) (int, error) {
+	c.mu.Lock()
+	if !c.loaded {
+		c.mu.Unlock()
+		count, err := c.load(ctx)
+		if err != nil {
+			return 0, err
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if !c.loaded {
+			c.count, c.loaded, c.refreshedAt = count, true, time.Now()
+		}
+		return c.count, nil
+	}
+	defer c.mu.Unlock()
+
+	if !c.refreshing && time.Since(c.refreshedAt) >= c.interval {
+		c.refreshing = true
+		go c.refresh()
+	}
+	return c.count, nil
+}
+
+// Add adjusts the total after a local insert or delete.
+func (c *pageCounter) Add(delta int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.loaded {
+		c.count += delta
+	}
+}
+
+func (c *pageCounter) refresh() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	count, err := c.load(ctx)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.refreshing = false
+	if err != nil {
+		log.Printf("refresh page count: %v", err)
+		return
+	}
+	c.count, c.refreshedAt = count, time.Now()
+}
+
+// siteStats is a snapshot of wiki growth rendered by /stats.
+type siteStats struct {
+	GeneratedAt  time.Time
+	Pages        int
+	Links        int
+	LinksPerPage float64
+	FrontierSize int
+	Days         []dayStats
+	MaxDayPages  int
+}
+
+type dayStats struct {
+	Day   time.Time
+	Pages int
+	Total int
+}
+
+// statsCache holds the latest siteStats snapshot, rebuilt at most once per TTL.
+type statsCache struct {
+	mu       sync.Mutex
+	snapshot *siteStats
+}
+
+func (c *statsCache) Get(ctx context.Context, ttl time.Duration, compute func(context.Context) (*siteStats, error)) (*siteStats, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.snapshot != nil && time.Since(c.snapshot.GeneratedAt) < ttl {
+		return c.snapshot, nil
+	}
+	snapshot, err := compute(ctx)
+	if err != nil {
+		if c.snapshot != nil {
+			log.Printf("refresh site stats: %v", err)
+			return c.snapshot, nil
+		}
+		return nil, err
+	}
+	c.snapshot = snapshot
+	return snapshot, nil
+}
+
+func computeSiteStats(ctx context.Context, db *sql.DB) (*siteStats, error) {
+	stats := &siteStats{GeneratedAt: time.Now()}
+
+	const frontierQuery = `SELECT COUNT(DISTINCT l.target_slug) FROM page_links l
+		LEFT JOIN pages p ON p.slug = l.target_slug
+		LEFT JOIN redirects r ON r.alias_slug = l.target_slug
+		WHERE p.slug IS NULL AND r.alias_slug IS NULL`
+	counts := []struct {
+		query string
+		dest  *int
+	}{
+		{`SELECT COUNT(*) FROM pages`, &stats.Pages},
+		{`SELECT COUNT(*) FROM page_links`, &stats.Links},
+		{frontierQuery, &stats.FrontierSize},
+	}
+	for _, c := range counts {
+		if err := db.QueryRowContext(ctx, c.query).Scan(c.dest); err != nil {
+			return nil, err
+		}
+	}
+	if stats.Pages > 0 {
+		stats.LinksPerPage = float64(stats.Links) / float64(stats.Pages)
+	}
+
+	days, err := pagesPerDay(ctx, db, siteStatsDays)
+	if err != nil {
+		return nil, err
+	}
+	stats.Days = days
+	for _, day := range days {
+		if day.Pages > stats.MaxDayPages {
+			stats.MaxDayPages = day.Pages
+		}
+	}
+	return stats, nil
+}
+
+// pagesPerDay returns page creation counts for the most recent days that saw
+// new pages, newest first, with running totals.
+func pagesPerDay(ctx context.Context, db *sql.DB, limit int) ([]dayStats, error) {
+	const query = `SELECT DATE(created_at) AS day, COUNT(*) FROM pages GROUP BY day ORDER BY day`
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var days []dayStats
+	total := 0
+	for rows.Next() {
+		var day dayStats
+		if err := rows.Scan(&day.Day, &day.Pages); err != nil {
+			return nil, err
+		}
+		total += day.Pages
+		day.Total = total
+		days = append(days, day)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(days) > limit {
+		days = days[len(days)-limit:]
+	}
+	for i, j := 0, len(days)-1; i < j; i, j = i+1, j-1 {
+		days[i], days[j] = days[j], days[i]
+	}
+	return days, nil
+}
+
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx := r.Context()
+	stats, err := s.stats.Get(ctx, siteStatsTTL, func(ctx context.Context) (*siteStats, error) {
+		return computeSiteStats(ctx, s.db)
+	})
+	if err != nil {
+		log.Printf("site stats: %v", err)
+		http.Error(w, "failed to load statistics", http.StatusInternalServerError)
+		return
+	}
+
+	count, err := s.pageCount(ctx)
+	if err != nil {
+		log.Printf("page count: %v", err)
+		count = 0
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	data := struct {
+		Stats       *siteStats
+		PageCount   int
+		SearchQuery string
+	}{
+		Stats:       stats,
+		PageCount:   count,
+		SearchQuery: "",
+	}
+
+	if err := s.templates.ExecuteTemplate(w, "stats.gohtml", data); err != nil {
+		log.Printf("render stats: %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package app
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPageCounterLoadsOnceAndTracksInserts(t *testing.T) {
+	loads := 0
+	counter := newPageCounter(time.Hour, func(context.Context) (int, error) {
+		loads++
+		return 41, nil
+	})
+
+	if got, _ := counter.Get(context.Background()); got != 41 {
+		t.Fatalf("initial count = %d, want 41", got)
+	}
+	counter.Add(1)
+	if got, _ := counter.Get(context.Background()); got != 42 {
+		t.Fatalf("count after insert = %d, want 42", got)
+	}
+	if loads != 1 {
+		t.Fatalf("expected a single COUNT(*) load, got %d", loads)
+	}
+}
+
+func TestStatsTemplateRenders(t *testing.T) {
+	srv, err := NewServer(nil, Config{})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+
+	data := struct {
+		Stats       *siteStats
+		PageCount   int
+		SearchQuery string
+	}{
+		Stats: &siteStats{
+			GeneratedAt: time.Now(),
+			Pages:       3,
+			Days:        []dayStats{{Day: time.Date(2025, 9, 26, 0, 0, 0, 0, time.UTC), Pages: 3, Total: 3}},
+			MaxDayPages: 3,
+		},
+		PageCount: 3,
+	}
+
+	var b strings.Builder
+	if err := srv.templates.ExecuteTemplate(&b, "stats.gohtml", data); err != nil {
+		t.Fatalf("render stats: %v", err)
+	}
+	if !strings.Contains(b.String(), "2025-09-26") || !strings.Contains(b.String(), "width: 100%") {
+		t.Fatalf("stats page missing growth row: %s", b.String())
+	}
+}
@@ -6,3 +6,14 @@ import "embed"
 //
 //go:embed templates/*
 var templateFS embed.FS
+
+// percent scales part against whole for CSS widths, clamped to 0-100.
+func percent(part, whole int) int {
+	if whole <= 0 || part <= 0 {
+		return 0
+	}
+	if part >= whole {
+		return 100
+	}
+	return part * 100 / whole
+}
@@ -0,0 +1,60 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	nethtml "golang.org/x/net/html"
+)
+
+// maxTitleLen matches the width of pages.title.
+const maxTitleLen = 255
+
+// ExtractTitle returns the text of the first <h1> in generated HTML with
+// whitespace collapsed, or "" when the content has no usable heading.
+func ExtractTitle(content string) string {
+	z := nethtml.NewTokenizer(strings.NewReader(content))
+	inHeading := false
+	var b strings.Builder
+	for {
+		switch z.Next() {
+		case nethtml.ErrorToken:
+			return cleanTitle(b.String())
+		case nethtml.StartTagToken:
+			if name, _ := z.TagName(); string(name) == "h1" {
+				inHeading = true
+			}
+		case nethtml.EndTagToken:
+			if name, _ := z.TagName(); inHeading && string(name) == "h1" {
+				return cleanTitle(b.String())
+			}
+		case nethtml.TextToken:
+			if inHeading {
+				b.Write(z.Text())
+				b.WriteByte(' ')
+			}
+		}
+	}
+}
+
+func cleanTitle(text string) string {
+	title := strings.Join(strings.Fields(text), " ")
+	if runes := []rune(title); len(runes) > maxTitleLen {
+		title = strings.TrimSpace(string(runes[:maxTitleLen]))
+	}
+	return title
+}
+
+// BackfillTitles parses the <h1> of every stored page into pages.title and
+// returns the number of pages processed.
+func BackfillTitles(ctx context.Context, db *sql.DB) (int, error) {
+	return eachPage(ctx, db, func(p Page) error {
+		title := ExtractTitle(p.Content)
+		if title == "" {
+			return nil
+		}
+		_, err := db.ExecContext(ctx, `UPDATE pages SET title = ? WHERE slug = ?`, title, p.Slug)
+		return err
+	})
+}
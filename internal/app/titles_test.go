@@ -0,0 +1,39 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExtractTitle(t *testing.T) {
+	cases := map[string]string{
+		"<h1>NASA</h1><p>Space agency.</p>":                     "NASA",
+		"<h1>Battle of the\n  <em>Bulge</em></h1>":              "Battle of the Bulge",
+		"<p>Intro</p><h1>Tōkyō &amp; Ōsaka</h1><h1>Second</h1>": "Tōkyō & Ōsaka",
+		"<h2>Not a title</h2><p>No heading here.</p>":           "",
+		"<h1>   </h1>": "",
+	}
+	for content, want := range cases {
+		if got := ExtractTitle(content); got != want {
+			t.Errorf("ExtractTitle(%q) = %q, want %q", content, got, want)
+		}
+	}
+}
+
+func TestExtractTitleTruncates(t *testing.T) {
+	long := strings.Repeat("é", maxTitleLen+10)
+	if got := ExtractTitle("<h1>" + long + "</h1>"); len([]rune(got)) != maxTitleLen {
+		t.Fatalf("title length = %d runes, want %d", len([]rune(got)), maxTitleLen)
+	}
+}
+
+func TestDisplayTitleFallsBackToSlug(t *testing.T) {
+	page := &Page{Slug: "nasa"}
+	if got := page.DisplayTitle(); got != "Nasa" {
+		t.Fatalf("DisplayTitle() without title = %q", got)
+	}
+	page.Title = "NASA"
+	if got := page.DisplayTitle(); got != "NASA" {
+		t.Fatalf("DisplayTitle() = %q, want NASA", got)
+	}
+}
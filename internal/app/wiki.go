@@ -6,22 +6,47 @@ import (
 	"strings"
 	"time"
 	"unicode"
+	"unicode/utf8"
 
-	"golang.org/x/text/runes"
-	"golang.org/x/text/transform"
+	"golang.org/x/text/cases"
 	"golang.org/x/text/unicode/norm"
 )
 
 // Page represents a persisted wiki article.
 type Page struct {
-	Slug      string
+	Slug string
+	// Title is the heading the model wrote, or "" for pages stored before
+	// titles were recorded.
+	Title     string
 	Content   string
 	CreatedAt time.Time
+	// UpdatedAt is when the page was last expanded; zero if never.
+	UpdatedAt time.Time
 }
 
-var slugAllowed = regexp.MustCompile(`^[a-z0-9_\-]+$`)
+// DisplayTitle returns the stored title, falling back to one derived from
+// the slug.
+func (p *Page) DisplayTitle() string {
+	return DisplayTitle(p.Slug, p.Title)
+}
+
+// DisplayTitle returns title, or one derived from slug when title is empty.
+func DisplayTitle(slug, title string) string {
+	if title != "" {
+		return title
+	}
+	return SlugTitle(slug)
+}
+
+// slugAllowed accepts letters and digits from any script. Latin slugs are
+// folded to ASCII first, so slugs stored before Unicode support keep their
+// form.
+var slugAllowed = regexp.MustCompile(`^[\p{L}\p{M}\p{Nd}_\-]+$`)
 
-// NormalizeSlug normalizes raw slug input into the canonical database slug.
+var slugFolder = cases.Fold()
+
+// NormalizeSlug normalizes raw slug input into the canonical database slug:
+// NFC, case-folded, with diacritics stripped from Latin letters only.
 func NormalizeSlug(input string) (string, error) {
 	trimmed := strings.TrimSpace(input)
 	if strings.ContainsAny(trimmed, "/\\?&:#'\"") || strings.Contains(trimmed, "..") {
@@ -32,7 +57,7 @@ func NormalizeSlug(input string) (string, error) {
 	trimmed = strings.ReplaceAll(trimmed, " ", "_")
 	trimmed = strings.ReplaceAll(trimmed, "%20", "_")
 	trimmed = normalizeUnicode(trimmed)
-	trimmed = strings.ToLower(trimmed)
+	trimmed = norm.NFC.String(slugFolder.String(trimmed))
 	trimmed = strings.Trim(trimmed, "_")
 
 	if trimmed == "" {
@@ -52,37 +77,56 @@ func NormalizeSlug(input string) (string, error) {
 func normalizeUnicode(s string) string {
 	var b strings.Builder
 	b.Grow(len(s))
+	afterLetter := false
 	for _, r := range s {
 		switch {
 		case unicode.IsLetter(r) || unicode.IsDigit(r):
 			b.WriteRune(unicode.ToLower(r))
+			afterLetter = unicode.IsLetter(r)
+		case unicode.IsMark(r) && afterLetter:
+			// vowel signs and other marks that belong to the preceding letter
+			b.WriteRune(r)
 		case r == '-' || r == '_':
 			b.WriteRune('_')
+			afterLetter = false
 		default:
 			// skip everything else
+			afterLetter = false
 		}
 	}
 	return b.String()
 }
 
-// SlugTitle converts a slug into a human-friendly title for rendering.
+// SlugTitle converts a slug into a human-friendly title. It is only a fallback
+// for pages without a stored title and for pages that do not exist yet.
 func SlugTitle(slug string) string {
 	parts := strings.Split(slug, "_")
 	for i, part := range parts {
 		if part == "" {
 			continue
 		}
-		parts[i] = strings.ToUpper(part[:1]) + part[1:]
+		r, size := utf8.DecodeRuneInString(part)
+		parts[i] = string(unicode.ToTitle(r)) + part[size:]
 	}
 	return strings.Join(parts, " ")
 }
 
-var diacriticStripper = transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
-
+// stripDiacritics removes combining marks that follow a Latin letter, so
+// "Café" becomes "cafe", while marks that other scripts need (the breve in
+// Cyrillic "й", Devanagari vowel signs) are kept.
 func stripDiacritics(s string) string {
-	stripped, _, err := transform.String(diacriticStripper, s)
-	if err != nil {
-		return s
+	var b strings.Builder
+	b.Grow(len(s))
+	latin := false
+	for _, r := range norm.NFD.String(s) {
+		if unicode.Is(unicode.Mn, r) {
+			if latin {
+				continue
+			}
+		} else {
+			latin = unicode.Is(unicode.Latin, r)
+		}
+		b.WriteRune(r)
 	}
-	return stripped
+	return norm.NFC.String(b.String())
 }
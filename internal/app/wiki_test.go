@@ -8,6 +8,15 @@ func TestNormalizeSlug(t *testing.T) {
 		"Quantum-Flux":   "quantum_flux",
 		"  spaced out  ": "spaced_out",
 		"Emoji😀Test":     "emojitest",
+		"Crème Brûlée":   "creme_brulee",
+		"Cafe\u0301":     "cafe",
+		"Привет":         "привет",
+		"Москва Сити":    "москва_сити",
+		"Йошкар-Ола":     "йошкар_ола",
+		"и\u0306ога":     "йога",
+		"東京":             "東京",
+		"ΟΔΥΣΣΕΥΣ":       "οδυσσευσ",
+		"हिन्दी":         "हिन्दी",
 	}
 
 	for input, want := range tests {
@@ -22,7 +31,7 @@ func TestNormalizeSlug(t *testing.T) {
 }
 
 func TestNormalizeSlugInvalid(t *testing.T) {
-	inputs := []string{"", "../etc/passwd", "white space?", "Привет"}
+	inputs := []string{"", "../etc/passwd", "white space?", "😀", "東京/大阪"}
 	for _, input := range inputs {
 		if _, err := NormalizeSlug(input); err == nil {
 			t.Fatalf("NormalizeSlug(%q) expected error", input)
@@ -37,4 +46,7 @@ func TestSlugTitle(t *testing.T) {
 	if got, want := SlugTitle("orbital_gardening"), "Orbital Gardening"; got != want {
 		t.Fatalf("SlugTitle() = %q, want %q", got, want)
 	}
+	if got, want := SlugTitle("москва_сити"), "Москва Сити"; got != want {
+		t.Fatalf("SlugTitle() = %q, want %q", got, want)
+	}
 }
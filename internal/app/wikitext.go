@@ -0,0 +1,630 @@
+package app
+
+import (
+	"errors"
+	"html"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Namespace numbers the importer treats specially.
+const (
+	mwMainNamespace     = 0
+	mwFileNamespace     = 6
+	mwCategoryNamespace = 14
+)
+
+// mwDefaultNamespaces are the canonical English namespace names, used when an
+// export does not list its own. Keys are lower case with spaces.
+var mwDefaultNamespaces = map[string]int{
+	"media": -2, "special": -1, "talk": 1, "user": 2, "user talk": 3,
+	"project": 4, "project talk": 5, "file": 6, "image": 6, "file talk": 7,
+	"mediawiki": 8, "template": 10, "template talk": 11, "help": 12,
+	"category": 14, "category talk": 15, "module": 828,
+}
+
+// mwNamespaces maps namespace names and aliases to their numbers.
+type mwNamespaces map[string]int
+
+// split separates a namespace prefix from title. Titles whose prefix is not a
+// known namespace, such as "Star Wars: Andor", are in the main namespace.
+func (ns mwNamespaces) split(title string) (int, string) {
+	prefix, rest, ok := strings.Cut(title, ":")
+	if !ok {
+		return mwMainNamespace, title
+	}
+	key := strings.ToLower(strings.TrimSpace(strings.ReplaceAll(prefix, "_", " ")))
+	if n, ok := ns[key]; ok {
+		return n, strings.TrimSpace(rest)
+	}
+	if n, ok := mwDefaultNamespaces[key]; ok && len(ns) == 0 {
+		return n, strings.TrimSpace(rest)
+	}
+	return mwMainNamespace, title
+}
+
+// mwTitleReplacer clears the characters NormalizeSlug rejects but MediaWiki
+// titles may contain.
+var mwTitleReplacer = strings.NewReplacer("'", "", "’", "", `"`, "", "/", " ", `\`, " ", "?", " ", "&", " ", ":", " ", "#", " ", "..", " ")
+
+// mwSlug is the EndlessWiki slug of a MediaWiki title.
+func mwSlug(title string) (string, error) {
+	slug, err := NormalizeSlug(mwTitleReplacer.Replace(strings.ReplaceAll(title, "_", " ")))
+	if err != nil {
+		return "", err
+	}
+	if utf8.RuneCountInString(slug) > maxTitleLen {
+		return "", errors.New("slug too long")
+	}
+	return slug, nil
+}
+
+// mwDisplayTitle is the title MediaWiki shows for a page title.
+func mwDisplayTitle(title string) string {
+	title = strings.Join(strings.Fields(strings.ReplaceAll(title, "_", " ")), " ")
+	if r, size := utf8.DecodeRuneInString(title); r != utf8.RuneError {
+		title = string(unicode.ToUpper(r)) + title[size:]
+	}
+	return cleanTitle(title)
+}
+
+var (
+	mwCommentPattern   = regexp.MustCompile(`(?s)<!--.*?(?:-->|$)`)
+	mwVerbatimPattern  = regexp.MustCompile(`(?is)<(nowiki|pre|code|math)(?:\s[^>]*)?>(.*?)</(?:nowiki|pre|code|math)\s*>`)
+	mwDroppedPattern   = regexp.MustCompile(`(?is)<(ref|gallery|references|timeline|score|syntaxhighlight|source|imagemap|script|style)(?:\s[^>]*)?>.*?</(?:ref|gallery|references|timeline|score|syntaxhighlight|source|imagemap|script|style)\s*>|<(?:ref|references)(?:\s[^>]*)?/>`)
+	mwMagicWordPattern = regexp.MustCompile(`__[A-Z]+__`)
+	mwHeadingPattern   = regexp.MustCompile(`^(={1,6})\s*(.+?)\s*(={1,6})\s*$`)
+	mwExternalPattern  = regexp.MustCompile(`\[((?:https?:)?//[^\s\]]+)(?:\s+([^\]]*))?\]`)
+	mwTagPattern       = regexp.MustCompile(`</?([A-Za-z][A-Za-z0-9]*)\b[^>]*>`)
+	mwLinkTrailPattern = regexp.MustCompile(`^\p{L}+`)
+	mwInterwikiPattern = regexp.MustCompile(`^[a-z]{2,3}(?:-[a-z]+)?$`)
+	mwPipeTrickPattern = regexp.MustCompile(`\s*\([^)]*\)$`)
+)
+
+// mwInlineTags are the HTML tags kept from wikitext, without attributes.
+var mwInlineTags = map[string]bool{
+	"b": true, "i": true, "u": true, "s": true, "em": true, "strong": true,
+	"sub": true, "sup": true, "small": true, "big": true, "del": true, "ins": true,
+	"blockquote": true, "tt": true, "var": true, "kbd": true, "q": true, "abbr": true,
+}
+
+// Placeholders stand in for finished HTML while the surrounding wikitext is
+// still being escaped and formatted. They use private-use runes, which
+// wikitext never contains.
+const (
+	mwHoldOpen  = "\uE000"
+	mwHoldClose = "\uE001"
+)
+
+var mwHoldPattern = regexp.MustCompile(mwHoldOpen + `(\d+)` + mwHoldClose)
+
+// wikitextConverter turns MediaWiki markup into EndlessWiki article HTML. It
+// covers the everyday subset: headings, paragraphs, lists, simple tables,
+// bold and italics, internal and external links, and categories. Templates,
+// references, files, and tags it does not know are dropped.
+type wikitextConverter struct {
+	namespaces mwNamespaces
+	held       []string
+	categories []string
+
+	out       strings.Builder
+	paragraph []string
+	pre       []string
+	list      []byte
+	table     *mwTable
+}
+
+type mwTable struct {
+	caption string
+	rows    [][]mwCell
+}
+
+type mwCell struct {
+	header  bool
+	content string
+}
+
+// wikitextToHTML converts the wikitext of the page title. It returns the
+// article, in the shape generated articles have, and the titles of the
+// categories the page is in.
+func wikitextToHTML(title, text string, namespaces mwNamespaces) (string, []string) {
+	c := &wikitextConverter{namespaces: namespaces}
+	body := c.convert(text)
+	var b strings.Builder
+	b.WriteString("<h1>")
+	b.WriteString(templateEscape(title))
+	b.WriteString("</h1>\n<div class=\"endlesswiki-body\">\n")
+	b.WriteString(body)
+	b.WriteString("</div>\n")
+	return b.String(), c.categories
+}
+
+func (c *wikitextConverter) hold(html string) string {
+	c.held = append(c.held, html)
+	return mwHoldOpen + strconv.Itoa(len(c.held)-1) + mwHoldClose
+}
+
+func (c *wikitextConverter) release(s string) string {
+	for strings.Contains(s, mwHoldOpen) {
+		s = mwHoldPattern.ReplaceAllStringFunc(s, func(m string) string {
+			n, _ := strconv.Atoi(m[len(mwHoldOpen) : len(m)-len(mwHoldClose)])
+			return c.held[n]
+		})
+	}
+	return s
+}
+
+func (c *wikitextConverter) convert(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = mwCommentPattern.ReplaceAllString(text, "")
+	text = mwVerbatimPattern.ReplaceAllStringFunc(text, func(m string) string {
+		parts := mwVerbatimPattern.FindStringSubmatch(m)
+		escaped := templateEscape(html.UnescapeString(parts[2]))
+		switch strings.ToLower(parts[1]) {
+		case "pre":
+			return c.hold("<pre>" + escaped + "</pre>")
+		case "code", "math":
+			return c.hold("<code>" + escaped + "</code>")
+		}
+		return c.hold(escaped)
+	})
+	text = mwDroppedPattern.ReplaceAllString(text, "")
+	text = stripTemplates(text)
+	text = mwMagicWordPattern.ReplaceAllString(text, "")
+
+	for _, line := range strings.Split(text, "\n") {
+		c.line(line)
+	}
+	c.flush()
+	c.closeList(0)
+	c.closeTable()
+	return c.release(c.out.String())
+}
+
+// stripTemplates removes {{templates}} and {{{parameters}}}, which may nest.
+func stripTemplates(text string) string {
+	var b strings.Builder
+	depth := 0
+	for i := 0; i < len(text); i++ {
+		switch {
+		case strings.HasPrefix(text[i:], "{{"):
+			depth++
+			i++
+		case depth > 0 && strings.HasPrefix(text[i:], "}}"):
+			depth--
+			i++
+		case depth == 0:
+			b.WriteByte(text[i])
+		}
+	}
+	return b.String()
+}
+
+func (c *wikitextConverter) line(line string) {
+	trimmed := strings.TrimSpace(line)
+	if c.table != nil || strings.HasPrefix(trimmed, "{|") {
+		c.flush()
+		c.closeList(0)
+		c.tableLine(trimmed)
+		return
+	}
+
+	switch {
+	case trimmed == "":
+		c.flush()
+		c.closeList(0)
+	case mwHeadingPattern.MatchString(trimmed):
+		c.flush()
+		c.closeList(0)
+		m := mwHeadingPattern.FindStringSubmatch(trimmed)
+		level := min(len(m[1]), len(m[3]))
+		// <h1> is the article title.
+		level = max(level, 2)
+		tag := "h" + strconv.Itoa(level)
+		c.out.WriteString("<" + tag + ">" + c.inline(m[2]) + "</" + tag + ">\n")
+	case strings.HasPrefix(trimmed, "----"):
+		c.flush()
+		c.closeList(0)
+		c.out.WriteString("<hr>\n")
+	case strings.ContainsRune("*#:;", rune(line[0])):
+		c.flush()
+		prefix := line[:len(line)-len(strings.TrimLeft(line, "*#:;"))]
+		c.listItem(prefix, strings.TrimSpace(line[len(prefix):]))
+	case line[0] == ' ':
+		if len(c.paragraph) > 0 {
+			c.flushParagraph()
+		}
+		c.closeList(0)
+		c.pre = append(c.pre, c.inline(line[1:]))
+	default:
+		if len(c.pre) > 0 {
+			c.flushPre()
+		}
+		c.closeList(0)
+		c.paragraph = append(c.paragraph, c.inline(trimmed))
+	}
+}
+
+func (c *wikitextConverter) flush() {
+	c.flushParagraph()
+	c.flushPre()
+}
+
+func (c *wikitextConverter) flushParagraph() {
+	text := strings.TrimSpace(strings.Join(c.paragraph, "\n"))
+	c.paragraph = nil
+	// Lines that held only templates or categories leave nothing behind.
+	if text != "" {
+		c.out.WriteString("<p>" + text + "</p>\n")
+	}
+}
+
+func (c *wikitextConverter) flushPre() {
+	if len(c.pre) > 0 {
+		c.out.WriteString("<pre>" + strings.Join(c.pre, "\n") + "</pre>\n")
+		c.pre = nil
+	}
+}
+
+func mwListTags(marker byte) (list, item string) {
+	switch marker {
+	case '*':
+		return "ul", "li"
+	case '#':
+		return "ol", "li"
+	case ';':
+		return "dl", "dt"
+	}
+	return "dl", "dd"
+}
+
+func mwSameList(a, b byte) bool {
+	listA, _ := mwListTags(a)
+	listB, _ := mwListTags(b)
+	return listA == listB
+}
+
+// listItem opens the lists prefix needs, closing any the previous item had
+// that it does not share.
+func (c *wikitextConverter) listItem(prefix, text string) {
+	common := 0
+	for common < len(prefix) && common < len(c.list) && mwSameList(prefix[common], c.list[common]) {
+		common++
+	}
+	sibling := common == len(prefix)
+	if sibling {
+		// The next item of an open list: close the previous one, and any
+		// lists nested in it, but not the list itself.
+		c.closeList(common)
+		_, item := mwListTags(c.list[common-1])
+		c.out.WriteString("</" + item + ">\n")
+		c.list = c.list[:common-1]
+	} else {
+		c.closeList(common)
+	}
+	for len(c.list) < len(prefix) {
+		marker := prefix[len(c.list)]
+		list, item := mwListTags(marker)
+		if !sibling || len(c.list) < len(prefix)-1 {
+			c.out.WriteString("<" + list + ">")
+		}
+		c.out.WriteString("<" + item + ">")
+		c.list = append(c.list, marker)
+	}
+
+	last := prefix[len(prefix)-1]
+	if last == ';' {
+		if term, definition, ok := splitDefinition(text); ok {
+			c.out.WriteString(c.inline(term) + "</dt>\n<dd>" + c.inline(definition))
+			c.list[len(c.list)-1] = ':'
+			return
+		}
+	}
+	c.out.WriteString(c.inline(text))
+}
+
+// closeList closes open lists until depth remain.
+func (c *wikitextConverter) closeList(depth int) {
+	for len(c.list) > depth {
+		list, item := mwListTags(c.list[len(c.list)-1])
+		c.out.WriteString("</" + item + "></" + list + ">\n")
+		c.list = c.list[:len(c.list)-1]
+	}
+}
+
+// splitDefinition splits "; term : definition" at the first colon outside
+// links.
+func splitDefinition(text string) (string, string, bool) {
+	depth := 0
+	for i := 0; i < len(text); i++ {
+		switch {
+		case strings.HasPrefix(text[i:], "[["):
+			depth++
+		case strings.HasPrefix(text[i:], "]]") && depth > 0:
+			depth--
+		case text[i] == ':' && depth == 0 && !strings.HasPrefix(text[i:], "://"):
+			return strings.TrimSpace(text[:i]), strings.TrimSpace(text[i+1:]), true
+		}
+	}
+	return "", "", false
+}
+
+func (c *wikitextConverter) tableLine(line string) {
+	switch {
+	case strings.HasPrefix(line, "{|"):
+		// Nested tables are flattened into the outer one.
+		if c.table == nil {
+			c.table = &mwTable{}
+		}
+	case strings.HasPrefix(line, "|}"):
+		c.closeTable()
+	case strings.HasPrefix(line, "|+"):
+		c.table.caption = c.inline(cellContent(line[2:]))
+	case strings.HasPrefix(line, "|-"):
+		c.table.rows = append(c.table.rows, nil)
+	case strings.HasPrefix(line, "!"):
+		for _, cell := range splitOutsideLinks(line[1:], "!!", "||") {
+			c.addCell(mwCell{header: true, content: c.inline(cellContent(cell))})
+		}
+	case strings.HasPrefix(line, "|"):
+		for _, cell := range splitOutsideLinks(line[1:], "||") {
+			c.addCell(mwCell{content: c.inline(cellContent(cell))})
+		}
+	case line != "":
+		// A continuation of the previous cell.
+		if rows := c.table.rows; len(rows) > 0 && len(rows[len(rows)-1]) > 0 {
+			row := rows[len(rows)-1]
+			row[len(row)-1].content += "<br>" + c.inline(line)
+		}
+	}
+}
+
+func (c *wikitextConverter) addCell(cell mwCell) {
+	if len(c.table.rows) == 0 {
+		c.table.rows = append(c.table.rows, nil)
+	}
+	last := len(c.table.rows) - 1
+	c.table.rows[last] = append(c.table.rows[last], cell)
+}
+
+func (c *wikitextConverter) closeTable() {
+	t := c.table
+	if t == nil {
+		return
+	}
+	c.table = nil
+	c.out.WriteString("<table class=\"wikitable\">\n")
+	if t.caption != "" {
+		c.out.WriteString("<caption>" + t.caption + "</caption>\n")
+	}
+	for _, row := range t.rows {
+		if len(row) == 0 {
+			continue
+		}
+		c.out.WriteString("<tr>")
+		for _, cell := range row {
+			tag := "td"
+			if cell.header {
+				tag = "th"
+			}
+			c.out.WriteString("<" + tag + ">" + cell.content + "</" + tag + ">")
+		}
+		c.out.WriteString("</tr>\n")
+	}
+	c.out.WriteString("</table>\n")
+}
+
+// cellContent drops the attributes of a table cell, written as
+// `style="…" | content`.
+func cellContent(cell string) string {
+	parts := splitOutsideLinks(cell, "|")
+	if len(parts) > 1 && strings.Contains(parts[0], "=") {
+		return strings.TrimSpace(strings.Join(parts[1:], "|"))
+	}
+	return strings.TrimSpace(cell)
+}
+
+// splitOutsideLinks splits s at each separator that is not inside [[links]].
+func splitOutsideLinks(s string, seps ...string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case strings.HasPrefix(s[i:], "[["):
+			depth++
+			i++
+			continue
+		case strings.HasPrefix(s[i:], "]]") && depth > 0:
+			depth--
+			i++
+			continue
+		}
+		if depth > 0 {
+			continue
+		}
+		for _, sep := range seps {
+			if strings.HasPrefix(s[i:], sep) {
+				parts = append(parts, s[start:i])
+				start = i + len(sep)
+				i = start - 1
+				break
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// inline converts the markup within a line: links, tags, bold and italics.
+func (c *wikitextConverter) inline(s string) string {
+	s = c.internalLinks(s)
+	s = mwExternalPattern.ReplaceAllStringFunc(s, func(m string) string {
+		parts := mwExternalPattern.FindStringSubmatch(m)
+		label := parts[2]
+		if label == "" {
+			label = parts[1]
+		}
+		href := parts[1]
+		if strings.HasPrefix(href, "//") {
+			href = "https:" + href
+		}
+		return c.hold(`<a href="` + templateEscape(href) + `">` + c.inline(label) + "</a>")
+	})
+	s = mwTagPattern.ReplaceAllStringFunc(s, func(m string) string {
+		name := strings.ToLower(mwTagPattern.FindStringSubmatch(m)[1])
+		switch {
+		case name == "br":
+			return c.hold("<br>")
+		case mwInlineTags[name] && strings.HasPrefix(m, "</"):
+			return c.hold("</" + name + ">")
+		case mwInlineTags[name]:
+			return c.hold("<" + name + ">")
+		}
+		return ""
+	})
+	return c.quotes(s)
+}
+
+// quotes converts ”italic”, ”'bold”', and ””'both””', escaping the
+// text around them. Formatting left open closes at the end of the line.
+func (c *wikitextConverter) quotes(s string) string {
+	var b strings.Builder
+	var open []string
+	toggle := func(tag string) {
+		for i := len(open) - 1; i >= 0; i-- {
+			if open[i] != tag {
+				continue
+			}
+			// Close the tags opened inside this one, then reopen them.
+			for j := len(open) - 1; j >= i; j-- {
+				b.WriteString("</" + open[j] + ">")
+			}
+			for _, inner := range open[i+1:] {
+				b.WriteString("<" + inner + ">")
+			}
+			open = append(open[:i], open[i+1:]...)
+			return
+		}
+		b.WriteString("<" + tag + ">")
+		open = append(open, tag)
+	}
+	for s != "" {
+		i := strings.Index(s, "''")
+		if i < 0 {
+			b.WriteString(templateEscape(html.UnescapeString(s)))
+			break
+		}
+		b.WriteString(templateEscape(html.UnescapeString(s[:i])))
+		n := len(s[i:]) - len(strings.TrimLeft(s[i:], "'"))
+		switch {
+		case n >= 5:
+			b.WriteString(templateEscape(strings.Repeat("'", n-5)))
+			first, second := "b", "i"
+			if len(open) > 0 && open[len(open)-1] == "i" {
+				first, second = "i", "b"
+			}
+			toggle(first)
+			toggle(second)
+		case n == 3:
+			toggle("b")
+		case n == 4:
+			// An apostrophe before bold text.
+			b.WriteString("&#39;")
+			toggle("b")
+		default:
+			toggle("i")
+		}
+		s = s[i+n:]
+	}
+	for i := len(open) - 1; i >= 0; i-- {
+		b.WriteString("</" + open[i] + ">")
+	}
+	return b.String()
+}
+
+// internalLinks replaces [[links]] with anchors. Categories are collected and
+// removed, as are files and interlanguage links; links into other namespaces
+// keep only their text.
+func (c *wikitextConverter) internalLinks(s string) string {
+	var b strings.Builder
+	for {
+		start := strings.Index(s, "[[")
+		if start < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+		end := matchingLinkEnd(s, start)
+		if end < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+		b.WriteString(s[:start])
+		inner := s[start+2 : end]
+		s = s[end+2:]
+		trail := mwLinkTrailPattern.FindString(s)
+		s = s[len(trail):]
+		b.WriteString(c.internalLink(inner, trail))
+	}
+}
+
+// matchingLinkEnd returns the index of the "]]" closing the link that opens at
+// start, allowing for links nested in file captions, or -1.
+func matchingLinkEnd(s string, start int) int {
+	depth := 0
+	for i := start; i < len(s)-1; i++ {
+		switch {
+		case s[i] == '[' && s[i+1] == '[':
+			depth++
+			i++
+		case s[i] == ']' && s[i+1] == ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+			i++
+		}
+	}
+	return -1
+}
+
+func (c *wikitextConverter) internalLink(inner, trail string) string {
+	target, label, hasLabel := strings.Cut(inner, "|")
+	target = strings.TrimSpace(target)
+	leadingColon := strings.HasPrefix(target, ":")
+	target = strings.TrimPrefix(target, ":")
+	ns, name := c.namespaces.split(target)
+
+	switch {
+	case ns == mwCategoryNamespace && !leadingColon:
+		c.categories = append(c.categories, mwDisplayTitle(name))
+		return ""
+	case ns == mwFileNamespace && !leadingColon:
+		return ""
+	case ns == mwMainNamespace && !leadingColon && strings.Contains(target, ":"):
+		if prefix, _, _ := strings.Cut(target, ":"); mwInterwikiPattern.MatchString(prefix) {
+			// [[fr:Rome]]: the same article in another language.
+			return ""
+		}
+	}
+
+	if !hasLabel {
+		label = target
+	} else if label == "" {
+		// The pipe trick: [[Rome (city)|]] shows "Rome".
+		label = strings.TrimSpace(mwPipeTrickPattern.ReplaceAllString(name, ""))
+	}
+	text := c.inline(label + trail)
+
+	page, _, _ := strings.Cut(target, "#")
+	if ns != mwMainNamespace || page == "" {
+		return text
+	}
+	slug, err := mwSlug(page)
+	if err != nil {
+		return text
+	}
+	return c.hold(`<a href="/wiki/` + url.PathEscape(slug) + `">` + text + "</a>")
+}
@@ -0,0 +1,110 @@
+package app
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWikitextToHTML(t *testing.T) {
+	text := `{{Infobox city
+| name = Rome
+| population = {{formatnum:2873000}}
+}}
+'''Rome''' is the capital of [[Italy]] and of the [[Lazio|Lazio region]].<ref>Census.</ref>
+It lies on the [[Tiber River|Tiber]]s banks. __NOTOC__
+<!-- editors: expand -->
+
+== History ==
+Founded, [[Rome (legend)|]] says, by [[Romulus and Remus|''Romulus'']] & Remus.
+[[File:Colosseum.jpg|thumb|The [[Colosseum]]]]
+=== Empire ===
+* [[Augustus]]
+** first emperor
+* [[Nero]]
+# one
+# two
+; Consul : an elected magistrate
+----
+ x < y
+{| class="wikitable"
+|+ Districts
+! Name !! Area
+|-
+| [[Trastevere]] || style="color:red" | 4 km²
+|}
+See [https://example.com/rome the site], [[:Category:Cities]], [[User:Bob]], <span style="x">red</span><br/> and <nowiki>[[not a link]]</nowiki>.
+<script>alert(1)</script>
+[[Category:Capitals in Europe|Rome]]
+[[Category:Cities]]
+[[fr:Rome]]`
+
+	content, categories := wikitextToHTML("Rome", text, nil)
+	for _, want := range []string{
+		"<h1>Rome</h1>\n<div class=\"endlesswiki-body\">\n<p><b>Rome</b> is the capital",
+		`<a href="/wiki/italy">Italy</a>`,
+		`<a href="/wiki/lazio">Lazio region</a>.`,
+		`<a href="/wiki/tiber_river">Tibers</a> banks.`,
+		"<h2>History</h2>",
+		`<a href="/wiki/rome_legend">Rome</a> says`,
+		`<a href="/wiki/romulus_and_remus"><i>Romulus</i></a> &amp; Remus.`,
+		"<h3>Empire</h3>",
+		"<ul><li><a href=\"/wiki/augustus\">Augustus</a><ul><li>first emperor</li></ul>\n</li>\n<li><a href=\"/wiki/nero\">Nero</a></li></ul>\n",
+		"<ol><li>one</li>\n<li>two</li></ol>\n",
+		"<dl><dt>Consul</dt>\n<dd>an elected magistrate</dd></dl>\n",
+		"<hr>\n<pre>x &lt; y</pre>\n",
+		"<caption>Districts</caption>",
+		"<tr><th>Name</th><th>Area</th></tr>",
+		`<tr><td><a href="/wiki/trastevere">Trastevere</a></td><td>4 km²</td></tr>`,
+		`<a href="https://example.com/rome">the site</a>`,
+		"Category:Cities, User:Bob, red<br> and [[not a link]].",
+		"</div>\n",
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("content missing %q:\n%s", want, content)
+		}
+	}
+	for _, unwanted := range []string{"Infobox", "Census", "NOTOC", "editors", "Colosseum", "script", "alert", "span", "fr:", "/wiki/user"} {
+		if strings.Contains(content, unwanted) {
+			t.Errorf("content contains %q:\n%s", unwanted, content)
+		}
+	}
+	if got := strings.Join(categories, "|"); got != "Capitals in Europe|Cities" {
+		t.Errorf("categories = %q", got)
+	}
+	if got := ExtractLinkedSlugs(content); len(got) != 8 {
+		t.Errorf("linked slugs = %v", got)
+	}
+}
+
+func TestWikitextQuotes(t *testing.T) {
+	c := &wikitextConverter{}
+	for in, want := range map[string]string{
+		"''a'' '''b''' '''''c'''''": "<i>a</i> <b>b</b> <b><i>c</i></b>",
+		"'''unclosed":               "<b>unclosed</b>",
+		"l''''amour'''":             "l&#39;<b>amour</b>",
+		"it's & <fine>":             "it&#39;s &amp; ",
+		"&eacute;t&eacute;":         "été",
+	} {
+		if got := c.release(c.inline(in)); got != want {
+			t.Errorf("inline(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestMWSlug(t *testing.T) {
+	for title, want := range map[string]string{
+		"Rome":                  "rome",
+		"Star_Wars: A New Hope": "star_wars_a_new_hope",
+		"AC/DC":                 "ac_dc",
+		"Schrödinger's cat":     "schrodingers_cat",
+		"What? Where & Why #1":  "what_where_why_1",
+		"Mr. Smith Goes...":     "mr_smith_goes",
+	} {
+		if got, err := mwSlug(title); err != nil || got != want {
+			t.Errorf("mwSlug(%q) = %q, %v, want %q", title, got, err, want)
+		}
+	}
+	if _, err := mwSlug(strings.Repeat("a", maxTitleLen+1)); err == nil {
+		t.Error("over-long title accepted")
+	}
+}
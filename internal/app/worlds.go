@@ -0,0 +1,230 @@
+package app
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// worldsPathPrefix is where worlds without their own hostname are served, as
+// /w/{world}/wiki/{slug}.
+const worldsPathPrefix = "/w/"
+
+var worldNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,31}$`)
+
+// worldFile is one entry of the WORLDS_FILE configuration.
+type worldFile struct {
+	Name         string   `json:"name"`
+	Hosts        []string `json:"hosts"`
+	DSN          string   `json:"dsn"`
+	Model        string   `json:"model"`
+	SystemPrompt string   `json:"system_prompt"`
+	MainPage     string   `json:"main_page"`
+	GenLimit     int      `json:"generation_limit"`
+	Lang         string   `json:"lang"`
+	// Editions maps language codes to the databases of the world's other
+	// language editions.
+	Editions map[string]string `json:"editions"`
+}
+
+// LoadWorlds reads the named worlds listed in path. Each world gets a copy of
+// base with its own database, prompt, main page, model, and rate limit; worlds
+// without hosts are served under /w/{name}. main_page is the path of an HTML
+// file, relative to the configuration file. Language editions of the default
+// wiki are not inherited.
+func LoadWorlds(path string, base Config) ([]Config, error) {
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read worlds: %w", err)
+	}
+	var file struct {
+		Worlds []worldFile `json:"worlds"`
+	}
+	if err := json.Unmarshal(buf, &file); err != nil {
+		return nil, fmt.Errorf("parse worlds %s: %w", path, err)
+	}
+
+	seenNames := make(map[string]struct{})
+	seenHosts := make(map[string]string)
+	worlds := make([]Config, 0, len(file.Worlds))
+	for _, wf := range file.Worlds {
+		if !worldNamePattern.MatchString(wf.Name) {
+			return nil, fmt.Errorf("world name %q must be lower-case letters, digits, - or _", wf.Name)
+		}
+		if _, dup := seenNames[wf.Name]; dup {
+			return nil, fmt.Errorf("world %q is defined twice", wf.Name)
+		}
+		seenNames[wf.Name] = struct{}{}
+		if wf.DSN == "" {
+			return nil, fmt.Errorf("world %q has no dsn; each world needs its own database", wf.Name)
+		}
+		if wf.GenLimit < 0 {
+			return nil, fmt.Errorf("world %q has a negative generation_limit", wf.Name)
+		}
+
+		cfg := base
+		cfg.World = wf.Name
+		cfg.Model = wf.Model
+		cfg.SystemPrompt = strings.TrimSpace(wf.SystemPrompt)
+		cfg.GenLimit = wf.GenLimit
+		cfg.Lang = ""
+		if wf.Lang != "" {
+			if cfg.Lang, err = parseEditionLang(wf.Lang); err != nil {
+				return nil, fmt.Errorf("world %q: %w", wf.Name, err)
+			}
+		}
+		if cfg.DSN, err = normalizeMySQLDSN(wf.DSN); err != nil {
+			return nil, fmt.Errorf("world %q: %w", wf.Name, err)
+		}
+		if wf.MainPage != "" {
+			mainPath := wf.MainPage
+			if !filepath.IsAbs(mainPath) {
+				mainPath = filepath.Join(filepath.Dir(path), mainPath)
+			}
+			html, err := os.ReadFile(mainPath)
+			if err != nil {
+				return nil, fmt.Errorf("world %q main page: %w", wf.Name, err)
+			}
+			cfg.MainPageHTML = string(html)
+		}
+
+		cfg.Hosts = nil
+		for _, host := range wf.Hosts {
+			host = strings.ToLower(strings.TrimSpace(host))
+			if host == "" {
+				continue
+			}
+			if other, dup := seenHosts[host]; dup {
+				return nil, fmt.Errorf("host %q is used by worlds %q and %q", host, other, wf.Name)
+			}
+			seenHosts[host] = wf.Name
+			cfg.Hosts = append(cfg.Hosts, host)
+		}
+		if len(cfg.Hosts) == 0 {
+			cfg.BasePath = worldsPathPrefix + wf.Name
+		}
+
+		cfg.Editions = nil
+		langs := make([]string, 0, len(wf.Editions))
+		for lang := range wf.Editions {
+			langs = append(langs, lang)
+		}
+		sort.Strings(langs)
+		for _, lang := range langs {
+			edition, err := editionConfig(cfg, lang, wf.Editions[lang])
+			if err != nil {
+				return nil, fmt.Errorf("world %q: %w", wf.Name, err)
+			}
+			cfg.Editions = append(cfg.Editions, edition)
+		}
+		worlds = append(worlds, cfg)
+	}
+	return worlds, nil
+}
+
+// OpenWorld opens the database of one world or edition and builds its
+// server.
+func OpenWorld(cfg Config) (*Server, *sql.DB, error) {
+	db, err := NewDB(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, nil, fmt.Errorf("ping world %q: %w", cfg.World, err)
+	}
+	srv, err := NewServer(db, cfg)
+	if err != nil {
+		db.Close()
+		return nil, nil, err
+	}
+	return srv, db, nil
+}
+
+// WorldRouter sends each request to the wiki it addresses: a world whose
+// hostname matches, a world under /w/{name}/, or else the default wiki.
+type WorldRouter struct {
+	fallback http.Handler
+	byHost   map[string]*Server
+	byName   map[string]*Server
+}
+
+// NewWorldRouter routes to worlds, falling back to the default wiki.
+func NewWorldRouter(fallback http.Handler, worlds []*Server) *WorldRouter {
+	wr := &WorldRouter{
+		fallback: fallback,
+		byHost:   make(map[string]*Server),
+		byName:   make(map[string]*Server),
+	}
+	for _, srv := range worlds {
+		wr.byName[srv.cfg.World] = srv
+		for _, host := range srv.cfg.Hosts {
+			wr.byHost[host] = srv
+		}
+	}
+	return wr
+}
+
+// ServeHTTP satisfies http.Handler.
+func (wr *WorldRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if srv, ok := wr.byHost[requestHost(r)]; ok {
+		srv.ServeHTTP(w, r)
+		return
+	}
+
+	rest, ok := strings.CutPrefix(r.URL.Path, worldsPathPrefix)
+	if !ok {
+		wr.fallback.ServeHTTP(w, r)
+		return
+	}
+	name, _, _ := strings.Cut(rest, "/")
+	srv, ok := wr.byName[name]
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if len(srv.cfg.Hosts) > 0 {
+		// The world has its own hostname; send readers there.
+		target := "//" + srv.cfg.Hosts[0] + "/" + strings.TrimLeft(strings.TrimPrefix(r.URL.Path, worldsPathPrefix+name), "/")
+		if r.URL.RawQuery != "" {
+			target += "?" + r.URL.RawQuery
+		}
+		http.Redirect(w, r, target, http.StatusMovedPermanently)
+		return
+	}
+	if r.URL.Path == srv.cfg.BasePath {
+		http.Redirect(w, r, srv.cfg.BasePath+"/", http.StatusMovedPermanently)
+		return
+	}
+	http.StripPrefix(srv.cfg.BasePath, srv).ServeHTTP(w, r)
+}
+
+// requestHost returns the lower-cased request hostname without its port.
+func requestHost(r *http.Request) string {
+	host := r.Host
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(host)
+}
+
+// path returns the URL of p, a root-relative path such as "/wiki/rome", on
+// this wiki.
+func (s *Server) path(p string) string {
+	return s.cfg.BasePath + p
+}
+
+// genLimit is the number of generations allowed per reader per window.
+func (s *Server) genLimit() int {
+	if s.cfg.GenLimit > 0 {
+		return s.cfg.GenLimit
+	}
+	return genLimitPerWindow
+}
@@ -0,0 +1,169 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeWorlds(t *testing.T, body string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "scifi.html"), []byte("<h1>Main Page</h1><p>Stars.</p>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "worlds.json")
+	if err := os.WriteFile(path, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadWorlds(t *testing.T) {
+	path := writeWorlds(t, `{"worlds": [
+		{"name": "scifi", "dsn": "mysql://u:p@db/scifi", "model": "m", "system_prompt": " Space opera. ", "main_page": "scifi.html", "generation_limit": 5},
+		{"name": "fantasy", "hosts": ["Fantasy.Example.com"], "dsn": "u:p@tcp(db:3306)/fantasy"}
+	]}`)
+	worlds, err := LoadWorlds(path, Config{GroqAPIKey: "key"})
+	if err != nil {
+		t.Fatalf("LoadWorlds: %v", err)
+	}
+	if len(worlds) != 2 {
+		t.Fatalf("got %d worlds", len(worlds))
+	}
+
+	scifi := worlds[0]
+	if scifi.World != "scifi" || scifi.BasePath != "/w/scifi" || scifi.Model != "m" || scifi.SystemPrompt != "Space opera." || scifi.GenLimit != 5 {
+		t.Fatalf("scifi = %+v", scifi)
+	}
+	if scifi.DSN != "u:p@tcp(db:3306)/scifi?parseTime=true" || scifi.GroqAPIKey != "key" {
+		t.Fatalf("scifi dsn = %q, key = %q", scifi.DSN, scifi.GroqAPIKey)
+	}
+	if !strings.Contains(scifi.MainPageHTML, "Stars.") {
+		t.Fatalf("main page not loaded: %q", scifi.MainPageHTML)
+	}
+
+	fantasy := worlds[1]
+	if fantasy.BasePath != "" || len(fantasy.Hosts) != 1 || fantasy.Hosts[0] != "fantasy.example.com" {
+		t.Fatalf("fantasy = %+v", fantasy)
+	}
+}
+
+func TestLoadWorldsRejectsInvalid(t *testing.T) {
+	for name, body := range map[string]string{
+		"bad name":       `{"worlds": [{"name": "Sci Fi", "dsn": "u:p@tcp(db)/x"}]}`,
+		"missing dsn":    `{"worlds": [{"name": "scifi"}]}`,
+		"duplicate":      `{"worlds": [{"name": "a", "dsn": "u@tcp(db)/a"}, {"name": "a", "dsn": "u@tcp(db)/b"}]}`,
+		"shared host":    `{"worlds": [{"name": "a", "hosts": ["x.com"], "dsn": "u@tcp(db)/a"}, {"name": "b", "hosts": ["X.com"], "dsn": "u@tcp(db)/b"}]}`,
+		"no main page":   `{"worlds": [{"name": "a", "dsn": "u@tcp(db)/a", "main_page": "missing.html"}]}`,
+		"malformed json": `{"worlds": [`,
+	} {
+		if _, err := LoadWorlds(writeWorlds(t, body), Config{}); err == nil {
+			t.Errorf("%s: LoadWorlds succeeded", name)
+		}
+	}
+}
+
+func TestWorldRouter(t *testing.T) {
+	newServer := func(cfg Config) *Server {
+		srv, err := NewServer(nil, cfg)
+		if err != nil {
+			t.Fatalf("NewServer: %v", err)
+		}
+		return srv
+	}
+	router := NewWorldRouter(newServer(Config{}), []*Server{
+		newServer(Config{World: "scifi", BasePath: "/w/scifi"}),
+		newServer(Config{World: "fantasy", Hosts: []string{"fantasy.example.com"}}),
+	})
+
+	for _, tc := range []struct {
+		host, path string
+		status     int
+		location   string
+	}{
+		{"wiki.example.com", "/", http.StatusFound, "/wiki/main_page"},
+		{"wiki.example.com", "/w/scifi/", http.StatusFound, "/w/scifi/wiki/main_page"},
+		{"wiki.example.com", "/w/scifi/wiki/", http.StatusFound, "/w/scifi/wiki/main_page"},
+		{"wiki.example.com", "/w/scifi", http.StatusMovedPermanently, "/w/scifi/"},
+		{"wiki.example.com", "/w/nowhere/wiki/x", http.StatusNotFound, ""},
+		{"wiki.example.com", "/w/fantasy/wiki/elves?origin=x", http.StatusMovedPermanently, "//fantasy.example.com/wiki/elves?origin=x"},
+		{"Fantasy.example.com:8080", "/", http.StatusFound, "/wiki/main_page"},
+		{"fantasy.example.com", "/constellation", http.StatusNotFound, ""},
+	} {
+		r := httptest.NewRequest(http.MethodGet, tc.path, nil)
+		r.Host = tc.host
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, r)
+		if rec.Code != tc.status || rec.Header().Get("Location") != tc.location {
+			t.Errorf("%s%s: status %d location %q, want %d %q", tc.host, tc.path, rec.Code, rec.Header().Get("Location"), tc.status, tc.location)
+		}
+	}
+}
+
+func TestDecorateInternalLinksUnderBase(t *testing.T) {
+	content := `<p><a href="/wiki/made_up">New</a> <a href="/wiki/existing#history">Old</a> <a href="/category/x">Cat</a></p>`
+	got := decorateInternalLinksUnder("/w/scifi", content, "source_page", map[string]struct{}{"made_up": {}})
+	for _, want := range []string{
+		`data-href="/w/scifi/wiki/made_up?origin=source_page"`,
+		`<a href="/w/scifi/wiki/existing?origin=source_page#history">`,
+		`<a href="/category/x">`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output missing %s:\n%s", want, got)
+		}
+	}
+}
+
+func TestWikiTemplateUsesBasePath(t *testing.T) {
+	srv, err := NewServer(nil, Config{World: "scifi", BasePath: "/w/scifi"})
+	if err != nil {
+		t.Fatalf("NewServer: %v", err)
+	}
+	var b strings.Builder
+	if err := srv.templates.ExecuteTemplate(&b, "wiki.gohtml", map[string]any{"Title": "Rome", "Slug": "rome"}); err != nil {
+		t.Fatalf("render wiki: %v", err)
+	}
+	out := b.String()
+	for _, want := range []string{`href="/w/scifi/random"`, `action="/w/scifi/search"`, `action="/w/scifi/expand/rome"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %s", want)
+		}
+	}
+	if strings.Contains(out, "/constellation") {
+		t.Errorf("named world links to the default wiki's constellation map")
+	}
+}
+
+func TestWorldsHaveNoClusterSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "static"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	raw := `{"clusters":[{"id":1,"size":1,"sample":[{"slug":"rome"}]}]}`
+	if err := os.WriteFile(filepath.Join(dir, constellationSnapshot), []byte(raw), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(dir)
+
+	for _, tc := range []struct {
+		name string
+		cfg  Config
+		want bool
+	}{
+		{"default", Config{}, true},
+		{"world", Config{World: "scifi", BasePath: "/w/scifi"}, false},
+		{"edition", Config{Edition: true, Lang: "de", BasePath: "/de"}, false},
+	} {
+		srv, err := NewServer(nil, tc.cfg)
+		if err != nil {
+			t.Fatalf("%s: NewServer: %v", tc.name, err)
+		}
+		if got := srv.clusterSnapshot() != nil; got != tc.want {
+			t.Errorf("%s: has snapshot = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
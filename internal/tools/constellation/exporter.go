@@ -15,6 +15,7 @@ const maxClusterSample = 40
 
 type ClusterMember struct {
 	Slug      string    `json:"slug"`
+	Title     string    `json:"title"`
 	Outbound  int       `json:"outbound"`
 	CreatedAt time.Time `json:"created_at"`
 }
@@ -55,6 +56,7 @@ type Graph struct {
 
 type pageRecord struct {
 	slug     string
+	title    string
 	created  time.Time
 	outbound int
 }
@@ -75,7 +77,7 @@ type clusterPair struct {
 // Export generates a constellation snapshot written to outPath (if provided)
 // and returns the resulting Graph.
 func Export(db *sql.DB, outPath string) (Graph, error) {
-	rows, err := db.Query(`SELECT slug, content, created_at FROM pages`)
+	rows, err := db.Query(`SELECT slug, title, content, created_at FROM pages`)
 	if err != nil {
 		return Graph{}, err
 	}
@@ -83,6 +85,7 @@ func Export(db *sql.DB, outPath string) (Graph, error) {
 
 	type page struct {
 		slug    string
+		title   string
 		content string
 		created time.Time
 	}
@@ -92,7 +95,7 @@ func Export(db *sql.DB, outPath string) (Graph, error) {
 
 	for rows.Next() {
 		var p page
-		if err := rows.Scan(&p.slug, &p.content, &p.created); err != nil {
+		if err := rows.Scan(&p.slug, &p.title, &p.content, &p.created); err != nil {
 			return Graph{}, err
 		}
 		pages = append(pages, p)
@@ -126,6 +129,7 @@ func Export(db *sql.DB, outPath string) (Graph, error) {
 
 		pageRecords = append(pageRecords, pageRecord{
 			slug:     p.slug,
+			title:    p.title,
 			created:  p.created,
 			outbound: outbound,
 		})
@@ -152,6 +156,7 @@ func Export(db *sql.DB, outPath string) (Graph, error) {
 		}
 		member := ClusterMember{
 			Slug:      record.slug,
+			Title:     app.DisplayTitle(record.slug, record.title),
 			Outbound:  record.outbound,
 			CreatedAt: record.created,
 		}
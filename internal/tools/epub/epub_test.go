@@ -0,0 +1,192 @@
+package epub
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mapLoader(pages map[string][]string) loader {
+	return func(slug string) (*page, error) {
+		links, ok := pages[slug]
+		if !ok {
+			return nil, nil
+		}
+		return &page{slug: slug, title: strings.ToUpper(slug), links: links}, nil
+	}
+}
+
+func slugsOf(pages []*page) string {
+	var slugs []string
+	for _, p := range pages {
+		slugs = append(slugs, p.slug)
+	}
+	return strings.Join(slugs, " ")
+}
+
+func TestNeighbourhoodOrdersByDistance(t *testing.T) {
+	load := mapLoader(map[string][]string{
+		"rome":     {"tiber", "carthage", "unwritten"},
+		"tiber":    {"ostia", "rome"},
+		"carthage": {"hannibal", "tiber"},
+		"ostia":    {"sea"},
+		"hannibal": {},
+		"sea":      {},
+	})
+	for _, tc := range []struct {
+		depth, limit int
+		want         string
+	}{
+		{0, 0, "rome"},
+		{1, 0, "rome tiber carthage"},
+		{2, 0, "rome tiber carthage ostia hannibal"},
+		{5, 0, "rome tiber carthage ostia hannibal sea"},
+		{5, 4, "rome tiber carthage ostia"},
+	} {
+		pages, err := neighbourhood("rome", tc.depth, tc.limit, load)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := slugsOf(pages); got != tc.want {
+			t.Errorf("depth %d limit %d: %s, want %s", tc.depth, tc.limit, got, tc.want)
+		}
+	}
+	if _, err := neighbourhood("atlantis", 1, 0, load); err == nil {
+		t.Error("missing start page accepted")
+	}
+}
+
+func TestByCentrality(t *testing.T) {
+	pages, err := loadAll([]string{"a", "b", "hub", "gone", "c"}, mapLoader(map[string][]string{
+		"a":   {"hub"},
+		"b":   {"hub", "elsewhere"},
+		"c":   {"hub", "a"},
+		"hub": {"a"},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := slugsOf(byCentrality(pages)); got != "hub a c b" {
+		t.Errorf("order = %s", got)
+	}
+}
+
+func TestChapterBody(t *testing.T) {
+	inBook := map[string]struct{}{"rome.xhtml": {}}
+	content := `<h1>Tiber</h1><!-- model note --><p>Flows through <a href="rome.xhtml#history" onclick="x()">Rome</a>` +
+		` past <span class="missing-link"><b>Ostia</b></span>, see <a href="https://example.com/">this</a> and <a href="/random">more</a>.<br>` +
+		`<script>alert(1)</script></p><table><tr><td>1 &lt; 2 &amp; &nbsp;</td></tr></table>`
+	body, linked, err := chapterBody(content, inBook)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if linked != 1 {
+		t.Errorf("linked = %d", linked)
+	}
+	for _, want := range []string{
+		`<a href="rome.xhtml#history">Rome</a>`,
+		`past <span class="missing-link"><b>Ostia</b></span>,`,
+		`<a href="https://example.com/">this</a>`,
+		`and more.<br/>`,
+		`<tbody>`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("body missing %s:\n%s", want, body)
+		}
+	}
+	for _, unwanted := range []string{"onclick", "script", "model note"} {
+		if strings.Contains(body, unwanted) {
+			t.Errorf("body contains %s:\n%s", unwanted, body)
+		}
+	}
+	checkXML(t, "chapter", "<div>"+body+"</div>")
+}
+
+func checkXML(t *testing.T, name, doc string) {
+	t.Helper()
+	dec := xml.NewDecoder(strings.NewReader(doc))
+	// Entities are declared by neither EPUB nor XML itself, so strict
+	// parsing also catches leftovers such as &nbsp;.
+	dec.Strict = true
+	for {
+		if _, err := dec.Token(); err == io.EOF {
+			return
+		} else if err != nil {
+			t.Fatalf("%s is not well-formed: %v\n%s", name, err, doc)
+		}
+	}
+}
+
+func TestBookContainer(t *testing.T) {
+	b := &book{
+		id:       "urn:uuid:00000000-0000-5000-8000-000000000000",
+		title:    "The Tiber & Its Cities, Ancient and Modern",
+		subtitle: "An EndlessWiki reader",
+		lang:     "en",
+		modified: time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC),
+		chapters: []chapter{
+			{file: "chapters/tiber.xhtml", id: "c001", title: "Tiber", body: "<h1>Tiber</h1>"},
+			{file: "chapters/東京.xhtml", id: "c002", title: "Tokyo", body: "<h1>Tokyo</h1>"},
+		},
+	}
+	var buf bytes.Buffer
+	if err := b.write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	if string(data[30:38]) != "mimetype" || string(data[38:58]) != "application/epub+zip" {
+		t.Fatalf("container does not start with the mimetype file: %q", data[:58])
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if zr.File[0].Method != zip.Store {
+		t.Error("mimetype is compressed")
+	}
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[f.Name] = string(content)
+		if strings.HasSuffix(f.Name, ".xhtml") || strings.HasSuffix(f.Name, ".xml") || strings.HasSuffix(f.Name, ".opf") || strings.HasSuffix(f.Name, ".svg") {
+			checkXML(t, f.Name, string(content))
+		}
+	}
+
+	opf := files["OEBPS/content.opf"]
+	for _, want := range []string{
+		`<dc:title>The Tiber &amp; Its Cities, Ancient and Modern</dc:title>`,
+		`<meta property="dcterms:modified">2024-05-01T12:00:00Z</meta>`,
+		`properties="cover-image"`,
+		`properties="nav"`,
+		`<itemref idref="cover"/>`,
+		`<item id="c002" href="chapters/%E6%9D%B1%E4%BA%AC.xhtml" media-type="application/xhtml+xml"/>`,
+		`<itemref idref="c002"/>`,
+	} {
+		if !strings.Contains(opf, want) {
+			t.Errorf("content.opf missing %s", want)
+		}
+	}
+	if nav := files["OEBPS/nav.xhtml"]; !strings.Contains(nav, `epub:type="toc"`) || !strings.Contains(nav, `<a href="chapters/%E6%9D%B1%E4%BA%AC.xhtml">Tokyo</a>`) {
+		t.Errorf("nav.xhtml = %s", nav)
+	}
+	if chapter := files["OEBPS/chapters/tiber.xhtml"]; !strings.Contains(chapter, `<section epub:type="chapter" id="c001">`) || !strings.Contains(chapter, `href="../style.css"`) {
+		t.Errorf("chapters/tiber.xhtml = %s", chapter)
+	}
+	if cover := files["OEBPS/cover.svg"]; strings.Count(cover, "font-size=\"48\"") != 3 {
+		t.Errorf("title not wrapped onto three lines:\n%s", cover)
+	}
+}
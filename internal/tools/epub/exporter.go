@@ -0,0 +1,449 @@
+package epub
+
+import (
+	"context"
+	"crypto/sha1"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	nethtml "golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"endlesswiki/internal/app"
+	"endlesswiki/internal/tools/constellation"
+)
+
+// Options selects the pages of a book. A book is either the neighbourhood of
+// Start, up to Depth links away, or a cluster of the constellation snapshot.
+type Options struct {
+	Start string
+	Depth int
+	// Cluster is a cluster id from the snapshot at Snapshot, used when Start
+	// is empty. Snapshots only list the 40 most-linked members of each
+	// cluster, so a cluster book has at most that many chapters.
+	Cluster  int
+	Snapshot string
+	// Limit caps the number of chapters.
+	Limit int
+	// Title defaults to the title of the first chapter.
+	Title string
+	// Language is the wiki's BCP 47 code; it defaults to "en".
+	Language string
+}
+
+// Stats reports what Export wrote.
+type Stats struct {
+	Chapters int
+	// Links counts links that point inside the book; links to other pages
+	// were rendered as plain text.
+	Links int
+}
+
+// page is a stored page with its links resolved through redirects, in
+// document order.
+type page struct {
+	slug    string
+	title   string
+	content string
+	links   []string
+}
+
+// loader returns the page slug names, following redirects, or nil when it
+// has not been written.
+type loader func(slug string) (*page, error)
+
+// chapterDir holds the chapters inside the container, apart from the book's
+// own documents, so that no slug can clash with them.
+const chapterDir = "chapters"
+
+// Export writes an EPUB 3 book of the pages selected by opts to outPath.
+// Chapters are ordered by their distance from the start page, or by their
+// centrality within the cluster, and are rendered as the wiki shows them,
+// with infoboxes, tables of contents, and categories. Links between chapters,
+// also through redirects, lead to the linked chapter; links to pages outside
+// the book become plain text.
+func Export(db *sql.DB, outPath string, opts Options) (Stats, error) {
+	var stats Stats
+	if opts.Language == "" {
+		opts.Language = "en"
+	}
+	load, redirects, err := dbLoader(db)
+	if err != nil {
+		return stats, err
+	}
+
+	var pages []*page
+	var subtitle string
+	if opts.Start != "" {
+		start, err := app.NormalizeSlug(opts.Start)
+		if err != nil {
+			return stats, fmt.Errorf("start %q: %w", opts.Start, err)
+		}
+		if pages, err = neighbourhood(start, opts.Depth, opts.Limit, load); err != nil {
+			return stats, err
+		}
+		subtitle = "An EndlessWiki reader"
+	} else {
+		members, err := clusterMembers(opts.Snapshot, opts.Cluster)
+		if err != nil {
+			return stats, err
+		}
+		if pages, err = loadAll(members, load); err != nil {
+			return stats, err
+		}
+		if len(pages) == 0 {
+			return stats, fmt.Errorf("none of the pages of cluster %d exist", opts.Cluster)
+		}
+		pages = byCentrality(pages)
+		if opts.Limit > 0 && len(pages) > opts.Limit {
+			pages = pages[:opts.Limit]
+		}
+		subtitle = fmt.Sprintf("EndlessWiki constellation %d", opts.Cluster)
+	}
+
+	// Every chapter is written as slug.xhtml; aliases of chapters link to
+	// their target.
+	entries := make(map[string]string, len(pages))
+	for _, p := range pages {
+		entries[p.slug] = p.slug
+	}
+	for alias, target := range redirects {
+		_, isPage := entries[alias]
+		if _, ok := entries[target]; ok && !isPage {
+			entries[alias] = target
+		}
+	}
+	renderer, err := app.NewArticleRenderer(db, opts.Language, entries, ".xhtml")
+	if err != nil {
+		return stats, err
+	}
+
+	b := &book{
+		title:    opts.Title,
+		subtitle: subtitle,
+		lang:     opts.Language,
+		modified: time.Now().UTC().Truncate(time.Second),
+	}
+	if b.title == "" {
+		b.title = pages[0].title
+	}
+	// Rebuilding the same selection gives the same identifier, so readers
+	// treat it as a new edition of the book.
+	id := sha1.New()
+	inBook := make(map[string]struct{}, len(pages))
+	for _, p := range pages {
+		id.Write([]byte(p.slug + "\n"))
+		inBook[url.PathEscape(p.slug)+".xhtml"] = struct{}{}
+	}
+	sum := id.Sum(nil)
+	sum[6] = sum[6]&0x0f | 0x50
+	sum[8] = sum[8]&0x3f | 0x80
+	b.id = fmt.Sprintf("urn:uuid:%x-%x-%x-%x-%x", sum[0:4], sum[4:6], sum[6:8], sum[8:10], sum[10:16])
+
+	ctx := context.Background()
+	for i, p := range pages {
+		article, err := renderer.Render(ctx, p.slug)
+		if err != nil {
+			return stats, fmt.Errorf("chapter %s: %w", p.slug, err)
+		}
+		if article == nil {
+			return stats, fmt.Errorf("chapter %s: page was removed during the export", p.slug)
+		}
+		body, linked, err := chapterBody(string(article.Body), inBook)
+		if err != nil {
+			return stats, fmt.Errorf("chapter %s: %w", p.slug, err)
+		}
+		b.chapters = append(b.chapters, chapter{
+			file:  chapterDir + "/" + p.slug + ".xhtml",
+			id:    fmt.Sprintf("c%03d", i+1),
+			title: article.Title,
+			body:  body,
+		})
+		stats.Links += linked
+	}
+	stats.Chapters = len(b.chapters)
+	return stats, writeFile(outPath, b)
+}
+
+// writeFile writes the book next to outPath and moves it into place.
+func writeFile(outPath string, b *book) error {
+	f, err := os.CreateTemp(filepath.Dir(outPath), filepath.Base(outPath)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if err := b.write(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), outPath)
+}
+
+// dbLoader returns a loader for the pages in db and the redirects it
+// follows, from alias to target.
+func dbLoader(db *sql.DB) (loader, map[string]string, error) {
+	rows, err := db.Query(`SELECT alias_slug, target_slug FROM redirects`)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+	redirects := make(map[string]string)
+	for rows.Next() {
+		var alias, target string
+		if err := rows.Scan(&alias, &target); err != nil {
+			return nil, nil, err
+		}
+		redirects[alias] = target
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	resolve := func(slug string) string {
+		if target, ok := redirects[slug]; ok {
+			return target
+		}
+		return slug
+	}
+
+	return func(slug string) (*page, error) {
+		p := &page{slug: resolve(slug)}
+		err := db.QueryRow(`SELECT title, content FROM pages WHERE slug = ?`, p.slug).Scan(&p.title, &p.content)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		p.title = app.DisplayTitle(p.slug, p.title)
+		seen := map[string]struct{}{p.slug: {}}
+		for _, target := range app.ExtractLinkedSlugs(p.content) {
+			target = resolve(target)
+			if _, ok := seen[target]; !ok {
+				seen[target] = struct{}{}
+				p.links = append(p.links, target)
+			}
+		}
+		return p, nil
+	}, redirects, nil
+}
+
+// neighbourhood walks links breadth-first from start, up to depth links away
+// and at most limit pages (when limit > 0). Pages at the same distance keep
+// the order in which they are first linked.
+func neighbourhood(start string, depth, limit int, load loader) ([]*page, error) {
+	first, err := load(start)
+	if err != nil {
+		return nil, err
+	}
+	if first == nil {
+		return nil, fmt.Errorf("page %s has not been written", start)
+	}
+	pages := []*page{first}
+	seen := map[string]struct{}{start: {}, first.slug: {}}
+	frontier := pages
+	for d := 0; d < depth && len(frontier) > 0; d++ {
+		var next []*page
+		for _, p := range frontier {
+			for _, slug := range p.links {
+				if limit > 0 && len(pages) >= limit {
+					return pages, nil
+				}
+				if _, ok := seen[slug]; ok {
+					continue
+				}
+				seen[slug] = struct{}{}
+				linked, err := load(slug)
+				if err != nil {
+					return nil, err
+				}
+				if linked == nil {
+					continue
+				}
+				pages = append(pages, linked)
+				next = append(next, linked)
+			}
+		}
+		frontier = next
+	}
+	return pages, nil
+}
+
+// clusterMembers returns the slugs the constellation snapshot lists for a
+// cluster.
+func clusterMembers(snapshot string, id int) ([]string, error) {
+	data, err := os.ReadFile(snapshot)
+	if err != nil {
+		return nil, err
+	}
+	var graph constellation.Graph
+	if err := json.Unmarshal(data, &graph); err != nil {
+		return nil, fmt.Errorf("%s: %w", snapshot, err)
+	}
+	for _, cluster := range graph.Clusters {
+		if cluster.ID != id {
+			continue
+		}
+		slugs := make([]string, len(cluster.Sample))
+		for i, member := range cluster.Sample {
+			slugs[i] = member.Slug
+		}
+		return slugs, nil
+	}
+	return nil, fmt.Errorf("%s has no cluster %d", snapshot, id)
+}
+
+// loadAll loads slugs, skipping pages that no longer exist and duplicates
+// left behind by merges.
+func loadAll(slugs []string, load loader) ([]*page, error) {
+	var pages []*page
+	seen := make(map[string]struct{})
+	for _, slug := range slugs {
+		p, err := load(slug)
+		if err != nil {
+			return nil, err
+		}
+		if p == nil {
+			continue
+		}
+		if _, ok := seen[p.slug]; !ok {
+			seen[p.slug] = struct{}{}
+			pages = append(pages, p)
+		}
+	}
+	return pages, nil
+}
+
+// byCentrality orders pages by how many of the others they link to or are
+// linked from, most connected first.
+func byCentrality(pages []*page) []*page {
+	included := make(map[string]struct{}, len(pages))
+	for _, p := range pages {
+		included[p.slug] = struct{}{}
+	}
+	degree := make(map[string]int, len(pages))
+	for _, p := range pages {
+		for _, target := range p.links {
+			if _, ok := included[target]; ok {
+				degree[p.slug]++
+				degree[target]++
+			}
+		}
+	}
+	sorted := append([]*page(nil), pages...)
+	sort.SliceStable(sorted, func(i, j int) bool { return degree[sorted[i].slug] > degree[sorted[j].slug] })
+	return sorted
+}
+
+// xmlName matches attribute names that are also valid, namespace-free XML
+// names.
+var xmlName = regexp.MustCompile(`^[a-z_][a-z0-9_.-]*$`)
+
+// droppedElements have no place in a book: scripts, styles, and interactive
+// elements.
+var droppedElements = map[atom.Atom]bool{
+	atom.Script: true, atom.Style: true, atom.Noscript: true, atom.Iframe: true,
+	atom.Form: true, atom.Input: true, atom.Button: true, atom.Select: true, atom.Textarea: true,
+}
+
+// chapterBody converts a rendered article to XHTML for a book. Links to the
+// chapters in inBook, by their href, are kept, as are external and same-page
+// links; other site-relative links are unwrapped to plain text. It returns the
+// body and the number of links kept inside the book.
+func chapterBody(content string, inBook map[string]struct{}) (string, int, error) {
+	context := &nethtml.Node{Type: nethtml.ElementNode, Data: "body", DataAtom: atom.Body}
+	nodes, err := nethtml.ParseFragment(strings.NewReader(content), context)
+	if err != nil {
+		return "", 0, err
+	}
+	root := &nethtml.Node{Type: nethtml.ElementNode, Data: "div", DataAtom: atom.Div}
+	for _, n := range nodes {
+		root.AppendChild(n)
+	}
+	linked := cleanNode(root, inBook)
+
+	var b strings.Builder
+	for n := root.FirstChild; n != nil; n = n.NextSibling {
+		if err := nethtml.Render(&b, n); err != nil {
+			return "", 0, err
+		}
+	}
+	return b.String(), linked, nil
+}
+
+func cleanNode(n *nethtml.Node, inBook map[string]struct{}) int {
+	linked := 0
+	for c := n.FirstChild; c != nil; {
+		next := c.NextSibling
+		switch {
+		case c.Type == nethtml.CommentNode || c.Type == nethtml.DoctypeNode:
+			n.RemoveChild(c)
+		case c.Type != nethtml.ElementNode:
+		case droppedElements[c.DataAtom]:
+			n.RemoveChild(c)
+		default:
+			linked += cleanNode(c, inBook)
+			attrs := c.Attr[:0]
+			for _, attr := range c.Attr {
+				if attr.Namespace == "" && xmlName.MatchString(attr.Key) && !strings.HasPrefix(attr.Key, "on") {
+					attrs = append(attrs, attr)
+				}
+			}
+			c.Attr = attrs
+			if c.DataAtom != atom.A {
+				break
+			}
+			switch internal, keep := bookLink(c, inBook); {
+			case internal:
+				linked++
+			case !keep:
+				// Replace the anchor with its children.
+				for gc := c.FirstChild; gc != nil; {
+					following := gc.NextSibling
+					c.RemoveChild(gc)
+					n.InsertBefore(gc, c)
+					gc = following
+				}
+				n.RemoveChild(c)
+			}
+		}
+		c = next
+	}
+	return linked
+}
+
+// bookLink decides what becomes of an anchor: links to a chapter are internal
+// and kept, external and same-page links are kept as they are, and the rest
+// are unwrapped.
+func bookLink(a *nethtml.Node, inBook map[string]struct{}) (internal, keep bool) {
+	for _, attr := range a.Attr {
+		if attr.Key != "href" {
+			continue
+		}
+		value := strings.TrimSpace(attr.Val)
+		file, _, _ := strings.Cut(value, "#")
+		if _, ok := inBook[file]; ok {
+			return true, true
+		}
+		lower := strings.ToLower(value)
+		for _, prefix := range []string{"#", "http://", "https://", "mailto:"} {
+			if strings.HasPrefix(lower, prefix) {
+				return false, true
+			}
+		}
+		return false, false
+	}
+	// Anchors without href are only targets.
+	return false, true
+}
@@ -0,0 +1,232 @@
+package epub
+
+import (
+	"archive/zip"
+	"fmt"
+	"hash/crc32"
+	"html"
+	"io"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// book is an EPUB 3 publication: a cover page, a navigation document, and
+// chapters in reading order.
+type book struct {
+	id       string
+	title    string
+	subtitle string
+	lang     string
+	modified time.Time
+	chapters []chapter
+}
+
+type chapter struct {
+	// file is the chapter's path below the package document, and id both its
+	// manifest id and the id of its section.
+	file  string
+	id    string
+	title string
+	body  string
+}
+
+const stylesheet = `body { font-family: serif; line-height: 1.5; }
+h1, h2, h3 { font-family: sans-serif; font-weight: normal; }
+.cover { margin: 0; padding: 0; text-align: center; }
+.cover img { max-width: 100%; max-height: 100%; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #a2a9b1; padding: 0.2em 0.4em; }
+.missing-link { color: #54595d; }
+.infobox { float: right; margin: 0 0 1em 1em; max-width: 50%; font-size: 0.9em; }
+.toc ul { list-style: none; padding-left: 1em; }
+#catlinks { clear: both; margin-top: 2em; font-size: 0.9em; }
+#catlinks ul { display: inline; padding: 0; }
+#catlinks li { display: inline; margin-right: 0.5em; }
+`
+
+const containerXML = `<?xml version="1.0" encoding="UTF-8"?>
+<container version="1.0" xmlns="urn:oasis:names:tc:opendocument:xmlns:container">
+  <rootfiles>
+    <rootfile full-path="OEBPS/content.opf" media-type="application/oebps-package+xml"/>
+  </rootfiles>
+</container>
+`
+
+// write writes b to w as an EPUB container.
+func (b *book) write(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	// The mimetype file comes first, stored and without extra fields, so
+	// that readers can sniff it at a fixed offset.
+	mimetype := []byte("application/epub+zip")
+	raw, err := zw.CreateRaw(&zip.FileHeader{
+		Name:               "mimetype",
+		Method:             zip.Store,
+		CRC32:              crc32.ChecksumIEEE(mimetype),
+		CompressedSize64:   uint64(len(mimetype)),
+		UncompressedSize64: uint64(len(mimetype)),
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := raw.Write(mimetype); err != nil {
+		return err
+	}
+
+	files := []struct{ name, content string }{
+		{"META-INF/container.xml", containerXML},
+		{"OEBPS/content.opf", b.packageDocument()},
+		{"OEBPS/nav.xhtml", b.navDocument()},
+		{"OEBPS/cover.xhtml", b.coverPage()},
+		{"OEBPS/cover.svg", b.coverImage()},
+		{"OEBPS/style.css", stylesheet},
+	}
+	for _, ch := range b.chapters {
+		files = append(files, struct{ name, content string }{"OEBPS/" + ch.file, b.chapterDocument(ch)})
+	}
+	for _, f := range files {
+		fw, err := zw.CreateHeader(&zip.FileHeader{Name: f.name, Method: zip.Deflate, Modified: b.modified})
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(fw, f.content); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+func (b *book) packageDocument() string {
+	var s strings.Builder
+	s.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<package xmlns="http://www.idpf.org/2007/opf" version="3.0" unique-identifier="book-id" xml:lang="` + esc(b.lang) + `">
+  <metadata xmlns:dc="http://purl.org/dc/elements/1.1/">
+    <dc:identifier id="book-id">` + esc(b.id) + `</dc:identifier>
+    <dc:title>` + esc(b.title) + `</dc:title>
+    <dc:language>` + esc(b.lang) + `</dc:language>
+    <dc:creator>EndlessWiki</dc:creator>
+    <meta property="dcterms:modified">` + b.modified.UTC().Format("2006-01-02T15:04:05Z") + `</meta>
+    <meta name="cover" content="cover-image"/>
+  </metadata>
+  <manifest>
+    <item id="nav" href="nav.xhtml" media-type="application/xhtml+xml" properties="nav"/>
+    <item id="cover" href="cover.xhtml" media-type="application/xhtml+xml"/>
+    <item id="cover-image" href="cover.svg" media-type="image/svg+xml" properties="cover-image"/>
+    <item id="style" href="style.css" media-type="text/css"/>
+`)
+	for _, ch := range b.chapters {
+		s.WriteString(`    <item id="` + ch.id + `" href="` + esc(escapePath(ch.file)) + `" media-type="application/xhtml+xml"/>` + "\n")
+	}
+	s.WriteString(`  </manifest>
+  <spine>
+    <itemref idref="cover"/>
+    <itemref idref="nav"/>
+`)
+	for _, ch := range b.chapters {
+		s.WriteString(`    <itemref idref="` + ch.id + `"/>` + "\n")
+	}
+	s.WriteString("  </spine>\n</package>\n")
+	return s.String()
+}
+
+func (b *book) navDocument() string {
+	var s strings.Builder
+	s.WriteString(`<nav epub:type="toc" id="toc">
+<h1>` + esc(b.title) + `</h1>
+<ol>
+`)
+	for _, ch := range b.chapters {
+		s.WriteString(`<li><a href="` + esc(escapePath(ch.file)) + `">` + esc(ch.title) + "</a></li>\n")
+	}
+	s.WriteString("</ol>\n</nav>")
+	return b.xhtml("", b.title, s.String(), "")
+}
+
+func (b *book) coverPage() string {
+	body := `<section epub:type="cover" class="cover"><img src="cover.svg" alt="` + esc(b.title) + `"/></section>`
+	return b.xhtml("", b.title, body, "cover")
+}
+
+func (b *book) chapterDocument(ch chapter) string {
+	body := `<section epub:type="chapter" id="` + ch.id + `">
+` + ch.body + `
+</section>`
+	return b.xhtml(strings.Repeat("../", strings.Count(ch.file, "/")), ch.title, body, "")
+}
+
+// xhtml wraps body in a document root (a relative path ending in "/", or
+// empty) below the package document.
+func (b *book) xhtml(root, title, body, class string) string {
+	if class != "" {
+		class = ` class="` + class + `"`
+	}
+	return `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE html>
+<html xmlns="http://www.w3.org/1999/xhtml" xmlns:epub="http://www.idpf.org/2007/ops" lang="` + esc(b.lang) + `" xml:lang="` + esc(b.lang) + `">
+<head>
+<meta charset="UTF-8"/>
+<title>` + esc(title) + `</title>
+<link rel="stylesheet" type="text/css" href="` + root + `style.css"/>
+</head>
+<body` + class + `>
+` + body + `
+</body>
+</html>
+`
+}
+
+// coverTitleWidth is how many characters of the title fit on a cover line.
+const coverTitleWidth = 18
+
+// coverImage draws the cover: the title, wrapped, above the subtitle.
+func (b *book) coverImage() string {
+	lines := wrapWords(b.title, coverTitleWidth)
+	var s strings.Builder
+	s.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<svg xmlns="http://www.w3.org/2000/svg" version="1.1" width="600" height="800" viewBox="0 0 600 800">
+<rect width="600" height="800" fill="#202122"/>
+<rect x="40" y="40" width="520" height="720" fill="none" stroke="#f8f9fa" stroke-width="2"/>
+`)
+	y := 340 - 30*(len(lines)-1)
+	for _, line := range lines {
+		fmt.Fprintf(&s, `<text x="300" y="%d" font-family="serif" font-size="48" fill="#f8f9fa" text-anchor="middle">%s</text>`+"\n", y, esc(line))
+		y += 60
+	}
+	fmt.Fprintf(&s, `<text x="300" y="%d" font-family="sans-serif" font-size="24" fill="#c8ccd1" text-anchor="middle">%s</text>`+"\n", y+40, esc(b.subtitle))
+	s.WriteString("</svg>\n")
+	return s.String()
+}
+
+// wrapWords breaks s into lines of at most width runes, except for single
+// words that are longer.
+func wrapWords(s string, width int) []string {
+	var lines []string
+	var line string
+	for _, word := range strings.Fields(s) {
+		if line != "" && len([]rune(line))+1+len([]rune(word)) > width {
+			lines = append(lines, line)
+			line = ""
+		}
+		if line != "" {
+			line += " "
+		}
+		line += word
+	}
+	if line != "" || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// escapePath escapes each segment of a path for use as an href.
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+func esc(s string) string {
+	return html.EscapeString(s)
+}
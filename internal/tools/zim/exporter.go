@@ -0,0 +1,320 @@
+package zim
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"fmt"
+	"html/template"
+	"image"
+	"image/color"
+	"image/png"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"golang.org/x/text/language"
+
+	"endlesswiki/internal/app"
+)
+
+const mainPageSlug = "main_page"
+
+// Options describes the archive in its metadata.
+type Options struct {
+	// Name identifies the archive across versions, e.g. "endlesswiki_en".
+	Name string
+	// Title defaults to "EndlessWiki".
+	Title       string
+	Description string
+	// Language is the wiki's BCP 47 code; it defaults to "en".
+	Language  string
+	Creator   string
+	Publisher string
+}
+
+// Stats reports what Export wrote.
+type Stats struct {
+	Articles  int
+	Redirects int
+}
+
+type pageRecord struct {
+	slug  string
+	title string
+}
+
+var articleTemplate = template.Must(template.New("article").Parse(`<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>{{.Title}}</title>
+<link rel="stylesheet" href="style.css">
+</head>
+<body>
+<main>
+{{.Content}}
+</main>
+{{if not .Created.IsZero}}<footer>Discovered {{.Created.Format "January 2, 2006"}}</footer>{{end}}
+</body>
+</html>
+`))
+
+var indexTemplate = template.Must(template.New("index").Parse(`<h1>{{.Title}}</h1>
+<ul class="index">
+{{range .Pages}}<li><a href="{{.Href}}">{{.Title}}</a></li>
+{{end}}</ul>
+`))
+
+const stylesheet = `body { margin: 0 auto; max-width: 50em; padding: 1em; font-family: Georgia, serif; line-height: 1.5; color: #202122; }
+h1, h2, h3 { font-family: sans-serif; font-weight: normal; border-bottom: 1px solid #a2a9b1; }
+a { color: #0645ad; text-decoration: none; }
+.missing-link { color: #54595d; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #a2a9b1; padding: 0.2em 0.4em; }
+.infobox { float: right; margin: 0 0 1em 1em; max-width: 22em; font-size: 0.9em; background: #f8f9fa; }
+.toc { display: inline-block; margin: 1em 0; padding: 0.5em 1em; border: 1px solid #a2a9b1; background: #f8f9fa; }
+.toc ul { list-style: none; padding-left: 1em; }
+#catlinks { clear: both; margin-top: 2em; padding: 0.5em; border: 1px solid #a2a9b1; }
+#catlinks ul { display: inline; padding: 0; }
+#catlinks li { display: inline; margin-right: 0.5em; }
+footer { margin-top: 2em; font-size: 0.85em; color: #54595d; }
+`
+
+// Export writes every stored page of the wiki in db to a ZIM archive at
+// outPath, for offline reading in Kiwix. Articles are rendered as the wiki
+// shows them, with infoboxes, tables of contents, and categories, and keep
+// their stored titles, which also make up the title index; links to pages
+// that were never written are rendered as plain text. When no main page has
+// been generated yet, an alphabetical index takes its place.
+func Export(db *sql.DB, outPath string, opts Options) (Stats, error) {
+	var stats Stats
+	if opts.Language == "" {
+		opts.Language = "en"
+	}
+	if opts.Title == "" {
+		opts.Title = "EndlessWiki"
+	}
+	tag, err := language.Parse(opts.Language)
+	if err != nil {
+		return stats, err
+	}
+	base, _ := tag.Base()
+
+	pages, err := loadPages(db)
+	if err != nil {
+		return stats, err
+	}
+	redirects, err := loadRedirects(db)
+	if err != nil {
+		return stats, err
+	}
+	// Aliases get redirect entries of their own, so every entry is named
+	// after its slug.
+	existing := make(map[string]string, len(pages)+len(redirects))
+	for _, p := range pages {
+		existing[p.slug] = p.slug
+	}
+	var aliases [][2]string
+	for _, redirect := range redirects {
+		_, isPage := existing[redirect[0]]
+		if _, ok := existing[redirect[1]]; ok && !isPage {
+			aliases = append(aliases, redirect)
+		}
+	}
+	for _, redirect := range aliases {
+		existing[redirect[0]] = redirect[0]
+	}
+
+	dir := filepath.Dir(outPath)
+	w, err := NewWriter(dir)
+	if err != nil {
+		return stats, err
+	}
+	defer w.Close()
+
+	renderer, err := app.NewArticleRenderer(db, opts.Language, existing, "")
+	if err != nil {
+		return stats, err
+	}
+	ctx := context.Background()
+	hasMainPage := false
+	for _, p := range pages {
+		article, err := renderer.Render(ctx, p.slug)
+		if err != nil {
+			return stats, fmt.Errorf("render %s: %w", p.slug, err)
+		}
+		if article == nil {
+			// Merged away since the listing.
+			continue
+		}
+		doc, err := renderArticle(opts.Language, article.Title, article.Body, article.Created)
+		if err != nil {
+			return stats, err
+		}
+		if err := w.AddItem('C', article.Slug, article.Title, "text/html", doc, true); err != nil {
+			return stats, err
+		}
+		hasMainPage = hasMainPage || article.Slug == mainPageSlug
+		stats.Articles++
+	}
+
+	for _, redirect := range aliases {
+		if err := w.AddRedirect('C', redirect[0], app.SlugTitle(redirect[0]), 'C', redirect[1]); err != nil {
+			return stats, err
+		}
+		stats.Redirects++
+	}
+
+	if !hasMainPage {
+		doc, err := renderIndex(opts, pages)
+		if err != nil {
+			return stats, err
+		}
+		if err := w.AddItem('C', mainPageSlug, opts.Title, "text/html", doc, false); err != nil {
+			return stats, err
+		}
+	}
+	w.SetMainPage(mainPageSlug)
+	if err := w.AddItem('C', "style.css", "", "text/css", []byte(stylesheet), false); err != nil {
+		return stats, err
+	}
+
+	metadata := [][2]string{
+		{"Name", opts.Name},
+		{"Title", opts.Title},
+		{"Description", opts.Description},
+		{"Language", base.ISO3()},
+		{"Creator", opts.Creator},
+		{"Publisher", opts.Publisher},
+		{"Date", time.Now().UTC().Format(time.DateOnly)},
+	}
+	for _, m := range metadata {
+		if m[1] == "" {
+			continue
+		}
+		if err := w.AddMetadata(m[0], m[1]); err != nil {
+			return stats, err
+		}
+	}
+	icon, err := illustration()
+	if err != nil {
+		return stats, err
+	}
+	if err := w.AddItem('M', "Illustration_48x48@1", "", "image/png", icon, false); err != nil {
+		return stats, err
+	}
+
+	return stats, writeFile(outPath, w)
+}
+
+// writeFile finishes the archive next to outPath and moves it into place, so
+// an interrupted export never leaves a truncated archive behind.
+func writeFile(outPath string, w *Writer) error {
+	f, err := os.CreateTemp(filepath.Dir(outPath), filepath.Base(outPath)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if err := w.Finish(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), outPath)
+}
+
+func loadPages(db *sql.DB) ([]pageRecord, error) {
+	rows, err := db.Query(`SELECT slug, title FROM pages ORDER BY slug`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var pages []pageRecord
+	for rows.Next() {
+		var p pageRecord
+		if err := rows.Scan(&p.slug, &p.title); err != nil {
+			return nil, err
+		}
+		p.title = app.DisplayTitle(p.slug, p.title)
+		pages = append(pages, p)
+	}
+	return pages, rows.Err()
+}
+
+func loadRedirects(db *sql.DB) ([][2]string, error) {
+	rows, err := db.Query(`SELECT alias_slug, target_slug FROM redirects ORDER BY alias_slug`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var redirects [][2]string
+	for rows.Next() {
+		var redirect [2]string
+		if err := rows.Scan(&redirect[0], &redirect[1]); err != nil {
+			return nil, err
+		}
+		redirects = append(redirects, redirect)
+	}
+	return redirects, rows.Err()
+}
+
+func renderArticle(lang, title string, content template.HTML, created time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	err := articleTemplate.Execute(&buf, struct {
+		Lang    string
+		Title   string
+		Content template.HTML
+		Created time.Time
+	}{lang, title, content, created})
+	return buf.Bytes(), err
+}
+
+// renderIndex is the stand-in main page: every article in title order.
+func renderIndex(opts Options, pages []pageRecord) ([]byte, error) {
+	type link struct{ Href, Title string }
+	links := make([]link, len(pages))
+	for i, p := range pages {
+		links[i] = link{Href: url.PathEscape(p.slug), Title: p.title}
+	}
+	sort.SliceStable(links, func(i, j int) bool { return links[i].Title < links[j].Title })
+	var buf bytes.Buffer
+	if err := indexTemplate.Execute(&buf, struct {
+		Title string
+		Pages []link
+	}{opts.Title, links}); err != nil {
+		return nil, err
+	}
+	return renderArticle(opts.Language, opts.Title, template.HTML(buf.String()), time.Time{})
+}
+
+// illustration is the 48×48 icon Kiwix shows in its library.
+func illustration() ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, 48, 48))
+	ink, paper := color.RGBA{0x20, 0x21, 0x22, 0xff}, color.RGBA{0xf8, 0xf9, 0xfa, 0xff}
+	for y := 0; y < 48; y++ {
+		for x := 0; x < 48; x++ {
+			// Two rings side by side, an infinity sign.
+			c := paper
+			for _, cx := range []int{16, 32} {
+				dx, dy := x-cx, y-24
+				if d := dx*dx + dy*dy; d >= 7*7 && d <= 10*10 {
+					c = ink
+				}
+			}
+			img.Set(x, y, c)
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
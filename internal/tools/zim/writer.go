@@ -0,0 +1,360 @@
+package zim
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/md5"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// The archives follow version 6.1 of the ZIM format
+// (https://wiki.openzim.org/wiki/ZIM_file_format): content lives in the C
+// namespace, metadata in M, the main page redirect in W, and the title
+// listings in X. Clusters are stored uncompressed, since the format's
+// compressions (xz and zstd) are not in the standard library.
+const (
+	magicNumber  = 72173914
+	majorVersion = 6
+	minorVersion = 1
+	headerSize   = 80
+
+	// clusterSize is the size at which a cluster is closed and a new one
+	// started.
+	clusterSize = 1 << 20
+
+	compressionNone = 1
+	redirectMime    = 0xffff
+	noPage          = 0xffffffff
+
+	listingMime = "application/octet-stream+zimlisting"
+	// frontListing indexes the entries readers offer in search
+	// suggestions, in title order.
+	frontListing = "listing/titleOrdered/v1"
+)
+
+// entry is a directory entry: an item stored in a cluster, or a redirect.
+type entry struct {
+	namespace byte
+	path      string
+	title     string
+	front     bool
+
+	mime    uint16
+	cluster uint32
+	blob    uint32
+
+	redirect  bool
+	targetKey string
+	target    *entry
+
+	index uint32
+}
+
+func (e *entry) key() string {
+	return string(e.namespace) + e.path
+}
+
+// sortTitle is the title entries are ordered by in the title index.
+func (e *entry) sortTitle() string {
+	if e.title != "" {
+		return e.title
+	}
+	return e.path
+}
+
+// Writer builds a ZIM archive. Item content is spooled to a temporary file as
+// it is added, so memory use does not grow with the size of the archive.
+type Writer struct {
+	spool    *os.File
+	spooled  int64
+	clusters []int64
+	blobs    [][]byte
+	size     int
+
+	mimes    []string
+	mimeIdx  map[string]uint16
+	entries  []*entry
+	byKey    map[string]*entry
+	mainPage string
+}
+
+// NewWriter returns a Writer that spools content to a temporary file in dir.
+func NewWriter(dir string) (*Writer, error) {
+	spool, err := os.CreateTemp(dir, "zim-clusters-*")
+	if err != nil {
+		return nil, err
+	}
+	return &Writer{spool: spool, mimeIdx: make(map[string]uint16), byKey: make(map[string]*entry)}, nil
+}
+
+// Close removes the spool file.
+func (w *Writer) Close() error {
+	err := w.spool.Close()
+	if rmErr := os.Remove(w.spool.Name()); err == nil {
+		err = rmErr
+	}
+	return err
+}
+
+// AddItem stores content at namespace/path. Front items are the articles
+// readers list in search suggestions.
+func (w *Writer) AddItem(namespace byte, path, title, mime string, content []byte, front bool) error {
+	e := &entry{namespace: namespace, path: path, title: title, front: front}
+	if err := w.addEntry(e); err != nil {
+		return err
+	}
+	var err error
+	if e.mime, err = w.mimeIndex(mime); err != nil {
+		return err
+	}
+	return w.addBlob(e, content)
+}
+
+// AddMetadata stores a text metadata value, such as Title or Language.
+func (w *Writer) AddMetadata(name, value string) error {
+	return w.AddItem('M', name, "", "text/plain", []byte(value), false)
+}
+
+// AddRedirect makes namespace/path resolve to targetNamespace/target. The
+// target must be added before Finish.
+func (w *Writer) AddRedirect(namespace byte, path, title string, targetNamespace byte, target string) error {
+	return w.addEntry(&entry{namespace: namespace, path: path, title: title, redirect: true, targetKey: string(targetNamespace) + target})
+}
+
+// SetMainPage sets the content path readers open first.
+func (w *Writer) SetMainPage(path string) {
+	w.mainPage = path
+}
+
+func (w *Writer) addEntry(e *entry) error {
+	if e.path == "" {
+		return fmt.Errorf("zim: empty path in namespace %c", e.namespace)
+	}
+	if _, ok := w.byKey[e.key()]; ok {
+		return fmt.Errorf("zim: duplicate entry %c/%s", e.namespace, e.path)
+	}
+	w.byKey[e.key()] = e
+	w.entries = append(w.entries, e)
+	return nil
+}
+
+func (w *Writer) mimeIndex(mime string) (uint16, error) {
+	if idx, ok := w.mimeIdx[mime]; ok {
+		return idx, nil
+	}
+	// 0xfffd and above are reserved for redirects and deleted entries.
+	if len(w.mimes) >= 0xfffd {
+		return 0, fmt.Errorf("zim: too many MIME types")
+	}
+	idx := uint16(len(w.mimes))
+	w.mimes = append(w.mimes, mime)
+	w.mimeIdx[mime] = idx
+	return idx, nil
+}
+
+func (w *Writer) addBlob(e *entry, content []byte) error {
+	if w.size > 0 && w.size+len(content) > clusterSize {
+		if err := w.flushCluster(); err != nil {
+			return err
+		}
+	}
+	e.cluster, e.blob = uint32(len(w.clusters)), uint32(len(w.blobs))
+	w.blobs = append(w.blobs, content)
+	w.size += len(content)
+	return nil
+}
+
+// flushCluster appends the pending blobs to the spool as one uncompressed
+// cluster: the compression byte, then n+1 offsets relative to the end of that
+// byte, then the blobs.
+func (w *Writer) flushCluster() error {
+	if len(w.blobs) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	buf.WriteByte(compressionNone)
+	offset := uint32(4 * (len(w.blobs) + 1))
+	for _, blob := range w.blobs {
+		binary.Write(&buf, binary.LittleEndian, offset)
+		offset += uint32(len(blob))
+	}
+	binary.Write(&buf, binary.LittleEndian, offset)
+	for _, blob := range w.blobs {
+		buf.Write(blob)
+	}
+
+	n, err := w.spool.Write(buf.Bytes())
+	if err != nil {
+		return err
+	}
+	w.clusters = append(w.clusters, w.spooled)
+	w.spooled += int64(n)
+	w.blobs, w.size = nil, 0
+	return nil
+}
+
+// Finish writes the archive to out. The Writer cannot be used afterwards.
+func (w *Writer) Finish(out io.Writer) error {
+	if w.mainPage != "" {
+		if err := w.AddRedirect('W', "mainPage", "", 'C', w.mainPage); err != nil {
+			return err
+		}
+	}
+	listing := &entry{namespace: 'X', path: frontListing}
+	if err := w.addEntry(listing); err != nil {
+		return err
+	}
+	var err error
+	if listing.mime, err = w.mimeIndex(listingMime); err != nil {
+		return err
+	}
+	if err := w.flushCluster(); err != nil {
+		return err
+	}
+
+	sort.Slice(w.entries, func(i, j int) bool { return w.entries[i].key() < w.entries[j].key() })
+	for i, e := range w.entries {
+		e.index = uint32(i)
+	}
+	for _, e := range w.entries {
+		if !e.redirect {
+			continue
+		}
+		if e.target = w.byKey[e.targetKey]; e.target == nil {
+			return fmt.Errorf("zim: %c/%s redirects to missing %s", e.namespace, e.path, e.targetKey)
+		}
+	}
+
+	byTitle := make([]*entry, len(w.entries))
+	copy(byTitle, w.entries)
+	sort.SliceStable(byTitle, func(i, j int) bool {
+		a, b := byTitle[i], byTitle[j]
+		if a.namespace != b.namespace {
+			return a.namespace < b.namespace
+		}
+		return a.sortTitle() < b.sortTitle()
+	})
+
+	var front []byte
+	for _, e := range byTitle {
+		if e.front {
+			front = binary.LittleEndian.AppendUint32(front, e.index)
+		}
+	}
+	if err := w.addBlob(listing, front); err != nil {
+		return err
+	}
+	if err := w.flushCluster(); err != nil {
+		return err
+	}
+	return w.writeArchive(out, byTitle)
+}
+
+func (w *Writer) writeArchive(out io.Writer, byTitle []*entry) error {
+	var mimeList []byte
+	for _, mime := range w.mimes {
+		mimeList = append(append(mimeList, mime...), 0)
+	}
+	mimeList = append(mimeList, 0)
+
+	urlPtrPos := uint64(headerSize + len(mimeList))
+	titlePtrPos := urlPtrPos + 8*uint64(len(w.entries))
+	clusterPtrPos := titlePtrPos + 4*uint64(len(w.entries))
+	direntPos := clusterPtrPos + 8*uint64(len(w.clusters))
+
+	dirents := make([][]byte, len(w.entries))
+	clusterPos := direntPos
+	for i, e := range w.entries {
+		dirents[i] = e.dirent()
+		clusterPos += uint64(len(dirents[i]))
+	}
+	checksumPos := clusterPos + uint64(w.spooled)
+
+	var uuid [16]byte
+	if _, err := rand.Read(uuid[:]); err != nil {
+		return err
+	}
+	uuid[6] = uuid[6]&0x0f | 0x40
+	uuid[8] = uuid[8]&0x3f | 0x80
+
+	mainPage := uint32(noPage)
+	if main := w.byKey["WmainPage"]; main != nil {
+		mainPage = main.index
+	}
+
+	sum := md5.New()
+	bw := bufio.NewWriter(io.MultiWriter(out, sum))
+	le := binary.LittleEndian
+	var header []byte
+	header = le.AppendUint32(header, magicNumber)
+	header = le.AppendUint16(header, majorVersion)
+	header = le.AppendUint16(header, minorVersion)
+	header = append(header, uuid[:]...)
+	header = le.AppendUint32(header, uint32(len(w.entries)))
+	header = le.AppendUint32(header, uint32(len(w.clusters)))
+	header = le.AppendUint64(header, urlPtrPos)
+	header = le.AppendUint64(header, titlePtrPos)
+	header = le.AppendUint64(header, clusterPtrPos)
+	header = le.AppendUint64(header, headerSize)
+	header = le.AppendUint32(header, mainPage)
+	header = le.AppendUint32(header, noPage)
+	header = le.AppendUint64(header, checksumPos)
+	bw.Write(header)
+	bw.Write(mimeList)
+
+	pos := direntPos
+	for _, dirent := range dirents {
+		bw.Write(le.AppendUint64(nil, pos))
+		pos += uint64(len(dirent))
+	}
+	for _, e := range byTitle {
+		bw.Write(le.AppendUint32(nil, e.index))
+	}
+	for _, offset := range w.clusters {
+		bw.Write(le.AppendUint64(nil, clusterPos+uint64(offset)))
+	}
+	for _, dirent := range dirents {
+		bw.Write(dirent)
+	}
+
+	if _, err := w.spool.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := io.Copy(bw, w.spool); err != nil {
+		return err
+	}
+	if err := bw.Flush(); err != nil {
+		return err
+	}
+	_, err := out.Write(sum.Sum(nil))
+	return err
+}
+
+// dirent encodes the directory entry of e.
+func (e *entry) dirent() []byte {
+	le := binary.LittleEndian
+	var b []byte
+	if e.redirect {
+		b = le.AppendUint16(b, redirectMime)
+	} else {
+		b = le.AppendUint16(b, e.mime)
+	}
+	b = append(b, 0, e.namespace) // no extra parameters
+	b = le.AppendUint32(b, 0)     // revision
+	if e.redirect {
+		b = le.AppendUint32(b, e.target.index)
+	} else {
+		b = le.AppendUint32(b, e.cluster)
+		b = le.AppendUint32(b, e.blob)
+	}
+	b = append(append(b, e.path...), 0)
+	if e.title != e.path {
+		b = append(b, e.title...)
+	}
+	return append(b, 0)
+}
@@ -0,0 +1,207 @@
+package zim
+
+import (
+	"bytes"
+	"crypto/md5"
+	"encoding/binary"
+	"strings"
+	"testing"
+)
+
+// archive is a minimal ZIM reader covering what Writer produces.
+type archive struct {
+	t        *testing.T
+	data     []byte
+	mimes    []string
+	count    uint32
+	mainPage uint32
+}
+
+type readEntry struct {
+	mime      uint16
+	namespace byte
+	path      string
+	title     string
+	cluster   uint32
+	blob      uint32
+	target    uint32
+}
+
+func readArchive(t *testing.T, data []byte) *archive {
+	t.Helper()
+	le := binary.LittleEndian
+	if le.Uint32(data) != magicNumber || le.Uint16(data[4:]) != majorVersion || le.Uint16(data[6:]) != minorVersion {
+		t.Fatalf("bad header % x", data[:8])
+	}
+	checksumPos := le.Uint64(data[72:])
+	if uint64(len(data)) != checksumPos+16 {
+		t.Fatalf("checksum at %d in %d bytes", checksumPos, len(data))
+	}
+	if sum := md5.Sum(data[:checksumPos]); !bytes.Equal(sum[:], data[checksumPos:]) {
+		t.Fatal("checksum mismatch")
+	}
+	a := &archive{t: t, data: data, count: le.Uint32(data[24:]), mainPage: le.Uint32(data[64:])}
+	if le.Uint64(data[56:]) != headerSize {
+		t.Fatalf("mime list at %d", le.Uint64(data[56:]))
+	}
+	for pos := headerSize; data[pos] != 0; {
+		end := pos + bytes.IndexByte(data[pos:], 0)
+		a.mimes = append(a.mimes, string(data[pos:end]))
+		pos = end + 1
+	}
+	return a
+}
+
+func (a *archive) entry(index uint32) readEntry {
+	le := binary.LittleEndian
+	pos := le.Uint64(a.data[le.Uint64(a.data[32:])+8*uint64(index):])
+	d := a.data[pos:]
+	e := readEntry{mime: le.Uint16(d), namespace: d[3]}
+	rest := d[12:]
+	if e.mime == redirectMime {
+		e.target = le.Uint32(d[8:])
+	} else {
+		e.cluster, e.blob = le.Uint32(d[8:]), le.Uint32(d[12:])
+		rest = d[16:]
+	}
+	path, rest, _ := bytes.Cut(rest, []byte{0})
+	title, _, _ := bytes.Cut(rest, []byte{0})
+	e.path, e.title = string(path), string(title)
+	return e
+}
+
+func (a *archive) titleOrder() []uint32 {
+	le := binary.LittleEndian
+	pos := le.Uint64(a.data[40:])
+	order := make([]uint32, a.count)
+	for i := range order {
+		order[i] = le.Uint32(a.data[pos+4*uint64(i):])
+	}
+	return order
+}
+
+func (a *archive) find(namespace byte, path string) (uint32, readEntry) {
+	for i := uint32(0); i < a.count; i++ {
+		if e := a.entry(i); e.namespace == namespace && e.path == path {
+			return i, e
+		}
+	}
+	a.t.Fatalf("no entry %c/%s", namespace, path)
+	return 0, readEntry{}
+}
+
+func (a *archive) content(e readEntry) []byte {
+	le := binary.LittleEndian
+	pos := le.Uint64(a.data[le.Uint64(a.data[48:])+8*uint64(e.cluster):])
+	if a.data[pos] != compressionNone {
+		a.t.Fatalf("cluster %d compression %d", e.cluster, a.data[pos])
+	}
+	offsets := a.data[pos+1:]
+	start, end := le.Uint32(offsets[4*e.blob:]), le.Uint32(offsets[4*e.blob+4:])
+	return offsets[start:end]
+}
+
+func TestWriterRoundTrip(t *testing.T) {
+	w, err := NewWriter(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	big := bytes.Repeat([]byte("x"), clusterSize)
+	items := []struct {
+		path, title string
+		content     []byte
+	}{
+		{"zeno", "Zeno of Elea", []byte("<h1>Zeno</h1>")},
+		{"rome", "Rome", []byte("<h1>Rome</h1>")},
+		{"atlas", "Atlas", big},
+		{"main_page", "Main Page", []byte("<h1>Welcome</h1>")},
+	}
+	for _, item := range items {
+		if err := w.AddItem('C', item.path, item.title, "text/html", item.content, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.AddRedirect('C', "roma", "Roma", 'C', "rome"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.AddMetadata("Title", "EndlessWiki"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.AddItem('C', "rome", "Rome", "text/html", nil, true); err == nil {
+		t.Error("duplicate path accepted")
+	}
+	w.SetMainPage("main_page")
+
+	var out bytes.Buffer
+	if err := w.Finish(&out); err != nil {
+		t.Fatal(err)
+	}
+	a := readArchive(t, out.Bytes())
+
+	var keys []string
+	for i := uint32(0); i < a.count; i++ {
+		e := a.entry(i)
+		keys = append(keys, string(e.namespace)+"/"+e.path)
+	}
+	want := "C/atlas C/main_page C/roma C/rome C/zeno M/Title W/mainPage X/" + frontListing
+	if got := strings.Join(keys, " "); got != want {
+		t.Fatalf("entries = %s, want %s", got, want)
+	}
+
+	for _, item := range items {
+		_, e := a.find('C', item.path)
+		if a.mimes[e.mime] != "text/html" || e.title != item.title {
+			t.Errorf("%s: mime %q title %q", item.path, a.mimes[e.mime], e.title)
+		}
+		if got := a.content(e); !bytes.Equal(got, item.content) {
+			t.Errorf("%s: content %.20q", item.path, got)
+		}
+	}
+	if _, e := a.find('C', "atlas"); e.cluster == 0 {
+		t.Error("a cluster-sized item shares the first cluster")
+	}
+
+	rome, _ := a.find('C', "rome")
+	if _, e := a.find('C', "roma"); e.mime != redirectMime || e.target != rome {
+		t.Errorf("roma = %+v", e)
+	}
+	mainPage, mainRedirect := a.find('W', "mainPage")
+	if a.mainPage != mainPage || a.entry(mainRedirect.target).path != "main_page" {
+		t.Errorf("main page %d -> %+v", a.mainPage, mainRedirect)
+	}
+
+	var titles []string
+	for _, idx := range a.titleOrder() {
+		if e := a.entry(idx); e.namespace == 'C' {
+			titles = append(titles, e.title)
+		}
+	}
+	if got := strings.Join(titles, ", "); got != "Atlas, Main Page, Roma, Rome, Zeno of Elea" {
+		t.Errorf("title order = %s", got)
+	}
+
+	_, listing := a.find('X', frontListing)
+	var front []string
+	for data := a.content(listing); len(data) > 0; data = data[4:] {
+		front = append(front, a.entry(binary.LittleEndian.Uint32(data)).path)
+	}
+	if got := strings.Join(front, " "); got != "atlas main_page rome zeno" {
+		t.Errorf("front listing = %s", got)
+	}
+}
+
+func TestWriterRejectsDanglingRedirect(t *testing.T) {
+	w, err := NewWriter(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if err := w.AddRedirect('C', "roma", "", 'C', "rome"); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Finish(&bytes.Buffer{}); err == nil {
+		t.Error("Finish accepted a redirect to a missing entry")
+	}
+}